@@ -0,0 +1,82 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/urfave/cli/v2"
+
+	"github.com/vuquang23/trustme/internal/pkg/config"
+	"github.com/vuquang23/trustme/internal/pkg/entity"
+	"github.com/vuquang23/trustme/internal/pkg/parser"
+	"github.com/vuquang23/trustme/internal/pkg/repository"
+)
+
+func backfillCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "backfill",
+		Usage: "Save the transactions of a range of blocks, keeping the saved ones and publishing no event",
+		Flags: []cli.Flag{
+			&cli.Uint64Flag{
+				Name:     "from",
+				Required: true,
+				Usage:    "First block",
+			},
+			&cli.Uint64Flag{
+				Name:     "to",
+				Required: true,
+				Usage:    "Last block",
+			},
+			&cli.StringSliceFlag{
+				Name:  "address",
+				Usage: "Save the transactions of these addresses instead of the subscribed ones, repeatable",
+			},
+		},
+		Action: backfill,
+	}
+}
+
+func reprocessCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "reprocess",
+		Usage: "Process a block again, replacing its entries, publishing no event",
+		Flags: []cli.Flag{
+			&cli.Uint64Flag{
+				Name:     "block",
+				Required: true,
+				Usage:    "Block number",
+			},
+		},
+		Action: reprocess,
+	}
+}
+
+func backfill(c *cli.Context) error {
+	return withParser(c, true, func(conf config.Config, _ *repository.Repositories, p *parser.Parser) error {
+		addresses := make([]string, 0, len(c.StringSlice("address")))
+		for _, s := range c.StringSlice("address") {
+			address, err := entity.ParseAddressKey(s, conf.API.EnforceChecksum)
+			if err != nil {
+				return fmt.Errorf("%s: %w", s, err)
+			}
+			addresses = append(addresses, address)
+		}
+
+		warnNoEvents(c)
+		saved, err := p.Backfill(c.Context, c.Uint64("from"), c.Uint64("to"), addresses)
+		fmt.Fprintf(c.App.Writer, "saved: %d\n", saved)
+		return err
+	})
+}
+
+func reprocess(c *cli.Context) error {
+	return withParser(c, true, func(_ config.Config, _ *repository.Repositories, p *parser.Parser) error {
+		warnNoEvents(c)
+		return p.ReprocessBlock(c.Context, c.Uint64("block"))
+	})
+}
+
+// warnNoEvents tells that the transactions saved by a command reach no consumer.
+func warnNoEvents(c *cli.Context) {
+	fmt.Fprintln(c.App.ErrWriter, "warning: no event is published, webhooks, sinks and streams won't see these transactions; "+
+		"reprocess the blocks with POST /admin/parser/reprocess and \"publish\": true to deliver them")
+}
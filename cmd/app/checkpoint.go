@@ -0,0 +1,55 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/urfave/cli/v2"
+
+	"github.com/vuquang23/trustme/internal/pkg/config"
+	"github.com/vuquang23/trustme/internal/pkg/parser"
+	"github.com/vuquang23/trustme/internal/pkg/repository"
+)
+
+func checkpointCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "checkpoint",
+		Usage: "Read or move the last processed block",
+		Subcommands: []*cli.Command{
+			{
+				Name:   "show",
+				Usage:  "Print the checkpoint",
+				Action: showCheckpoint,
+			},
+			{
+				Name:  "set",
+				Usage: "Move the checkpoint",
+				Flags: []cli.Flag{
+					&cli.Uint64Flag{
+						Name:     "block",
+						Required: true,
+						Usage:    "Block number",
+					},
+				},
+				Action: setCheckpoint,
+			},
+		},
+	}
+}
+
+func showCheckpoint(c *cli.Context) error {
+	return withParser(c, false, func(_ config.Config, repos *repository.Repositories, _ *parser.Parser) error {
+		checkpoint, err := repos.Checkpoint.GetCheckpoint()
+		if err != nil {
+			return err
+		}
+
+		fmt.Fprintln(c.App.Writer, checkpoint)
+		return nil
+	})
+}
+
+func setCheckpoint(c *cli.Context) error {
+	return withParser(c, false, func(_ config.Config, repos *repository.Repositories, _ *parser.Parser) error {
+		return repos.Checkpoint.SaveCheckpoint(c.Uint64("block"))
+	})
+}
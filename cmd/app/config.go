@@ -0,0 +1,41 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/urfave/cli/v2"
+)
+
+func configCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "config",
+		Usage: "Check the configuration",
+		Subcommands: []*cli.Command{
+			{
+				Name:   "validate",
+				Usage:  "Report unknown keys and the values the server would refuse",
+				Action: validateConfig,
+			},
+		},
+	}
+}
+
+func validateConfig(c *cli.Context) error {
+	// loading falls back to the defaults without a file, which would always be valid
+	if _, err := os.Stat(c.String("config")); err != nil {
+		return err
+	}
+
+	conf, err := loadConfig(c)
+	if err != nil {
+		return err
+	}
+
+	if err := conf.Validate(); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(c.App.Writer, "%s is valid\n", c.String("config"))
+	return nil
+}
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"io"
+	"os"
+
+	"github.com/urfave/cli/v2"
+
+	"github.com/vuquang23/trustme/internal/pkg/api"
+	"github.com/vuquang23/trustme/internal/pkg/config"
+	"github.com/vuquang23/trustme/internal/pkg/entity"
+	"github.com/vuquang23/trustme/internal/pkg/exporter"
+	"github.com/vuquang23/trustme/internal/pkg/parser"
+	"github.com/vuquang23/trustme/internal/pkg/repository"
+)
+
+const exportPageSize = 1000
+
+func exportCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "export",
+		Usage: "Write the transactions of an address as CSV, NDJSON or JSON, oldest first",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:     "address",
+				Required: true,
+				Usage:    "Address whose transactions are exported",
+			},
+			&cli.StringFlag{
+				Name:  "format",
+				Usage: "csv, ndjson or json, guessed from the output extension when empty",
+			},
+			&cli.StringFlag{
+				Name:    "output",
+				Aliases: []string{"o"},
+				Usage:   "File to write, stdout when empty",
+			},
+		},
+		Action: export,
+	}
+}
+
+func export(c *cli.Context) error {
+	return withParser(c, false, func(conf config.Config, _ *repository.Repositories, p *parser.Parser) error {
+		address, err := entity.ParseAddress(c.String("address"), conf.API.EnforceChecksum)
+		if err != nil {
+			return err
+		}
+
+		format := exporter.Format(c.String("format"))
+		if format == "" {
+			format = exporter.FormatFromFilename(c.String("output"))
+		}
+
+		var w io.Writer = c.App.Writer
+		if output := c.String("output"); output != "" {
+			f, err := os.Create(output)
+			if err != nil {
+				return err
+			}
+			defer f.Close()
+			w = f
+		}
+
+		encoder, err := exporter.NewEncoder(w, format)
+		if err != nil {
+			return err
+		}
+
+		query := entity.TxQuery{Order: entity.SortOrderAsc, Limit: exportPageSize}
+		for {
+			page, err := p.QueryTxRecords(entity.AddressKey(address), query)
+			if err != nil {
+				return err
+			}
+
+			for _, record := range page.Records {
+				if err := encoder.Encode(api.NewTxRecordResponse(record)); err != nil {
+					return err
+				}
+			}
+
+			if page.NextCursor == "" {
+				return encoder.Close()
+			}
+			query.Cursor = page.NextCursor
+		}
+	})
+}
@@ -0,0 +1,118 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/urfave/cli/v2"
+
+	"github.com/vuquang23/trustme/internal/pkg/auth"
+	"github.com/vuquang23/trustme/internal/pkg/config"
+	"github.com/vuquang23/trustme/internal/pkg/repository"
+)
+
+func keysCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "keys",
+		Usage: "Manage API keys",
+		Subcommands: []*cli.Command{
+			{
+				Name:  "create",
+				Usage: "Generate an API key, printed once",
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:  "name",
+						Usage: "Name of the key",
+					},
+					&cli.StringFlag{
+						Name:  "tenant",
+						Usage: "Tenant whose subscriptions the key sees, required unless --admin",
+					},
+					&cli.BoolFlag{
+						Name:  "admin",
+						Usage: "Let the key see every subscription and use the admin routes",
+					},
+				},
+				Action: createKey,
+			},
+			{
+				Name:   "list",
+				Usage:  "List the API keys",
+				Action: listKeys,
+			},
+			{
+				Name:  "revoke",
+				Usage: "Delete an API key",
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:     "id",
+						Required: true,
+						Usage:    "ID of the key",
+					},
+				},
+				Action: revokeKey,
+			},
+		},
+	}
+}
+
+// withAuthenticator runs fn with an authenticator over the configured key repository.
+func withAuthenticator(c *cli.Context, fn func(conf config.Config, authenticator *auth.Authenticator) error) error {
+	conf, err := loadConfig(c)
+	if err != nil {
+		return err
+	}
+
+	if conf.Repository.Backend == repository.BackendMemory {
+		return fmt.Errorf("keys of the %s backend don't outlive the command, configure a persistent backend or Auth.Keys", conf.Repository.Backend)
+	}
+
+	repos, err := repository.New(conf.Repository)
+	if err != nil {
+		return err
+	}
+	defer repos.Close()
+
+	authenticator, err := auth.New(conf.Auth, repos.APIKey)
+	if err != nil {
+		return err
+	}
+
+	return fn(conf, authenticator)
+}
+
+func createKey(c *cli.Context) error {
+	return withAuthenticator(c, func(_ config.Config, authenticator *auth.Authenticator) error {
+		key, apiKey, err := authenticator.CreateKey(c.String("name"), c.String("tenant"), c.Bool("admin"))
+		if err != nil {
+			return err
+		}
+
+		fmt.Fprintf(c.App.Writer, "id: %s\nkey: %s\n", apiKey.ID, key)
+		return nil
+	})
+}
+
+func listKeys(c *cli.Context) error {
+	return withAuthenticator(c, func(conf config.Config, authenticator *auth.Authenticator) error {
+		keys, err := authenticator.ListKeys()
+		if err != nil {
+			return err
+		}
+
+		for _, key := range keys {
+			fmt.Fprintf(c.App.Writer, "%s\t%s\ttenant=%s\tadmin=%t\t%s\n",
+				key.ID, key.Name, key.Tenant, key.Admin, key.CreatedAt.Format(time.RFC3339))
+		}
+		for _, key := range conf.Auth.Keys {
+			fmt.Fprintf(c.App.Writer, "config\t%s\ttenant=%s\tadmin=%t\n", key.Name, key.Tenant, key.Admin)
+		}
+		return nil
+	})
+}
+
+func revokeKey(c *cli.Context) error {
+	return withAuthenticator(c, func(_ config.Config, authenticator *auth.Authenticator) error {
+		return authenticator.RevokeKey(c.String("id"))
+	})
+}
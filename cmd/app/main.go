@@ -2,21 +2,37 @@ package main
 
 import (
 	"context"
+	"fmt"
 	"log"
+	"net/http"
 	"os"
 	"os/signal"
 
 	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/rpc"
 	"github.com/urfave/cli/v2"
 	"golang.org/x/sync/errgroup"
 	"golang.org/x/sys/unix"
 
+	"github.com/vuquang23/trustme/internal/pkg/admin"
 	"github.com/vuquang23/trustme/internal/pkg/api"
+	"github.com/vuquang23/trustme/internal/pkg/auth"
 	"github.com/vuquang23/trustme/internal/pkg/config"
+	"github.com/vuquang23/trustme/internal/pkg/entity"
+	"github.com/vuquang23/trustme/internal/pkg/eventbus"
+	"github.com/vuquang23/trustme/internal/pkg/graph"
+	"github.com/vuquang23/trustme/internal/pkg/grpcserver"
+	"github.com/vuquang23/trustme/internal/pkg/health"
+	"github.com/vuquang23/trustme/internal/pkg/jsonrpc"
+	"github.com/vuquang23/trustme/internal/pkg/metrics"
 	"github.com/vuquang23/trustme/internal/pkg/parser"
-	"github.com/vuquang23/trustme/internal/pkg/repository/subscriber"
-	"github.com/vuquang23/trustme/internal/pkg/repository/tx"
+	"github.com/vuquang23/trustme/internal/pkg/ratelimit"
+	"github.com/vuquang23/trustme/internal/pkg/repository"
 	"github.com/vuquang23/trustme/internal/pkg/server"
+	"github.com/vuquang23/trustme/internal/pkg/sink"
+	"github.com/vuquang23/trustme/internal/pkg/tenant"
+	"github.com/vuquang23/trustme/internal/pkg/tracing"
+	"github.com/vuquang23/trustme/internal/pkg/webhook"
 	"github.com/vuquang23/trustme/pkg/logger"
 )
 
@@ -35,13 +51,13 @@ func main() {
 			{
 				Name: "trustme",
 				Action: func(c *cli.Context) error {
-					conf := config.New()
-					if err := conf.Load(c.String("config")); err != nil {
+					conf, err := loadConfig(c)
+					if err != nil {
 						return err
 					}
 
 					// logger
-					_, err := logger.Init(conf.Log, logger.LoggerBackendZap)
+					_, err = logger.Init(conf.Log, logger.LoggerBackendZap)
 					if err != nil {
 						return err
 					}
@@ -54,31 +70,101 @@ func main() {
 						cancel()
 					}()
 
+					// tracing
+					shutdownTracing, err := tracing.Init(ctx, conf.Tracing)
+					if err != nil {
+						return err
+					}
+					defer func() {
+						if err := shutdownTracing(context.Background()); err != nil {
+							logger.Error(ctx, err.Error())
+						}
+					}()
+
 					// eth clients
-					rpcClient, err := ethclient.Dial("https://ethereum-rpc.publicnode.com")
+					rpcClient, err := dialRPC(ctx)
 					if err != nil {
 						return err
 					}
 
-					wsClient, err := ethclient.Dial("wss://ethereum-rpc.publicnode.com")
+					wsClient, err := ethclient.Dial(wsURL)
 					if err != nil {
 						return err
 					}
 
 					// repositories
-					subscriberRepo := subscriber.NewMemRepository()
-					txRepo := tx.NewMemRepository()
+					repos, err := repository.New(conf.Repository)
+					if err != nil {
+						return err
+					}
+					defer repos.Close()
 
-					// parser
-					parser := parser.New(rpcClient, wsClient, subscriberRepo, txRepo)
+					// event bus
+					bus := eventbus.New()
 
-					// http server
-					engine := server.GinEngine(conf.Http, conf.Log, logger.LoggerBackendZap)
-					api.SetupRoute(engine, parser)
+					// webhooks
+					webhooks := webhook.New(conf.Webhook, repos.Webhook, repos.Subscriber, api.EventData)
 
 					// run goroutines
 					var errGroup errgroup.Group
+
+					// parser, deliveries and broker messages are enqueued before the checkpoint moves on
+					publisher := eventbus.Publishers{webhooks}
+					if conf.Sink.Driver != "" {
+						relay, err := sink.New(conf.Sink, repos.Outbox, api.EventData)
+						if err != nil {
+							return err
+						}
+						defer relay.Close()
+
+						publisher = append(publisher, relay)
+						errGroup.Go(func() error { return relay.Run(ctx) })
+					}
+					publisher = append(publisher, bus)
+					parser := parser.New(conf.Parser, rpcClient, wsClient, repos.Subscriber, repos.Tx, repos.Checkpoint, publisher)
+
+					// rate limits and subscription quotas, adjustable at runtime through the admin routes
+					limiter, err := ratelimit.New(conf.RateLimit)
+					if err != nil {
+						return err
+					}
+					errGroup.Go(func() error { return limiter.Run(ctx) })
+
+					// authentication, every API sees the parser through the tenant of the request
+					authenticator, err := auth.New(conf.Auth, repos.APIKey)
+					if err != nil {
+						return err
+					}
+					tenants := tenant.New(parser, repos.Tenant, limiter)
+
+					// http server
+					engine, err := server.GinEngine(conf.Http, conf.Log, logger.LoggerBackendZap)
+					if err != nil {
+						return err
+					}
+					engine.Use(api.Authenticate(authenticator), api.RateLimit(limiter))
+					checker := health.New(conf.Health, parser, repos)
+					if err := api.SetupRoute(engine, conf.API, tenants, bus, webhooks, checker); err != nil {
+						return err
+					}
+					// without authentication anyone would be an admin, the admin routes are not served at all
+					if conf.Auth.Enabled {
+						admin.SetupRoute(engine, authenticator, limiter, parser)
+					}
+					metrics.SetupRoute(engine, conf.Metrics)
+					graph.SetupRoute(engine, conf.GraphQL, conf.API.EnforceChecksum, tenants)
+					jsonrpc.SetupRoute(engine, conf.JSONRPC, conf.API.EnforceChecksum, tenants, bus)
+
+					// grpc server
+					if conf.GRPC.BindAddress != "" {
+						grpcServer := grpcserver.New(grpcserver.NewServer(conf.GRPC, conf.API.EnforceChecksum, tenants, bus), authenticator, limiter)
+						errGroup.Go(func() error {
+							return grpcserver.Run(ctx, conf.GRPC.BindAddress, grpcServer)
+						})
+					}
+
 					errGroup.Go(func() error { return parser.Run(ctx) })
+					errGroup.Go(func() error { return webhooks.Run(ctx) })
 					errGroup.Go(func() error {
 						return server.Run(ctx, conf.Http.BindAddress, engine)
 					})
@@ -93,6 +179,14 @@ func main() {
 					return nil
 				},
 			},
+			backfillCommand(),
+			reprocessCommand(),
+			subscriptionsCommand(),
+			exportCommand(),
+			checkpointCommand(),
+			migrateCommand(),
+			configCommand(),
+			keysCommand(),
 		},
 		DefaultCommand: "trustme",
 	}
@@ -102,3 +196,68 @@ func main() {
 		log.Fatal(err)
 	}
 }
+
+const (
+	rpcURL = "https://ethereum-rpc.publicnode.com"
+	wsURL  = "wss://ethereum-rpc.publicnode.com"
+)
+
+// dialRPC connects the eth client of the RPC calls, which are traced and counted in the RPC metrics.
+func dialRPC(ctx context.Context) (*ethclient.Client, error) {
+	rpcConn, err := rpc.DialOptions(ctx, rpcURL,
+		rpc.WithHTTPClient(&http.Client{Transport: tracing.RPCTransport(metrics.RPCTransport(nil))}))
+	if err != nil {
+		return nil, err
+	}
+	return ethclient.NewClient(rpcConn), nil
+}
+
+// withParser runs fn with a parser over the configured repositories, for the commands working on the stored data
+// while the server is stopped. They refuse the memory backend, whose data doesn't outlive the command. The RPC is
+// only dialed with dial. The commands publish no event: webhooks, sinks and streams never see their work.
+func withParser(
+	c *cli.Context,
+	dial bool,
+	fn func(conf config.Config, repos *repository.Repositories, p *parser.Parser) error,
+) error {
+	conf, err := loadConfig(c)
+	if err != nil {
+		return err
+	}
+
+	if conf.Repository.Backend == repository.BackendMemory {
+		return fmt.Errorf("the %s backend doesn't outlive the command, configure a persistent backend", conf.Repository.Backend)
+	}
+
+	var rpcClient *ethclient.Client
+	if dial {
+		if rpcClient, err = dialRPC(c.Context); err != nil {
+			return err
+		}
+		defer rpcClient.Close()
+	}
+
+	repos, err := repository.New(conf.Repository)
+	if err != nil {
+		return err
+	}
+	defer repos.Close()
+
+	p := parser.New(conf.Parser, rpcClient, nil, repos.Subscriber, repos.Tx, repos.Checkpoint, discardEvents{})
+	return fn(conf, repos, p)
+}
+
+// discardEvents is the publisher of the commands, none of them publishes events.
+type discardEvents struct{}
+
+func (discardEvents) Publish(entity.Event) error {
+	return nil
+}
+
+func loadConfig(c *cli.Context) (config.Config, error) {
+	conf := config.New()
+	if err := conf.Load(c.String("config")); err != nil {
+		return conf, err
+	}
+	return conf, nil
+}
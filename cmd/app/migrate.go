@@ -0,0 +1,115 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/urfave/cli/v2"
+	bolt "go.etcd.io/bbolt"
+
+	"github.com/vuquang23/trustme/internal/pkg/repository"
+	"github.com/vuquang23/trustme/internal/pkg/repository/boltdb"
+)
+
+func migrateCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "migrate",
+		Usage: "Migrate the schema of the bolt database",
+		Subcommands: []*cli.Command{
+			{
+				Name:  "up",
+				Usage: "Apply the pending migrations",
+				Flags: []cli.Flag{
+					&cli.IntFlag{
+						Name:  "to",
+						Usage: "Version to stop at, the latest when unset",
+					},
+				},
+				Action: migrateUp,
+			},
+			{
+				Name:  "down",
+				Usage: "Revert migrations, dropping the buckets they created along with their data",
+				Flags: []cli.Flag{
+					&cli.IntFlag{
+						Name:  "to",
+						Usage: "Version to go back to, the one before the current when unset",
+					},
+				},
+				Action: migrateDown,
+			},
+		},
+	}
+}
+
+// withBolt runs fn with the configured bolt database, opened without checking its schema.
+func withBolt(c *cli.Context, fn func(db *bolt.DB) error) error {
+	conf, err := loadConfig(c)
+	if err != nil {
+		return err
+	}
+
+	if conf.Repository.Backend != repository.BackendBolt {
+		return fmt.Errorf("the %s backend has no schema to migrate", conf.Repository.Backend)
+	}
+
+	db, err := boltdb.OpenFile(conf.Repository.Bolt.Path, &bolt.Options{Timeout: conf.Repository.Bolt.Timeout})
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	return fn(db)
+}
+
+func migrateUp(c *cli.Context) error {
+	return withBolt(c, func(db *bolt.DB) error {
+		to := boltdb.LatestVersion()
+		if c.IsSet("to") {
+			to = c.Int("to")
+		}
+
+		applied, err := boltdb.MigrateUp(db, to)
+		printMigrations(c, "up", applied)
+		if err != nil {
+			return err
+		}
+		return printSchemaVersion(c, db)
+	})
+}
+
+func migrateDown(c *cli.Context) error {
+	return withBolt(c, func(db *bolt.DB) error {
+		current, err := boltdb.SchemaVersion(db)
+		if err != nil {
+			return err
+		}
+
+		to := current - 1
+		if c.IsSet("to") {
+			to = c.Int("to")
+		}
+
+		reverted, err := boltdb.MigrateDown(db, to)
+		printMigrations(c, "down", reverted)
+		if err != nil {
+			return err
+		}
+		return printSchemaVersion(c, db)
+	})
+}
+
+func printMigrations(c *cli.Context, direction string, migrations []boltdb.Migration) {
+	for _, m := range migrations {
+		fmt.Fprintf(c.App.Writer, "%s\t%d\t%s\n", direction, m.Version, m.Description)
+	}
+}
+
+func printSchemaVersion(c *cli.Context, db *bolt.DB) error {
+	version, err := boltdb.SchemaVersion(db)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(c.App.Writer, "schema version: %d, latest: %d\n", version, boltdb.LatestVersion())
+	return nil
+}
@@ -0,0 +1,193 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/urfave/cli/v2"
+
+	"github.com/vuquang23/trustme/internal/pkg/config"
+	"github.com/vuquang23/trustme/internal/pkg/entity"
+	"github.com/vuquang23/trustme/internal/pkg/importer"
+	"github.com/vuquang23/trustme/internal/pkg/parser"
+	"github.com/vuquang23/trustme/internal/pkg/ratelimit"
+	"github.com/vuquang23/trustme/internal/pkg/repository"
+	"github.com/vuquang23/trustme/internal/pkg/tenant"
+)
+
+const listPageSize = 1000
+
+func subscriptionsCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "subscriptions",
+		Usage: "Manage subscriptions",
+		Subcommands: []*cli.Command{
+			{
+				Name:   "list",
+				Usage:  "List the subscriptions",
+				Action: listSubscriptions,
+			},
+			{
+				Name:  "add",
+				Usage: "Subscribe an address",
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:     "address",
+						Required: true,
+						Usage:    "Address to watch",
+					},
+					&cli.StringFlag{
+						Name:  "label",
+						Usage: "Label of the subscription",
+					},
+					&cli.StringSliceFlag{
+						Name:  "tag",
+						Usage: "Tag of the subscription, repeatable",
+					},
+					&cli.StringFlag{
+						Name:  "owner",
+						Usage: "Owner of the subscription",
+					},
+					&cli.StringFlag{
+						Name:  "notes",
+						Usage: "Notes of the subscription",
+					},
+				},
+				Action: addSubscription,
+			},
+			{
+				Name:  "remove",
+				Usage: "Unsubscribe an address, keeping its transactions and the subscriptions of tenants",
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:     "address",
+						Required: true,
+						Usage:    "Address to stop watching",
+					},
+				},
+				Action: removeSubscription,
+			},
+			{
+				Name:  "import",
+				Usage: "Subscribe the addresses of a CSV, NDJSON or JSON file",
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:     "file",
+						Aliases:  []string{"f"},
+						Required: true,
+						Usage:    "File to import",
+					},
+					&cli.StringFlag{
+						Name:  "format",
+						Usage: "csv, ndjson or json, guessed from the file extension when empty",
+					},
+				},
+				Action: importSubscriptions,
+			},
+		},
+	}
+}
+
+func listSubscriptions(c *cli.Context) error {
+	return withParser(c, false, func(_ config.Config, _ *repository.Repositories, p *parser.Parser) error {
+		cursor := ""
+		for {
+			page, err := p.GetSubscriptions(cursor, listPageSize)
+			if err != nil {
+				return err
+			}
+
+			for _, s := range page.Subscriptions {
+				fmt.Fprintf(c.App.Writer, "%s\t%s\ttags=%s\towner=%s\t%s\n", common.HexToAddress(s.Address).Hex(),
+					s.Label, strings.Join(s.Tags, ";"), s.Owner, s.CreatedAt.Format(time.RFC3339))
+			}
+
+			if page.NextCursor == "" {
+				return nil
+			}
+			cursor = page.NextCursor
+		}
+	})
+}
+
+func addSubscription(c *cli.Context) error {
+	return withSubscriptions(c, func(conf config.Config, p *tenant.Parser) error {
+		address, err := entity.ParseAddressKey(c.String("address"), conf.API.EnforceChecksum)
+		if err != nil {
+			return err
+		}
+
+		return p.AddSubscription(&entity.Subscription{
+			Address: address,
+			Label:   c.String("label"),
+			Tags:    c.StringSlice("tag"),
+			Owner:   c.String("owner"),
+			Notes:   c.String("notes"),
+		})
+	})
+}
+
+func removeSubscription(c *cli.Context) error {
+	return withSubscriptions(c, func(conf config.Config, p *tenant.Parser) error {
+		address, err := entity.ParseAddressKey(c.String("address"), conf.API.EnforceChecksum)
+		if err != nil {
+			return err
+		}
+
+		if err := p.Unsubscribe(address); err != nil {
+			return err
+		}
+		if _, err := p.GetSubscription(address); err == nil {
+			fmt.Fprintf(c.App.Writer, "%s is still watched for the tenants subscribing to it\n", common.HexToAddress(address).Hex())
+		}
+		return nil
+	})
+}
+
+func importSubscriptions(c *cli.Context) error {
+	f, err := os.Open(c.String("file"))
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	format := importer.Format(c.String("format"))
+	if format == "" {
+		format = importer.FormatFromFilename(f.Name())
+	}
+
+	items, err := importer.Decode(f, format)
+	if err != nil {
+		return err
+	}
+
+	return withSubscriptions(c, func(conf config.Config, p *tenant.Parser) error {
+		result := importer.Import(items, conf.API.EnforceChecksum, p)
+		for _, item := range result.Items {
+			if item.Status != entity.ImportStatusCreated {
+				fmt.Fprintf(c.App.Writer, "line %d\t%s\t%s\t%s\n", item.Line, item.Address, item.Status, item.Error)
+			}
+		}
+		fmt.Fprintf(c.App.Writer, "created: %d, duplicates: %d, invalid: %d, failed: %d\n",
+			result.Created, result.Duplicates, result.Invalid, result.Failed)
+
+		return nil
+	})
+}
+
+// withSubscriptions runs fn with the parser as seen without a tenant. The subscriptions of the commands are owned
+// like the unscoped ones of the API: removing one keeps the address watched while tenants subscribe to it.
+func withSubscriptions(c *cli.Context, fn func(conf config.Config, p *tenant.Parser) error) error {
+	return withParser(c, false, func(conf config.Config, repos *repository.Repositories, p *parser.Parser) error {
+		// unscoped subscriptions count against no quota
+		limiter, err := ratelimit.New(conf.RateLimit)
+		if err != nil {
+			return err
+		}
+
+		return fn(conf, tenant.New(p, repos.Tenant, limiter).Parser(c.Context))
+	})
+}
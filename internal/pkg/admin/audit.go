@@ -0,0 +1,43 @@
+package admin
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/vuquang23/trustme/internal/pkg/auth"
+	"github.com/vuquang23/trustme/pkg/logger"
+)
+
+// Audit logs every admin action, that is every request but the reads, with who made it and its outcome.
+// The entries are logged at warn level so they are kept when the log level is raised.
+func Audit() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.Request.Method == http.MethodGet || c.Request.Method == http.MethodHead {
+			c.Next()
+			return
+		}
+
+		var buf bytes.Buffer
+		body, _ := io.ReadAll(io.TeeReader(c.Request.Body, &buf))
+		c.Request.Body = io.NopCloser(&buf)
+
+		c.Next()
+
+		fields := logger.Fields{
+			"audit.action":    c.Request.Method + " " + c.FullPath(),
+			"audit.uri":       c.Request.URL.RequestURI(),
+			"audit.body":      string(body),
+			"audit.client_ip": c.ClientIP(),
+			"audit.status":    c.Writer.Status(),
+			"audit.principal": "anonymous",
+		}
+		if principal := auth.PrincipalFromContext(c.Request.Context()); principal != nil {
+			fields["audit.principal"] = principal.KeyID
+			fields["audit.tenant"] = principal.Tenant
+		}
+		logger.WithFields(c, fields).Warn("admin action")
+	}
+}
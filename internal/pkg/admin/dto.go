@@ -0,0 +1,125 @@
+package admin
+
+import (
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/vuquang23/trustme/internal/pkg/api"
+	"github.com/vuquang23/trustme/internal/pkg/entity"
+	"github.com/vuquang23/trustme/internal/pkg/ratelimit"
+)
+
+type KeyResponse struct {
+	ID     string `json:"id"`
+	Name   string `json:"name"`
+	Tenant string `json:"tenant,omitempty"`
+	Admin  bool   `json:"admin"`
+	// Key is only returned when the key is created.
+	Key       string    `json:"key,omitempty"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+func newKeyResponse(key *entity.APIKey) KeyResponse {
+	return KeyResponse{
+		ID:        key.ID,
+		Name:      key.Name,
+		Tenant:    key.Tenant,
+		Admin:     key.Admin,
+		CreatedAt: key.CreatedAt,
+	}
+}
+
+type Limit struct {
+	// Rate is the number of requests per second, 0 for no limit.
+	Rate  float64 `json:"rate"`
+	Burst int     `json:"burst"`
+}
+
+type GroupLimit struct {
+	Prefix string `json:"prefix"`
+	Limit
+}
+
+type RateLimits struct {
+	// Enabled switches the rate limiting on or off, it is left as is when omitted.
+	Enabled             *bool        `json:"enabled"`
+	Default             Limit        `json:"default"`
+	Groups              []GroupLimit `json:"groups"`
+	SubscriptionsPerDay int          `json:"subscriptionsPerDay"`
+}
+
+func newRateLimits(limits ratelimit.Limits) RateLimits {
+	groups := make([]GroupLimit, 0, len(limits.Groups))
+	for _, g := range limits.Groups {
+		groups = append(groups, GroupLimit{Prefix: g.Prefix, Limit: Limit{Rate: g.Rate, Burst: g.Burst}})
+	}
+	return RateLimits{
+		Enabled:             &limits.Enabled,
+		Default:             Limit{Rate: limits.Default.Rate, Burst: limits.Default.Burst},
+		Groups:              groups,
+		SubscriptionsPerDay: limits.SubscriptionsPerDay,
+	}
+}
+
+// toLimits returns the limits, enabled as given or else as they are.
+func (l RateLimits) toLimits(enabled bool) ratelimit.Limits {
+	groups := make([]ratelimit.GroupLimit, 0, len(l.Groups))
+	for _, g := range l.Groups {
+		groups = append(groups, ratelimit.GroupLimit{Prefix: g.Prefix, Rate: g.Rate, Burst: g.Burst})
+	}
+	if l.Enabled != nil {
+		enabled = *l.Enabled
+	}
+	return ratelimit.Limits{
+		Enabled:             enabled,
+		Default:             ratelimit.Limit{Rate: l.Default.Rate, Burst: l.Default.Burst},
+		Groups:              groups,
+		SubscriptionsPerDay: l.SubscriptionsPerDay,
+	}
+}
+
+type ParserResponse struct {
+	Paused               bool               `json:"paused"`
+	Checkpoint           uint64             `json:"checkpoint"`
+	QueueDepth           int                `json:"queueDepth"`
+	QueueCapacity        int                `json:"queueCapacity"`
+	PendingConfirmations int                `json:"pendingConfirmations"`
+	FailedBlocks         int                `json:"failedBlocks"`
+	Status               api.StatusResponse `json:"status"`
+}
+
+func newParserResponse(internals *entity.ParserInternals) ParserResponse {
+	return ParserResponse{
+		Paused:               internals.Paused,
+		Checkpoint:           internals.Checkpoint,
+		QueueDepth:           internals.QueueDepth,
+		QueueCapacity:        internals.QueueCapacity,
+		PendingConfirmations: internals.PendingConfirmations,
+		FailedBlocks:         internals.FailedBlocks,
+		Status:               api.NewStatusResponse(internals.Sync),
+	}
+}
+
+type FailedBlockResponse struct {
+	Hash          string    `json:"hash,omitempty"`
+	Number        uint64    `json:"number"`
+	Error         string    `json:"error"`
+	Attempts      int       `json:"attempts"`
+	FirstFailedAt time.Time `json:"firstFailedAt"`
+	LastFailedAt  time.Time `json:"lastFailedAt"`
+}
+
+func newFailedBlockResponse(block entity.FailedBlock) FailedBlockResponse {
+	response := FailedBlockResponse{
+		Number:        block.Number,
+		Error:         block.Error,
+		Attempts:      block.Attempts,
+		FirstFailedAt: block.FirstFailedAt,
+		LastFailedAt:  block.LastFailedAt,
+	}
+	if block.Hash != (common.Hash{}) {
+		response.Hash = block.Hash.Hex()
+	}
+	return response
+}
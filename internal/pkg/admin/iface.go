@@ -0,0 +1,34 @@
+package admin
+
+import (
+	"github.com/vuquang23/trustme/internal/pkg/entity"
+	"github.com/vuquang23/trustme/internal/pkg/ratelimit"
+)
+
+type IKeys interface {
+	// generate a key, returned along with its stored form
+	CreateKey(name, tenant string, admin bool) (string, *entity.APIKey, error)
+	ListKeys() ([]*entity.APIKey, error)
+	RevokeKey(id string) error
+}
+
+type IRateLimiter interface {
+	Limits() ratelimit.Limits
+	// replace the limits, taking effect for the next requests
+	SetLimits(limits ratelimit.Limits) error
+}
+
+type IParser interface {
+	// stop and restart processing new blocks, the skipped ones are reprocessed after resuming
+	Pause()
+	Resume()
+	// drop the new heads subscription for a new one
+	Reconnect()
+	// process a range of blocks up to the checkpoint again in the background
+	Reprocess(from, to uint64, publish bool) error
+	// stop the running reprocess, telling whether there was one
+	CancelReprocess() bool
+	FailedBlocks() []entity.FailedBlock
+	ClearFailedBlocks()
+	Internals() (*entity.ParserInternals, error)
+}
@@ -0,0 +1,124 @@
+package admin
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"github.com/vuquang23/trustme/internal/pkg/api"
+	"github.com/vuquang23/trustme/pkg/logger"
+)
+
+// GetParser dumps the internals of the parser.
+func GetParser(parser IParser) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		internals, err := parser.Internals()
+		if err != nil {
+			api.RespondFailure(c, err)
+			return
+		}
+
+		api.RespondSuccess(c, newParserResponse(internals))
+	}
+}
+
+// Pause stops processing new blocks until Resume.
+func Pause(parser IParser) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		parser.Pause()
+		api.RespondSuccess(c, true)
+	}
+}
+
+// Resume processes new blocks again, the blocks skipped while paused are reprocessed in the background.
+func Resume(parser IParser) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		parser.Resume()
+		api.RespondSuccess(c, true)
+	}
+}
+
+// Reconnect makes the parser subscribe to new heads again.
+func Reconnect(parser IParser) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		parser.Reconnect()
+		api.RespondSuccess(c, true)
+	}
+}
+
+type ReprocessParams struct {
+	From uint64 `json:"from" binding:"required"`
+	// To defaults to From, to reprocess a single block.
+	To      uint64 `json:"to"`
+	Publish bool   `json:"publish"`
+}
+
+// Reprocess starts processing a block or a range of blocks again. It returns once the reprocess is started,
+// its progress is reported by the status.
+func Reprocess(parser IParser) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var params ReprocessParams
+		if err := c.ShouldBindJSON(&params); err != nil {
+			logger.Error(c, err.Error())
+			api.RespondFailure(c, invalidParams(err))
+			return
+		}
+		if params.To == 0 {
+			params.To = params.From
+		}
+
+		if err := parser.Reprocess(params.From, params.To, params.Publish); err != nil {
+			api.RespondFailure(c, err)
+			return
+		}
+
+		api.RespondSuccess(c, true)
+	}
+}
+
+// CancelReprocess stops the running reprocess, the response tells whether there was one.
+func CancelReprocess(parser IParser) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		api.RespondSuccess(c, parser.CancelReprocess())
+	}
+}
+
+func GetFailedBlocks(parser IParser) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		blocks := parser.FailedBlocks()
+
+		items := make([]FailedBlockResponse, 0, len(blocks))
+		for _, block := range blocks {
+			items = append(items, newFailedBlockResponse(block))
+		}
+		api.RespondSuccess(c, items)
+	}
+}
+
+func ClearFailedBlocks(parser IParser) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		parser.ClearFailedBlocks()
+		api.RespondSuccess(c, true)
+	}
+}
+
+type LogLevelParams struct {
+	Level string `json:"level" binding:"required,oneof=debug info warn error fatal"`
+}
+
+// SetLogLevel changes the level of the logs until the next restart.
+func SetLogLevel() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var params LogLevelParams
+		if err := c.ShouldBindJSON(&params); err != nil {
+			logger.Error(c, err.Error())
+			api.RespondFailure(c, invalidParams(err))
+			return
+		}
+
+		if err := logger.SetLogLevel(params.Level); err != nil {
+			api.RespondFailure(c, err)
+			return
+		}
+
+		api.RespondSuccess(c, params)
+	}
+}
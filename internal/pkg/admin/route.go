@@ -0,0 +1,127 @@
+package admin
+
+import (
+	"fmt"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/vuquang23/trustme/internal/pkg/api"
+	"github.com/vuquang23/trustme/internal/pkg/entity"
+	"github.com/vuquang23/trustme/pkg/logger"
+)
+
+// SetupRoute registers the admin routes, only served to admin principals. Every action is audit-logged.
+func SetupRoute(engine *gin.Engine, keys IKeys, limiter IRateLimiter, parser IParser) {
+	rg := engine.Group("/admin", api.RequireAdmin(), Audit())
+
+	rg.POST("/keys", CreateKey(keys))
+	rg.GET("/keys", GetKeys(keys))
+	rg.DELETE("/keys/:id", RevokeKey(keys))
+	rg.GET("/rate-limits", GetRateLimits(limiter))
+	rg.PUT("/rate-limits", SetRateLimits(limiter))
+	rg.PUT("/log-level", SetLogLevel())
+
+	rg.GET("/parser", GetParser(parser))
+	rg.POST("/parser/pause", Pause(parser))
+	rg.POST("/parser/resume", Resume(parser))
+	rg.POST("/parser/reconnect", Reconnect(parser))
+	rg.POST("/parser/reprocess", Reprocess(parser))
+	rg.DELETE("/parser/reprocess", CancelReprocess(parser))
+	rg.GET("/parser/failed-blocks", GetFailedBlocks(parser))
+	rg.DELETE("/parser/failed-blocks", ClearFailedBlocks(parser))
+}
+
+type CreateKeyParams struct {
+	Name   string `json:"name"`
+	Tenant string `json:"tenant"`
+	Admin  bool   `json:"admin"`
+}
+
+// CreateKey generates an API key. The response is the only one carrying the key.
+func CreateKey(keys IKeys) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var params CreateKeyParams
+		if err := c.ShouldBindJSON(&params); err != nil {
+			logger.Error(c, err.Error())
+			api.RespondFailure(c, invalidParams(err))
+			return
+		}
+
+		key, apiKey, err := keys.CreateKey(params.Name, params.Tenant, params.Admin)
+		if err != nil {
+			api.RespondFailure(c, err)
+			return
+		}
+
+		response := newKeyResponse(apiKey)
+		response.Key = key
+		api.RespondSuccess(c, response)
+	}
+}
+
+func GetKeys(keys IKeys) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		list, err := keys.ListKeys()
+		if err != nil {
+			api.RespondFailure(c, err)
+			return
+		}
+
+		items := make([]KeyResponse, 0, len(list))
+		for _, key := range list {
+			items = append(items, newKeyResponse(key))
+		}
+		api.RespondSuccess(c, items)
+	}
+}
+
+type KeyIDParams struct {
+	ID string `uri:"id" binding:"required"`
+}
+
+func RevokeKey(keys IKeys) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var params KeyIDParams
+		if err := c.ShouldBindUri(&params); err != nil {
+			logger.Error(c, err.Error())
+			api.RespondFailure(c, invalidParams(err))
+			return
+		}
+
+		if err := keys.RevokeKey(params.ID); err != nil {
+			api.RespondFailure(c, err)
+			return
+		}
+
+		api.RespondSuccess(c, true)
+	}
+}
+
+func GetRateLimits(limiter IRateLimiter) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		api.RespondSuccess(c, newRateLimits(limiter.Limits()))
+	}
+}
+
+// SetRateLimits replaces the rate limits and the subscription quota until the next restart.
+func SetRateLimits(limiter IRateLimiter) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var params RateLimits
+		if err := c.ShouldBindJSON(&params); err != nil {
+			logger.Error(c, err.Error())
+			api.RespondFailure(c, invalidParams(err))
+			return
+		}
+
+		if err := limiter.SetLimits(params.toLimits(limiter.Limits().Enabled)); err != nil {
+			api.RespondFailure(c, err)
+			return
+		}
+
+		api.RespondSuccess(c, newRateLimits(limiter.Limits()))
+	}
+}
+
+func invalidParams(err error) error {
+	return fmt.Errorf("%w: %v", entity.ErrInvalidParams, err)
+}
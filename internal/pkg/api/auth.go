@@ -0,0 +1,59 @@
+package api
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/vuquang23/trustme/internal/pkg/auth"
+	"github.com/vuquang23/trustme/internal/pkg/entity"
+)
+
+const HeaderAPIKey = "X-API-Key"
+
+// Authenticate requires an API key, in the X-API-Key header or as a bearer token, or a JWT bearer token
+// on every path but the public ones, and puts the principal in the request context. It lets everything
+// through when authentication is disabled.
+func Authenticate(authenticator IAuthenticator) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !authenticator.Enabled() || c.Request.Method == http.MethodOptions || authenticator.IsPublic(c.Request.URL.Path) {
+			c.Next()
+			return
+		}
+
+		principal, err := authenticator.Authenticate(credential(c.Request))
+		if err != nil {
+			c.Header("WWW-Authenticate", `Bearer realm="trustme"`)
+			RespondFailure(c, err)
+			c.Abort()
+			return
+		}
+
+		c.Request = c.Request.WithContext(auth.WithPrincipal(c.Request.Context(), principal))
+		c.Next()
+	}
+}
+
+// RequireAdmin refuses the requests of non admin principals. Without authentication there is no admin, every
+// request is refused.
+func RequireAdmin() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if principal := auth.PrincipalFromContext(c.Request.Context()); principal == nil || !principal.Admin {
+			RespondFailure(c, entity.ErrForbidden)
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+func credential(r *http.Request) string {
+	if key := r.Header.Get(HeaderAPIKey); key != "" {
+		return key
+	}
+	if token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer "); ok {
+		return strings.TrimSpace(token)
+	}
+	return ""
+}
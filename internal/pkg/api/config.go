@@ -0,0 +1,28 @@
+package api
+
+import "time"
+
+type Config struct {
+	// EnforceChecksum rejects addresses that are not EIP-55 checksummed, lowercase included.
+	EnforceChecksum bool
+	// MaxBatchSize is the maximum number of addresses accepted by one batch subscribe request.
+	MaxBatchSize int `default:"100000"`
+	// ValidateRequests rejects requests not matching the OpenAPI specification before they reach the handlers.
+	ValidateRequests bool `default:"true"`
+	// Docs serves the Swagger UI docs of the specification at /api/docs.
+	Docs bool `default:"true"`
+
+	// MaxStreamAddresses is the maximum number of addresses one event stream can watch.
+	MaxStreamAddresses int `default:"100"`
+	// StreamBufferSize is the number of events buffered per stream before a slow client is dropped.
+	StreamBufferSize int `default:"256"`
+	// StreamHeartbeat is the interval of keepalive comments sent on idle streams.
+	StreamHeartbeat time.Duration `default:"15s"`
+
+	// WSMaxSubscriptions is the maximum number of addresses one WebSocket connection can subscribe to.
+	WSMaxSubscriptions int `default:"1000"`
+	// WSBufferSize is the number of events queued per WebSocket connection before a slow client is disconnected.
+	WSBufferSize int `default:"1024"`
+	// WSPingInterval is the interval of ping frames, a client not answering within two intervals is disconnected.
+	WSPingInterval time.Duration `default:"30s"`
+}
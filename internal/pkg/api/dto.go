@@ -0,0 +1,553 @@
+package api
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+
+	"github.com/vuquang23/trustme/internal/pkg/entity"
+	"github.com/vuquang23/trustme/internal/pkg/util/ether"
+)
+
+type TxRecordResponse struct {
+	Hash      string `json:"hash"`
+	Address   string `json:"address"`
+	Direction string `json:"direction"`
+	From      string `json:"from"`
+	To        string `json:"to,omitempty"`
+	Type      uint8  `json:"type"`
+	Nonce     uint64 `json:"nonce"`
+	Input     string `json:"input"`
+
+	Value      string `json:"value"`
+	ValueEther string `json:"valueEther"`
+
+	Gas               uint64 `json:"gas"`
+	GasUsed           uint64 `json:"gasUsed"`
+	EffectiveGasPrice string `json:"effectiveGasPrice"`
+	Fee               string `json:"fee"`
+	FeeEther          string `json:"feeEther"`
+
+	BlockNumber uint64 `json:"blockNumber"`
+	BlockHash   string `json:"blockHash"`
+	Timestamp   uint64 `json:"timestamp"`
+	TxIndex     uint   `json:"txIndex"`
+	Status      string `json:"status"`
+}
+
+func NewTxRecordResponse(record *entity.TxRecord) TxRecordResponse {
+	var to string
+	if record.Tx.To() != nil {
+		to = record.Tx.To().Hex()
+	}
+
+	var effectiveGasPrice string
+	if record.EffectiveGasPrice != nil {
+		effectiveGasPrice = record.EffectiveGasPrice.String()
+	}
+
+	fee := record.Fee()
+
+	return TxRecordResponse{
+		Hash:              record.Hash().Hex(),
+		Address:           common.HexToAddress(record.Address).Hex(),
+		Direction:         string(record.Direction),
+		From:              record.From.Hex(),
+		To:                to,
+		Type:              record.Tx.Type(),
+		Nonce:             record.Tx.Nonce(),
+		Input:             hexutil.Encode(record.Tx.Data()),
+		Value:             record.Tx.Value().String(),
+		ValueEther:        ether.FormatWei(record.Tx.Value()),
+		Gas:               record.Tx.Gas(),
+		GasUsed:           record.GasUsed,
+		EffectiveGasPrice: effectiveGasPrice,
+		Fee:               fee.String(),
+		FeeEther:          ether.FormatWei(fee),
+		BlockNumber:       record.BlockNumber,
+		BlockHash:         record.BlockHash.Hex(),
+		Timestamp:         record.Timestamp,
+		TxIndex:           record.TxIndex,
+		Status:            string(record.Status),
+	}
+}
+
+func newTxRecordResponses(records []*entity.TxRecord) []TxRecordResponse {
+	responses := make([]TxRecordResponse, 0, len(records))
+	for _, record := range records {
+		responses = append(responses, NewTxRecordResponse(record))
+	}
+	return responses
+}
+
+// RawTransactionResponse is a transaction in the encoding of the Ethereum JSON-RPC API, its recipient checksummed.
+type RawTransactionResponse struct {
+	tx *types.Transaction
+}
+
+func (r RawTransactionResponse) MarshalJSON() ([]byte, error) {
+	raw, err := r.tx.MarshalJSON()
+	if err != nil || r.tx.To() == nil {
+		return raw, err
+	}
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &fields); err != nil {
+		return nil, err
+	}
+	fields["to"], err = json.Marshal(r.tx.To().Hex())
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(fields)
+}
+
+func NewRawTransactionResponses(txs []*types.Transaction) []RawTransactionResponse {
+	responses := make([]RawTransactionResponse, 0, len(txs))
+	for _, tx := range txs {
+		responses = append(responses, RawTransactionResponse{tx: tx})
+	}
+	return responses
+}
+
+type TxPageResponse struct {
+	Items      []TxRecordResponse `json:"items"`
+	NextCursor string             `json:"nextCursor,omitempty"`
+	Total      *int               `json:"total,omitempty"`
+}
+
+func NewTxPageResponse(page *entity.TxPage) TxPageResponse {
+	return TxPageResponse{
+		Items:      newTxRecordResponses(page.Records),
+		NextCursor: page.NextCursor,
+		Total:      page.Total,
+	}
+}
+
+type SubscriptionResponse struct {
+	Address   string    `json:"address"`
+	Label     string    `json:"label"`
+	Tags      []string  `json:"tags"`
+	Owner     string    `json:"owner"`
+	Notes     string    `json:"notes"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+type SubscriptionPageResponse struct {
+	Items      []SubscriptionResponse `json:"items"`
+	NextCursor string                 `json:"nextCursor,omitempty"`
+	Total      int                    `json:"total"`
+}
+
+func NewSubscriptionResponse(subscription *entity.Subscription) SubscriptionResponse {
+	tags := subscription.Tags
+	if tags == nil {
+		tags = []string{}
+	}
+
+	return SubscriptionResponse{
+		Address:   common.HexToAddress(subscription.Address).Hex(),
+		Label:     subscription.Label,
+		Tags:      tags,
+		Owner:     subscription.Owner,
+		Notes:     subscription.Notes,
+		CreatedAt: subscription.CreatedAt,
+	}
+}
+
+func NewSubscriptionPageResponse(page *entity.SubscriptionPage) SubscriptionPageResponse {
+	items := make([]SubscriptionResponse, 0, len(page.Subscriptions))
+	for _, subscription := range page.Subscriptions {
+		items = append(items, NewSubscriptionResponse(subscription))
+	}
+
+	return SubscriptionPageResponse{
+		Items:      items,
+		NextCursor: page.NextCursor,
+		Total:      page.Total,
+	}
+}
+
+type ImportItemResultResponse struct {
+	Line    int    `json:"line"`
+	Address string `json:"address"`
+	Status  string `json:"status"`
+	Error   string `json:"error,omitempty"`
+}
+
+type ImportResultResponse struct {
+	Created    int                        `json:"created"`
+	Duplicates int                        `json:"duplicates"`
+	Invalid    int                        `json:"invalid"`
+	Failed     int                        `json:"failed"`
+	Items      []ImportItemResultResponse `json:"items"`
+}
+
+func newImportResultResponse(result *entity.ImportResult) ImportResultResponse {
+	items := make([]ImportItemResultResponse, 0, len(result.Items))
+	for _, item := range result.Items {
+		items = append(items, ImportItemResultResponse{
+			Line:    item.Line,
+			Address: item.Address,
+			Status:  string(item.Status),
+			Error:   item.Error,
+		})
+	}
+
+	return ImportResultResponse{
+		Created:    result.Created,
+		Duplicates: result.Duplicates,
+		Invalid:    result.Invalid,
+		Failed:     result.Failed,
+		Items:      items,
+	}
+}
+
+type TxPartyResponse struct {
+	Address   string `json:"address"`
+	Direction string `json:"direction"`
+}
+
+type LogResponse struct {
+	LogIndex uint              `json:"logIndex"`
+	Address  string            `json:"address"`
+	Topics   []string          `json:"topics"`
+	Data     string            `json:"data"`
+	Event    string            `json:"event,omitempty"`
+	Args     map[string]string `json:"args,omitempty"`
+}
+
+type ReceiptResponse struct {
+	Status            string        `json:"status"`
+	GasUsed           uint64        `json:"gasUsed"`
+	CumulativeGasUsed uint64        `json:"cumulativeGasUsed"`
+	EffectiveGasPrice string        `json:"effectiveGasPrice"`
+	Fee               string        `json:"fee"`
+	FeeEther          string        `json:"feeEther"`
+	ContractAddress   string        `json:"contractAddress,omitempty"`
+	Logs              []LogResponse `json:"logs"`
+}
+
+type TxDetailResponse struct {
+	Hash    string `json:"hash"`
+	Indexed bool   `json:"indexed"`
+	Pending bool   `json:"pending"`
+
+	From       string `json:"from"`
+	To         string `json:"to,omitempty"`
+	Type       uint8  `json:"type"`
+	Nonce      uint64 `json:"nonce"`
+	Input      string `json:"input"`
+	Gas        uint64 `json:"gas"`
+	Value      string `json:"value"`
+	ValueEther string `json:"valueEther"`
+
+	BlockNumber uint64 `json:"blockNumber,omitempty"`
+	BlockHash   string `json:"blockHash,omitempty"`
+	Timestamp   uint64 `json:"timestamp,omitempty"`
+	TxIndex     uint   `json:"txIndex"`
+
+	Receipt *ReceiptResponse  `json:"receipt"`
+	Parties []TxPartyResponse `json:"parties"`
+}
+
+func NewTxDetailResponse(detail *entity.TxDetail) TxDetailResponse {
+	var to string
+	if detail.Tx.To() != nil {
+		to = detail.Tx.To().Hex()
+	}
+
+	response := TxDetailResponse{
+		Hash:        detail.Tx.Hash().Hex(),
+		Indexed:     detail.Indexed,
+		Pending:     detail.Pending,
+		From:        detail.From.Hex(),
+		To:          to,
+		Type:        detail.Tx.Type(),
+		Nonce:       detail.Tx.Nonce(),
+		Input:       hexutil.Encode(detail.Tx.Data()),
+		Gas:         detail.Tx.Gas(),
+		Value:       detail.Tx.Value().String(),
+		ValueEther:  ether.FormatWei(detail.Tx.Value()),
+		BlockNumber: detail.BlockNumber,
+		TxIndex:     detail.TxIndex,
+		Timestamp:   detail.Timestamp,
+		Parties:     make([]TxPartyResponse, 0, len(detail.Records)),
+	}
+	if detail.BlockHash != (common.Hash{}) {
+		response.BlockHash = detail.BlockHash.Hex()
+	}
+
+	for _, record := range detail.Records {
+		response.Parties = append(response.Parties, TxPartyResponse{
+			Address:   common.HexToAddress(record.Address).Hex(),
+			Direction: string(record.Direction),
+		})
+	}
+
+	if detail.Receipt != nil {
+		response.Receipt = newReceiptResponse(detail.Receipt, detail.Logs)
+	}
+
+	return response
+}
+
+func newReceiptResponse(receipt *types.Receipt, logs []entity.DecodedLog) *ReceiptResponse {
+	fee := entity.ReceiptFee(receipt)
+
+	response := &ReceiptResponse{
+		Status:            string(entity.ReceiptStatus(receipt)),
+		GasUsed:           receipt.GasUsed,
+		CumulativeGasUsed: receipt.CumulativeGasUsed,
+		Fee:               fee.String(),
+		FeeEther:          ether.FormatWei(fee),
+		Logs:              make([]LogResponse, 0, len(logs)),
+	}
+	if receipt.EffectiveGasPrice != nil {
+		response.EffectiveGasPrice = receipt.EffectiveGasPrice.String()
+	}
+	if receipt.ContractAddress != (common.Address{}) {
+		response.ContractAddress = receipt.ContractAddress.Hex()
+	}
+
+	for _, log := range logs {
+		topics := make([]string, 0, len(log.Log.Topics))
+		for _, topic := range log.Log.Topics {
+			topics = append(topics, topic.Hex())
+		}
+
+		response.Logs = append(response.Logs, LogResponse{
+			LogIndex: log.Log.Index,
+			Address:  log.Log.Address.Hex(),
+			Topics:   topics,
+			Data:     hexutil.Encode(log.Log.Data),
+			Event:    log.Event,
+			Args:     log.Args,
+		})
+	}
+
+	return response
+}
+
+type ConfirmationResponse struct {
+	TxRecordResponse
+	Confirmations uint64 `json:"confirmations"`
+}
+
+type TokenTransferResponse struct {
+	Token       string `json:"token"`
+	From        string `json:"from"`
+	To          string `json:"to"`
+	Value       string `json:"value,omitempty"`
+	TokenID     string `json:"tokenId,omitempty"`
+	TxHash      string `json:"txHash"`
+	LogIndex    uint   `json:"logIndex"`
+	BlockNumber uint64 `json:"blockNumber"`
+	BlockHash   string `json:"blockHash"`
+	TxIndex     uint   `json:"txIndex"`
+}
+
+func newTokenTransferResponse(transfer *entity.TokenTransfer) TokenTransferResponse {
+	response := TokenTransferResponse{
+		Token:       transfer.Token.Hex(),
+		From:        transfer.From.Hex(),
+		To:          transfer.To.Hex(),
+		TxHash:      transfer.TxHash.Hex(),
+		LogIndex:    transfer.LogIndex,
+		BlockNumber: transfer.BlockNumber,
+		BlockHash:   transfer.BlockHash.Hex(),
+		TxIndex:     transfer.TxIndex,
+	}
+	if transfer.Value != nil {
+		response.Value = transfer.Value.String()
+	}
+	if transfer.TokenID != nil {
+		response.TokenID = transfer.TokenID.String()
+	}
+	return response
+}
+
+// EventData is the data of an event in the WebSocket messages, the webhook deliveries and the broker messages.
+func EventData(event entity.Event) interface{} {
+	switch event.Type {
+	case entity.EventTypeTokenTransfer:
+		return newTokenTransferResponse(event.Transfer)
+	case entity.EventTypeBlock:
+		return BlockResponse{
+			Number:     event.Block.Number,
+			Hash:       event.Block.Hash.Hex(),
+			ParentHash: event.Block.ParentHash.Hex(),
+			Timestamp:  event.Block.Timestamp,
+			TxCount:    event.Block.TxCount,
+			MatchedTxs: event.Block.MatchedTxs,
+		}
+	case entity.EventTypeConfirmation:
+		return ConfirmationResponse{
+			TxRecordResponse: NewTxRecordResponse(event.Record),
+			Confirmations:    event.Confirmations,
+		}
+	default:
+		return NewTxRecordResponse(event.Record)
+	}
+}
+
+type BlockResponse struct {
+	Number     uint64 `json:"number"`
+	Hash       string `json:"hash"`
+	ParentHash string `json:"parentHash"`
+	Timestamp  uint64 `json:"timestamp"`
+	TxCount    int    `json:"txCount"`
+	MatchedTxs int    `json:"matchedTxs"`
+}
+
+type WebhookResponse struct {
+	ID        string    `json:"id"`
+	Address   string    `json:"address"`
+	URL       string    `json:"url"`
+	Secret    string    `json:"secret,omitempty"`
+	Events    []string  `json:"events"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// newWebhookResponse hides the secret unless withSecret, it is only returned when the webhook is created.
+func newWebhookResponse(webhook *entity.Webhook, withSecret bool) WebhookResponse {
+	events := make([]string, 0, len(webhook.Events))
+	for _, event := range webhook.Events {
+		events = append(events, string(event))
+	}
+
+	response := WebhookResponse{
+		ID:        webhook.ID,
+		Address:   common.HexToAddress(webhook.Address).Hex(),
+		URL:       webhook.URL,
+		Events:    events,
+		CreatedAt: webhook.CreatedAt,
+	}
+	if withSecret {
+		response.Secret = webhook.Secret
+	}
+	return response
+}
+
+type DeliveryAttemptResponse struct {
+	At         time.Time `json:"at"`
+	StatusCode int       `json:"statusCode,omitempty"`
+	Error      string    `json:"error,omitempty"`
+	DurationMs int64     `json:"durationMs"`
+}
+
+type DeliveryResponse struct {
+	ID            string                    `json:"id"`
+	WebhookID     string                    `json:"webhookId"`
+	Event         string                    `json:"event"`
+	Address       string                    `json:"address"`
+	Status        string                    `json:"status"`
+	Attempts      []DeliveryAttemptResponse `json:"attempts"`
+	NextAttemptAt *time.Time                `json:"nextAttemptAt,omitempty"`
+	CreatedAt     time.Time                 `json:"createdAt"`
+	Payload       json.RawMessage           `json:"payload"`
+}
+
+type DeliveryPageResponse struct {
+	Items      []DeliveryResponse `json:"items"`
+	NextCursor string             `json:"nextCursor,omitempty"`
+}
+
+func newDeliveryResponse(delivery *entity.WebhookDelivery) DeliveryResponse {
+	response := DeliveryResponse{
+		ID:        delivery.ID,
+		WebhookID: delivery.WebhookID,
+		Event:     string(delivery.Event),
+		Address:   common.HexToAddress(delivery.Address).Hex(),
+		Status:    string(delivery.Status),
+		Attempts:  make([]DeliveryAttemptResponse, 0, len(delivery.Attempts)),
+		CreatedAt: delivery.CreatedAt,
+		Payload:   delivery.Payload,
+	}
+	if delivery.Status == entity.DeliveryStatusPending {
+		nextAttemptAt := delivery.NextAttemptAt
+		response.NextAttemptAt = &nextAttemptAt
+	}
+
+	for _, attempt := range delivery.Attempts {
+		response.Attempts = append(response.Attempts, DeliveryAttemptResponse{
+			At:         attempt.At,
+			StatusCode: attempt.StatusCode,
+			Error:      attempt.Error,
+			DurationMs: attempt.Duration.Milliseconds(),
+		})
+	}
+	return response
+}
+
+func newDeliveryPageResponse(page *entity.DeliveryPage) DeliveryPageResponse {
+	items := make([]DeliveryResponse, 0, len(page.Deliveries))
+	for _, delivery := range page.Deliveries {
+		items = append(items, newDeliveryResponse(delivery))
+	}
+
+	return DeliveryPageResponse{
+		Items:      items,
+		NextCursor: page.NextCursor,
+	}
+}
+
+type WSStatusResponse struct {
+	Connected   bool       `json:"connected"`
+	ConnectedAt *time.Time `json:"connectedAt,omitempty"`
+	LastHeadAt  *time.Time `json:"lastHeadAt,omitempty"`
+	Reconnects  int        `json:"reconnects"`
+	LastError   string     `json:"lastError,omitempty"`
+}
+
+type BackfillResponse struct {
+	From    uint64 `json:"from"`
+	To      uint64 `json:"to"`
+	Current uint64 `json:"current"`
+}
+
+type StatusResponse struct {
+	CurrentBlock    uint64            `json:"currentBlock"`
+	ChainHead       uint64            `json:"chainHead"`
+	Lag             uint64            `json:"lag"`
+	LastBlockTime   *time.Time        `json:"lastBlockTime,omitempty"`
+	LastProcessedAt *time.Time        `json:"lastProcessedAt,omitempty"`
+	WS              WSStatusResponse  `json:"ws"`
+	Backfill        *BackfillResponse `json:"backfill,omitempty"`
+}
+
+func NewStatusResponse(status entity.SyncStatus) StatusResponse {
+	response := StatusResponse{
+		CurrentBlock:    status.CurrentBlock,
+		ChainHead:       status.ChainHead,
+		Lag:             status.Lag(),
+		LastBlockTime:   optionalTime(status.LastBlockTime),
+		LastProcessedAt: optionalTime(status.LastProcessedAt),
+		WS: WSStatusResponse{
+			Connected:   status.WS.Connected,
+			ConnectedAt: optionalTime(status.WS.ConnectedAt),
+			LastHeadAt:  optionalTime(status.WS.LastHeadAt),
+			Reconnects:  status.WS.Reconnects,
+			LastError:   status.WS.LastError,
+		},
+	}
+	if status.Backfill != nil {
+		response.Backfill = &BackfillResponse{
+			From:    status.Backfill.From,
+			To:      status.Backfill.To,
+			Current: status.Backfill.Current,
+		}
+	}
+	return response
+}
+
+// optionalTime leaves out the times that did not happen yet.
+func optionalTime(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+	return &t
+}
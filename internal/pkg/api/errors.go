@@ -0,0 +1,116 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+
+	"github.com/vuquang23/trustme/internal/pkg/entity"
+)
+
+var ErrorResponseByError = map[error]ErrorResponse{
+	entity.ErrInvalidParams: {
+		HTTPStatus: http.StatusBadRequest,
+		Code:       4000,
+		Message:    "invalid params",
+	},
+	entity.ErrInvalidAddress: {
+		HTTPStatus: http.StatusBadRequest,
+		Code:       4001,
+		Message:    "invalid address",
+	},
+	entity.ErrInvalidChecksum: {
+		HTTPStatus: http.StatusBadRequest,
+		Code:       4002,
+		Message:    "invalid address checksum",
+	},
+	entity.ErrInvalidCursor: {
+		HTTPStatus: http.StatusBadRequest,
+		Code:       4003,
+		Message:    "invalid cursor",
+	},
+	entity.ErrInvalidTxHash: {
+		HTTPStatus: http.StatusBadRequest,
+		Code:       4004,
+		Message:    "invalid tx hash",
+	},
+	entity.ErrNotSubscribed: {
+		HTTPStatus: http.StatusNotFound,
+		Code:       4040,
+		Message:    "address is not subscribed",
+	},
+	entity.ErrTxNotFound: {
+		HTTPStatus: http.StatusNotFound,
+		Code:       4041,
+		Message:    "transaction not found",
+	},
+	entity.ErrWebhookNotFound: {
+		HTTPStatus: http.StatusNotFound,
+		Code:       4042,
+		Message:    "webhook not found",
+	},
+	entity.ErrDeliveryNotFound: {
+		HTTPStatus: http.StatusNotFound,
+		Code:       4043,
+		Message:    "webhook delivery not found",
+	},
+	entity.ErrAPIKeyNotFound: {
+		HTTPStatus: http.StatusNotFound,
+		Code:       4044,
+		Message:    "api key not found",
+	},
+	entity.ErrUnauthorized: {
+		HTTPStatus: http.StatusUnauthorized,
+		Code:       4010,
+		Message:    "unauthorized",
+	},
+	entity.ErrForbidden: {
+		HTTPStatus: http.StatusForbidden,
+		Code:       4030,
+		Message:    "forbidden",
+	},
+	entity.ErrAlreadySubscribed: {
+		HTTPStatus: http.StatusConflict,
+		Code:       4090,
+		Message:    "address is already subscribed",
+	},
+	entity.ErrReprocessRunning: {
+		HTTPStatus: http.StatusConflict,
+		Code:       4091,
+		Message:    "a reprocess is already running",
+	},
+	entity.ErrRateLimited: {
+		HTTPStatus: http.StatusTooManyRequests,
+		Code:       4290,
+		Message:    "too many requests",
+	},
+	entity.ErrQuotaExceeded: {
+		HTTPStatus: http.StatusTooManyRequests,
+		Code:       4291,
+		Message:    "quota exceeded",
+	},
+	entity.ErrBackendUnavailable: {
+		HTTPStatus: http.StatusServiceUnavailable,
+		Code:       5030,
+		Message:    "backend unavailable",
+	},
+	entity.ErrNotReady: {
+		HTTPStatus: http.StatusServiceUnavailable,
+		Code:       5031,
+		Message:    "not ready",
+	},
+}
+
+func invalidParams(err error) error {
+	return fmt.Errorf("%w: %v", entity.ErrInvalidParams, err)
+}
+
+func parseTxHash(s string) (common.Hash, error) {
+	b, err := hexutil.Decode(s)
+	if err != nil || len(b) != common.HashLength {
+		return common.Hash{}, entity.ErrInvalidTxHash
+	}
+	return common.BytesToHash(b), nil
+}
@@ -0,0 +1,47 @@
+package api
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"github.com/vuquang23/trustme/internal/pkg/entity"
+	"github.com/vuquang23/trustme/internal/pkg/util/requestid"
+)
+
+// Healthz tells the process is alive, it checks nothing else.
+func Healthz() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		RespondSuccess(c, true)
+	}
+}
+
+// Readyz returns the result of each readiness check, with a 503 not ready error listing the failed ones.
+func Readyz(health IHealth) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		readiness := health.Ready(c.Request.Context())
+
+		if !readiness.Ready() {
+			// RespondFailure hides the details of 5xx errors, the failed checks are what the caller is after.
+			response := responseFromErr(entity.ErrNotReady)
+			response.RequestID = requestid.ExtractRequestID(c)
+			for _, check := range readiness.Checks {
+				if check.Error != "" {
+					response.Details = append(response.Details, check.Name+": "+check.Error)
+				}
+			}
+			c.JSON(response.HTTPStatus, response)
+			return
+		}
+
+		checks := make(map[string]string, len(readiness.Checks))
+		for _, check := range readiness.Checks {
+			checks[check.Name] = "ok"
+		}
+		RespondSuccess(c, checks)
+	}
+}
+
+func GetStatus(health IHealth) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		RespondSuccess(c, NewStatusResponse(health.Status(c.Request.Context())))
+	}
+}
@@ -11,8 +11,6 @@ import (
 	"github.com/vuquang23/trustme/pkg/logger"
 )
 
-var ErrorResponseByError = map[error]ErrorResponse{}
-
 type SuccessResponse struct {
 	Code      int         `json:"code"`
 	Message   string      `json:"message"`
@@ -58,6 +56,9 @@ func RespondFailure(c *gin.Context, err error) {
 	requestID := requestid.ExtractRequestID(c)
 	response := responseFromErr(err)
 	response.RequestID = requestID
+	if response.HTTPStatus < http.StatusInternalServerError && err.Error() != response.Message {
+		response.Details = []interface{}{err.Error()}
+	}
 
 	logger.
 		WithFields(c, logger.Fields{"request.id": requestID, "error": err}).
@@ -69,6 +70,11 @@ func RespondFailure(c *gin.Context, err error) {
 	)
 }
 
+// ResponseFromError is the error response of err, for the APIs not served by gin.
+func ResponseFromError(err error) ErrorResponse {
+	return responseFromErr(err)
+}
+
 func responseFromErr(err error) ErrorResponse {
 	for {
 		if err == nil {
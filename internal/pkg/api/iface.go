@@ -1,6 +1,16 @@
 package api
 
-import "github.com/ethereum/go-ethereum/core/types"
+import (
+	"context"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+
+	"github.com/vuquang23/trustme/internal/pkg/entity"
+	"github.com/vuquang23/trustme/internal/pkg/eventbus"
+	"github.com/vuquang23/trustme/internal/pkg/tenant"
+)
 
 type IParser interface {
 	// last parsed block
@@ -9,6 +19,66 @@ type IParser interface {
 	// add address to observer
 	Subscribe(address string) bool
 
+	// add address to observer with its metadata
+	AddSubscription(subscription *entity.Subscription) error
+
+	// remove address from observer
+	Unsubscribe(address string) error
+
+	// page of observed addresses
+	GetSubscriptions(cursor string, limit int) (*entity.SubscriptionPage, error)
+
 	// list of inbound or outbound transactions for an address
 	GetTransactions(address string) []*types.Transaction
+
+	// a transaction with its receipt and decoded logs, looked up on the RPC when not indexed
+	GetTxDetail(ctx context.Context, hash common.Hash) (*entity.TxDetail, error)
+
+	// page of transactions for an address with their block context
+	QueryTxRecords(address string, query entity.TxQuery) (*entity.TxPage, error)
+}
+
+type ITenants interface {
+	// the parser as seen by the tenant of the request, unrestricted without one
+	Parser(ctx context.Context) *tenant.Parser
+}
+
+type IAuthenticator interface {
+	// whether requests need credentials
+	Enabled() bool
+	// principal of an API key or a JWT
+	Authenticate(credential string) (*entity.Principal, error)
+	// whether a path is served without credentials
+	IsPublic(path string) bool
+}
+
+type IRateLimiter interface {
+	// take a token of the client for the route group of path, or tell how long to wait for one
+	Allow(client, path string) (bool, time.Duration)
+}
+
+type IHealth interface {
+	// result of the readiness checks
+	Ready(ctx context.Context) entity.Readiness
+	// progress of the parser against the chain
+	Status(ctx context.Context) entity.SyncStatus
+}
+
+type IEventBus interface {
+	Subscribe(buffer int, filter func(entity.Event) bool) *eventbus.Subscription
+}
+
+type IWebhooks interface {
+	// register a webhook for a subscribed address, generating its secret when empty
+	CreateWebhook(webhook *entity.Webhook) error
+	GetWebhook(id string) (*entity.Webhook, error)
+	DeleteWebhook(id string) error
+	// webhooks of an address, of every address when empty
+	ListWebhooks(address string) ([]*entity.Webhook, error)
+
+	GetDelivery(id string) (*entity.WebhookDelivery, error)
+	// page of deliveries, newest first
+	ListDeliveries(query entity.DeliveryQuery) (*entity.DeliveryPage, error)
+	// send a delivery again whatever its status
+	Replay(id string) (*entity.WebhookDelivery, error)
 }
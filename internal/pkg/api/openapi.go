@@ -0,0 +1,167 @@
+package api
+
+import (
+	"context"
+	_ "embed"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/getkin/kin-openapi/routers"
+	"github.com/gin-gonic/gin"
+	swaggerfiles "github.com/swaggo/files/v2"
+)
+
+//go:embed openapi.yaml
+var openAPIYAML []byte
+
+func init() {
+	// Validation errors are returned to clients, keep them to the point.
+	openapi3.SchemaErrorDetailsDisabled = true
+
+	openapi3filter.RegisterBodyDecoder("text/csv", openapi3filter.FileBodyDecoder)
+	openapi3filter.RegisterBodyDecoder("application/x-ndjson", openapi3filter.FileBodyDecoder)
+}
+
+// LoadSpec parses and validates the OpenAPI specification of the API, embedded from openapi.yaml.
+func LoadSpec() (*openapi3.T, error) {
+	spec, err := openapi3.NewLoader().LoadFromData(openAPIYAML)
+	if err != nil {
+		return nil, fmt.Errorf("load openapi spec: %w", err)
+	}
+	if err := spec.Validate(context.Background()); err != nil {
+		return nil, fmt.Errorf("validate openapi spec: %w", err)
+	}
+	return spec, nil
+}
+
+// GetOpenAPISpec serves the specification as JSON.
+func GetOpenAPISpec(spec *openapi3.T) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.JSON(http.StatusOK, spec)
+	}
+}
+
+const docsInitializer = `window.onload = function() {
+  window.ui = SwaggerUIBundle({
+    url: "/api/openapi.json",
+    dom_id: "#swagger-ui",
+    deepLinking: true,
+    presets: [SwaggerUIBundle.presets.apis, SwaggerUIStandalonePreset],
+    layout: "StandaloneLayout"
+  });
+};`
+
+// Docs serves Swagger UI, bundled in the binary and pointed at /api/openapi.json, under /api/docs/.
+func Docs() gin.HandlerFunc {
+	fileServer := http.StripPrefix("/api/docs", http.FileServer(http.FS(swaggerfiles.FS)))
+
+	return func(c *gin.Context) {
+		filepath := c.Param("filepath")
+		switch filepath {
+		case "/swagger-initializer.js":
+			c.Data(http.StatusOK, "application/javascript", []byte(docsInitializer))
+		case "/":
+			fileServer.ServeHTTP(c.Writer, c.Request)
+		default:
+			if _, err := fs.Stat(swaggerfiles.FS, strings.TrimPrefix(filepath, "/")); err != nil {
+				c.Status(http.StatusNotFound)
+				return
+			}
+			fileServer.ServeHTTP(c.Writer, c.Request)
+		}
+	}
+}
+
+// ValidateRequest rejects requests not matching the parameters and body of their operation in the specification
+// with an invalid params error. Routes that are not in the specification are left alone.
+func ValidateRequest(spec *openapi3.T) gin.HandlerFunc {
+	options := &openapi3filter.Options{
+		SkipSettingDefaults: true,
+		AuthenticationFunc:  openapi3filter.NoopAuthenticationFunc,
+	}
+
+	return func(c *gin.Context) {
+		path := specPath(c.FullPath())
+		pathItem := spec.Paths.Value(path)
+		if pathItem == nil {
+			c.Next()
+			return
+		}
+		operation := pathItem.GetOperation(c.Request.Method)
+		if operation == nil {
+			c.Next()
+			return
+		}
+
+		pathParams := make(map[string]string, len(c.Params))
+		for _, param := range c.Params {
+			pathParams[param.Key] = param.Value
+		}
+
+		err := openapi3filter.ValidateRequest(c.Request.Context(), &openapi3filter.RequestValidationInput{
+			Request:    c.Request,
+			PathParams: pathParams,
+			Route: &routers.Route{
+				Spec:      spec,
+				Path:      path,
+				PathItem:  pathItem,
+				Method:    c.Request.Method,
+				Operation: operation,
+			},
+			Options: options,
+		})
+		if err != nil {
+			RespondFailure(c, invalidParams(err))
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// CheckRoutes reports the routes missing from the specification and the operations of the specification
+// without a route, so the specification can't drift from SetupRoute.
+func CheckRoutes(spec *openapi3.T, routes gin.RoutesInfo) error {
+	served := make(map[string]struct{}, len(routes))
+	for _, route := range routes {
+		served[route.Method+" "+specPath(route.Path)] = struct{}{}
+	}
+
+	var problems []string
+	for key := range served {
+		method, path, _ := strings.Cut(key, " ")
+		if pathItem := spec.Paths.Value(path); pathItem == nil || pathItem.GetOperation(method) == nil {
+			problems = append(problems, "route "+key+" is not in the spec")
+		}
+	}
+	for path, pathItem := range spec.Paths.Map() {
+		for method := range pathItem.Operations() {
+			if _, ok := served[method+" "+path]; !ok {
+				problems = append(problems, "operation "+method+" "+path+" has no route")
+			}
+		}
+	}
+
+	if len(problems) > 0 {
+		sort.Strings(problems)
+		return fmt.Errorf("openapi spec out of sync: %s", strings.Join(problems, ", "))
+	}
+	return nil
+}
+
+// specPath converts a gin path to an OpenAPI one: /txs/:hash -> /txs/{hash}.
+func specPath(path string) string {
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		if strings.HasPrefix(segment, ":") || strings.HasPrefix(segment, "*") {
+			segments[i] = "{" + segment[1:] + "}"
+		}
+	}
+	return strings.Join(segments, "/")
+}
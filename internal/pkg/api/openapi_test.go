@@ -0,0 +1,161 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/gin-gonic/gin"
+)
+
+func init() {
+	gin.SetMode(gin.TestMode)
+}
+
+func TestSpecMatchesRoutes(t *testing.T) {
+	engine := gin.New()
+	// routes registered beforehand, like the admin ones, are not checked against the spec
+	engine.GET("/metrics", func(*gin.Context) {})
+
+	if err := SetupRoute(engine, Config{Docs: true}, nil, nil, nil, nil); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestCheckRoutesReportsDrift(t *testing.T) {
+	spec, err := LoadSpec()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var routes gin.RoutesInfo
+	for path, pathItem := range spec.Paths.Map() {
+		for method := range pathItem.Operations() {
+			if method == http.MethodGet && path == "/api/subscriptions" {
+				continue
+			}
+			routes = append(routes, gin.RouteInfo{Method: method, Path: strings.NewReplacer("{", ":", "}", "").Replace(path)})
+		}
+	}
+	if err := CheckRoutes(spec, routes); err == nil || !strings.Contains(err.Error(), "operation GET /api/subscriptions has no route") {
+		t.Fatalf("got %v, want the missing route reported", err)
+	}
+
+	routes = append(routes,
+		gin.RouteInfo{Method: http.MethodGet, Path: "/api/subscriptions"},
+		gin.RouteInfo{Method: http.MethodPut, Path: "/api/subscribe/:address"},
+	)
+	if err := CheckRoutes(spec, routes); err == nil || !strings.Contains(err.Error(), "route PUT /api/subscribe/{address} is not in the spec") {
+		t.Fatalf("got %v, want the undocumented route reported", err)
+	}
+}
+
+func TestValidateRequestRejectsRequestsOffSpec(t *testing.T) {
+	spec, err := LoadSpec()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	engine := gin.New()
+	engine.POST("/api/subscribe", ValidateRequest(spec), func(c *gin.Context) {
+		c.Status(http.StatusNoContent)
+	})
+
+	for _, tc := range []struct {
+		body   string
+		status int
+	}{
+		{`{"address": "0x1f9090aaE28b8a3dCeaDf281B0F12828e676c326", "tags": ["exchange"]}`, http.StatusNoContent},
+		{`{"address": "0x1f9090aaE28b8a3dCeaDf281B0F12828e676c326", "tags": "exchange"}`, http.StatusBadRequest},
+		{`{"label": "no address"}`, http.StatusBadRequest},
+	} {
+		req := httptest.NewRequest(http.MethodPost, "/api/subscribe", strings.NewReader(tc.body))
+		req.Header.Set("Content-Type", "application/json")
+		rec := httptest.NewRecorder()
+		engine.ServeHTTP(rec, req)
+
+		if rec.Code != tc.status {
+			t.Errorf("%s: got %d, want %d: %s", tc.body, rec.Code, tc.status, rec.Body)
+		}
+	}
+}
+
+// jsonFields returns the JSON names of the fields of a struct, the ones of embedded structs included.
+func jsonFields(typ reflect.Type) map[string]struct{} {
+	fields := make(map[string]struct{})
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if field.Anonymous && name == "" {
+			for embedded := range jsonFields(field.Type) {
+				fields[embedded] = struct{}{}
+			}
+			continue
+		}
+		if !field.IsExported() || name == "-" {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		fields[name] = struct{}{}
+	}
+	return fields
+}
+
+// schemaProperties returns the properties of a schema, the ones of its allOf included.
+func schemaProperties(schema *openapi3.Schema) map[string]struct{} {
+	properties := make(map[string]struct{}, len(schema.Properties))
+	for name := range schema.Properties {
+		properties[name] = struct{}{}
+	}
+	for _, ref := range schema.AllOf {
+		for name := range schemaProperties(ref.Value) {
+			properties[name] = struct{}{}
+		}
+	}
+	return properties
+}
+
+func TestSpecSchemasMatchResponses(t *testing.T) {
+	spec, err := LoadSpec()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for schema, response := range map[string]interface{}{
+		"Subscription":     SubscriptionResponse{},
+		"SubscriptionPage": SubscriptionPageResponse{},
+		"ImportResult":     ImportResultResponse{},
+		"TxRecord":         TxRecordResponse{},
+		"TxPage":           TxPageResponse{},
+		"TxDetail":         TxDetailResponse{},
+		"Receipt":          ReceiptResponse{},
+		"Webhook":          WebhookResponse{},
+		"Delivery":         DeliveryResponse{},
+		"DeliveryPage":     DeliveryPageResponse{},
+		"Status":           StatusResponse{},
+	} {
+		ref, ok := spec.Components.Schemas[schema]
+		if !ok {
+			t.Errorf("schema %s is not in the spec", schema)
+			continue
+		}
+
+		properties := schemaProperties(ref.Value)
+		fields := jsonFields(reflect.TypeOf(response))
+		for field := range fields {
+			if _, ok := properties[field]; !ok {
+				t.Errorf("%s: field %s is not in the spec", schema, field)
+			}
+		}
+		for property := range properties {
+			if _, ok := fields[property]; !ok {
+				t.Errorf("%s: property %s is not returned", schema, property)
+			}
+		}
+	}
+}
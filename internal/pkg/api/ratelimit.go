@@ -0,0 +1,44 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/vuquang23/trustme/internal/pkg/auth"
+	"github.com/vuquang23/trustme/internal/pkg/entity"
+)
+
+// RateLimit refuses the requests of a client over its rate limit with a Retry-After header. Clients are
+// told apart by their API key, or their IP when not authenticated, so it runs after Authenticate.
+func RateLimit(limiter IRateLimiter) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.Request.Method == http.MethodOptions {
+			c.Next()
+			return
+		}
+
+		ok, retryAfter := limiter.Allow(RateLimitClient(c.Request.Context(), c.ClientIP()), c.Request.URL.Path)
+		if !ok {
+			seconds := int(math.Ceil(retryAfter.Seconds()))
+			c.Header("Retry-After", strconv.Itoa(seconds))
+			RespondFailure(c, fmt.Errorf("%w: retry in %ds", entity.ErrRateLimited, seconds))
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// RateLimitClient identifies the client of a request for rate limiting: its API key, JWT subject or IP.
+func RateLimitClient(ctx context.Context, ip string) string {
+	if principal := auth.PrincipalFromContext(ctx); principal != nil && principal.KeyID != "" {
+		return "key:" + principal.KeyID
+	}
+	return "ip:" + ip
+}
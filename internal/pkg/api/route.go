@@ -1,41 +1,241 @@
 package api
 
 import (
+	"fmt"
 	"strings"
 
 	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
 
+	"github.com/vuquang23/trustme/internal/pkg/entity"
+	"github.com/vuquang23/trustme/internal/pkg/importer"
 	"github.com/vuquang23/trustme/pkg/logger"
 )
 
-func SetupRoute(engine *gin.Engine, parser IParser) {
+// SetupRoute registers the API routes, failing if they are out of sync with the OpenAPI specification.
+func SetupRoute(engine *gin.Engine, cfg Config, tenants ITenants, bus IEventBus, webhooks IWebhooks, health IHealth) error {
+	spec, err := LoadSpec()
+	if err != nil {
+		return err
+	}
+
+	existing := make(map[string]struct{})
+	for _, route := range engine.Routes() {
+		existing[route.Method+" "+route.Path] = struct{}{}
+	}
+
+	engine.GET("/healthz", Healthz())
+	engine.GET("/readyz", Readyz(health))
+
 	rg := engine.Group("/api")
+	if cfg.ValidateRequests {
+		rg.Use(ValidateRequest(spec))
+	}
+
+	rg.GET("/current-block", GetCurrentBlock(tenants))
+	rg.GET("/status", GetStatus(health))
+	rg.POST("/subscribe", SubscribeAddress(cfg, tenants))
+	rg.POST("/subscribe/batch", SubscribeAddresses(cfg, tenants))
+	rg.DELETE("/subscribe/:address", UnsubscribeAddress(cfg, tenants))
+	rg.GET("/subscriptions", GetSubscriptions(tenants))
+	rg.GET("/txs", GetTransactions(cfg, tenants))
+	rg.GET("/txs/:hash", GetTxDetail(tenants))
+	rg.GET("/stream", Stream(cfg, tenants, bus))
+	rg.GET("/ws", WebSocket(cfg, tenants, bus))
+	rg.POST("/webhooks", CreateWebhook(cfg, tenants, webhooks))
+	rg.GET("/webhooks", GetWebhooks(cfg, tenants, webhooks))
+	rg.DELETE("/webhooks/:id", DeleteWebhook(tenants, webhooks))
+	rg.GET("/webhook-deliveries", GetDeliveries(tenants, webhooks))
+	rg.GET("/webhook-deliveries/:id", GetDelivery(tenants, webhooks))
+	rg.POST("/webhook-deliveries/:id/replay", ReplayDelivery(tenants, webhooks))
+
+	v2 := engine.Group("/api/v2")
+
+	if cfg.ValidateRequests {
+		v2.Use(ValidateRequest(spec))
+	}
+
+	v2.GET("/txs", GetTxRecords(cfg, tenants))
+
+	rg.GET("/openapi.json", GetOpenAPISpec(spec))
+
+	var routes gin.RoutesInfo
+	for _, route := range engine.Routes() {
+		if _, ok := existing[route.Method+" "+route.Path]; !ok {
+			routes = append(routes, route)
+		}
+	}
+	if err := CheckRoutes(spec, routes); err != nil {
+		return err
+	}
+
+	if cfg.Docs {
+		engine.GET("/api/docs/*filepath", Docs())
+	}
 
-	rg.GET("/current-block", GetCurrentBlock(parser))
-	rg.POST("/subscribe", SubscribeAddress(parser))
-	rg.GET("/txs", GetTransactions(parser))
+	return nil
 }
 
-func GetCurrentBlock(parser IParser) gin.HandlerFunc {
+func GetCurrentBlock(tenants ITenants) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		RespondSuccess(c, parser.GetCurrentBlock())
+		RespondSuccess(c, tenants.Parser(c.Request.Context()).GetCurrentBlock())
 	}
 }
 
 type SubscribeAddressParams struct {
-	Address string `json:"address"`
+	Address string   `json:"address"`
+	Label   string   `json:"label"`
+	Tags    []string `json:"tags"`
+	Owner   string   `json:"owner"`
+	Notes   string   `json:"notes"`
 }
 
-func SubscribeAddress(parser IParser) gin.HandlerFunc {
+func SubscribeAddress(cfg Config, tenants ITenants) gin.HandlerFunc {
 	return func(c *gin.Context) {
+		parser := tenants.Parser(c.Request.Context())
+
 		var params SubscribeAddressParams
 		if err := c.ShouldBindJSON(&params); err != nil {
 			logger.Error(c, err.Error())
+			RespondFailure(c, invalidParams(err))
+			return
+		}
+
+		address, err := entity.ParseAddressKey(params.Address, cfg.EnforceChecksum)
+		if err != nil {
+			RespondFailure(c, err)
+			return
+		}
+
+		subscription := &entity.Subscription{
+			Address: address,
+			Label:   params.Label,
+			Tags:    params.Tags,
+			Owner:   params.Owner,
+			Notes:   params.Notes,
+		}
+		if err := parser.AddSubscription(subscription); err != nil {
 			RespondFailure(c, err)
 			return
 		}
 
-		RespondSuccess(c, parser.Subscribe(strings.ToLower(params.Address)))
+		RespondSuccess(c, NewSubscriptionResponse(subscription))
+	}
+}
+
+// SubscribeAddresses subscribes a batch of addresses given as a JSON array, CSV or NDJSON body,
+// or as a "file" field of a multipart form. Each address gets its own result.
+func SubscribeAddresses(cfg Config, tenants ITenants) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		parser := tenants.Parser(c.Request.Context())
+
+		items, err := decodeImportItems(c)
+		if err != nil {
+			logger.Error(c, err.Error())
+			RespondFailure(c, invalidParams(err))
+			return
+		}
+
+		if cfg.MaxBatchSize > 0 && len(items) > cfg.MaxBatchSize {
+			RespondFailure(c, invalidParams(fmt.Errorf("batch has %d items, max is %d", len(items), cfg.MaxBatchSize)))
+			return
+		}
+
+		RespondSuccess(c, newImportResultResponse(importer.Import(items, cfg.EnforceChecksum, parser)))
+	}
+}
+
+func decodeImportItems(c *gin.Context) ([]importer.Item, error) {
+	switch c.ContentType() {
+	case binding.MIMEMultipartPOSTForm:
+		fileHeader, err := c.FormFile("file")
+		if err != nil {
+			return nil, err
+		}
+
+		format := importer.Format(c.PostForm("format"))
+		if format == "" {
+			format = importer.FormatFromFilename(fileHeader.Filename)
+		}
+
+		f, err := fileHeader.Open()
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+
+		return importer.Decode(f, format)
+
+	case "text/csv":
+		return importer.Decode(c.Request.Body, importer.FormatCSV)
+
+	case "application/x-ndjson":
+		return importer.Decode(c.Request.Body, importer.FormatNDJSON)
+
+	default:
+		return importer.Decode(c.Request.Body, importer.FormatJSON)
+	}
+}
+
+type UnsubscribeAddressParams struct {
+	Address string `uri:"address"`
+}
+
+func UnsubscribeAddress(cfg Config, tenants ITenants) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		parser := tenants.Parser(c.Request.Context())
+
+		var params UnsubscribeAddressParams
+		if err := c.ShouldBindUri(&params); err != nil {
+			logger.Error(c, err.Error())
+			RespondFailure(c, invalidParams(err))
+			return
+		}
+
+		address, err := entity.ParseAddressKey(params.Address, cfg.EnforceChecksum)
+		if err != nil {
+			RespondFailure(c, err)
+			return
+		}
+
+		if err := parser.Unsubscribe(address); err != nil {
+			RespondFailure(c, err)
+			return
+		}
+
+		RespondSuccess(c, true)
+	}
+}
+
+const defaultSubscriptionPageLimit = 100
+
+type GetSubscriptionsParams struct {
+	Cursor string `form:"cursor"`
+	Limit  int    `form:"limit" binding:"omitempty,min=1,max=1000"`
+}
+
+func GetSubscriptions(tenants ITenants) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		parser := tenants.Parser(c.Request.Context())
+
+		var params GetSubscriptionsParams
+		if err := c.ShouldBindQuery(&params); err != nil {
+			logger.Error(c, err.Error())
+			RespondFailure(c, invalidParams(err))
+			return
+		}
+
+		if params.Limit == 0 {
+			params.Limit = defaultSubscriptionPageLimit
+		}
+
+		page, err := parser.GetSubscriptions(strings.ToLower(params.Cursor), params.Limit)
+		if err != nil {
+			RespondFailure(c, err)
+			return
+		}
+
+		RespondSuccess(c, NewSubscriptionPageResponse(page))
 	}
 }
 
@@ -43,15 +243,133 @@ type GetTransactionsParams struct {
 	Address string `form:"address"`
 }
 
-func GetTransactions(parser IParser) gin.HandlerFunc {
+func GetTransactions(cfg Config, tenants ITenants) gin.HandlerFunc {
 	return func(c *gin.Context) {
+		parser := tenants.Parser(c.Request.Context())
+
 		var params GetTransactionsParams
 		if err := c.ShouldBindQuery(&params); err != nil {
 			logger.Error(c, err.Error())
+			RespondFailure(c, invalidParams(err))
+			return
+		}
+
+		address, err := entity.ParseAddressKey(params.Address, cfg.EnforceChecksum)
+		if err != nil {
+			RespondFailure(c, err)
+			return
+		}
+
+		// the whole history in one response, superseded by the pages of /api/v2/txs
+		c.Header("Deprecation", "true")
+		c.Header("Link", `</api/v2/txs>; rel="successor-version"`)
+		RespondSuccess(c, NewRawTransactionResponses(parser.GetTransactions(address)))
+	}
+}
+
+type GetTxDetailParams struct {
+	Hash string `uri:"hash"`
+}
+
+func GetTxDetail(tenants ITenants) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		parser := tenants.Parser(c.Request.Context())
+
+		var params GetTxDetailParams
+		if err := c.ShouldBindUri(&params); err != nil {
+			logger.Error(c, err.Error())
+			RespondFailure(c, invalidParams(err))
+			return
+		}
+
+		hash, err := parseTxHash(params.Hash)
+		if err != nil {
+			RespondFailure(c, err)
+			return
+		}
+
+		detail, err := parser.GetTxDetail(c.Request.Context(), hash)
+		if err != nil {
+			RespondFailure(c, err)
+			return
+		}
+
+		RespondSuccess(c, NewTxDetailResponse(detail))
+	}
+}
+
+const defaultTxPageLimit = 100
+
+type GetTxRecordsParams struct {
+	Address      string  `form:"address" json:"address"`
+	FromBlock    *uint64 `form:"fromBlock" json:"fromBlock"`
+	ToBlock      *uint64 `form:"toBlock" json:"toBlock"`
+	FromTime     *uint64 `form:"fromTime" json:"fromTime"`
+	ToTime       *uint64 `form:"toTime" json:"toTime"`
+	Direction    string  `form:"direction" json:"direction" binding:"omitempty,oneof=in out self"`
+	MinValue     string  `form:"minValue" json:"minValue"`
+	MaxValue     string  `form:"maxValue" json:"maxValue"`
+	Status       string  `form:"status" json:"status" binding:"omitempty,oneof=success failed"`
+	Counterparty string  `form:"counterparty" json:"counterparty"`
+	Type         *uint8  `form:"type" json:"type"`
+	Order        string  `form:"order" json:"order" binding:"omitempty,oneof=asc desc"`
+	Cursor       string  `form:"cursor" json:"cursor"`
+	Limit        int     `form:"limit" json:"limit" binding:"omitempty,min=1,max=1000"`
+}
+
+// ToQuery converts the validated params, the address aside, to a repository query.
+func (p GetTxRecordsParams) ToQuery(enforceChecksum bool) (entity.TxQuery, error) {
+	limit := p.Limit
+	if limit == 0 {
+		limit = defaultTxPageLimit
+	}
+
+	return entity.ParseTxQuery(entity.TxQueryParams{
+		FromBlock:    p.FromBlock,
+		ToBlock:      p.ToBlock,
+		FromTime:     p.FromTime,
+		ToTime:       p.ToTime,
+		Direction:    p.Direction,
+		MinValue:     p.MinValue,
+		MaxValue:     p.MaxValue,
+		Status:       p.Status,
+		Counterparty: p.Counterparty,
+		TxType:       p.Type,
+		Order:        entity.SortOrder(p.Order),
+		Cursor:       p.Cursor,
+		Limit:        limit,
+	}, enforceChecksum)
+}
+
+func GetTxRecords(cfg Config, tenants ITenants) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		parser := tenants.Parser(c.Request.Context())
+
+		var params GetTxRecordsParams
+		if err := c.ShouldBindQuery(&params); err != nil {
+			logger.Error(c, err.Error())
+			RespondFailure(c, invalidParams(err))
+			return
+		}
+
+		address, err := entity.ParseAddressKey(params.Address, cfg.EnforceChecksum)
+		if err != nil {
+			RespondFailure(c, err)
+			return
+		}
+
+		query, err := params.ToQuery(cfg.EnforceChecksum)
+		if err != nil {
+			RespondFailure(c, err)
+			return
+		}
+
+		page, err := parser.QueryTxRecords(address, query)
+		if err != nil {
 			RespondFailure(c, err)
 			return
 		}
 
-		RespondSuccess(c, parser.GetTransactions(strings.ToLower(params.Address)))
+		RespondSuccess(c, NewTxPageResponse(page))
 	}
 }
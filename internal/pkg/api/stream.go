@@ -0,0 +1,220 @@
+package api
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/gin-contrib/sse"
+	"github.com/gin-gonic/gin"
+
+	"github.com/vuquang23/trustme/internal/pkg/entity"
+	"github.com/vuquang23/trustme/pkg/logger"
+)
+
+const replayPageLimit = 1000
+
+// streamPosition orders stream events by (block number, tx index, address).
+// It is sent as the SSE event id, so a client reconnecting with Last-Event-ID resumes right after it.
+type streamPosition struct {
+	blockNumber uint64
+	txIndex     uint
+	address     string
+}
+
+func positionOf(record *entity.TxRecord) streamPosition {
+	return streamPosition{
+		blockNumber: record.BlockNumber,
+		txIndex:     record.TxIndex,
+		address:     record.Address,
+	}
+}
+
+func (p streamPosition) after(other streamPosition) bool {
+	if p.blockNumber != other.blockNumber {
+		return p.blockNumber > other.blockNumber
+	}
+	if p.txIndex != other.txIndex {
+		return p.txIndex > other.txIndex
+	}
+	return p.address > other.address
+}
+
+func (p streamPosition) String() string {
+	return entity.EncodeTxCursor(p.blockNumber, p.txIndex) + "." + p.address
+}
+
+// streamKey identifies the event of a transaction for one of its addresses.
+type streamKey struct {
+	hash    common.Hash
+	address string
+}
+
+func keyOf(record *entity.TxRecord) streamKey {
+	return streamKey{hash: record.Hash(), address: record.Address}
+}
+
+func parseStreamPosition(s string) (streamPosition, error) {
+	cursor, address, ok := strings.Cut(s, ".")
+	if !ok {
+		return streamPosition{}, entity.ErrInvalidCursor
+	}
+
+	blockNumber, txIndex, err := entity.DecodeTxCursor(cursor)
+	if err != nil {
+		return streamPosition{}, err
+	}
+
+	return streamPosition{blockNumber: blockNumber, txIndex: txIndex, address: address}, nil
+}
+
+type StreamParams struct {
+	Addresses []string `form:"address"`
+}
+
+// Stream pushes a "tx" server-sent event whenever the parser saves a transaction of one of the addresses.
+// With a Last-Event-ID header, the transactions saved after that event are replayed from the repository first.
+func Stream(cfg Config, tenants ITenants, bus IEventBus) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		parser := tenants.Parser(c.Request.Context())
+
+		var params StreamParams
+		if err := c.ShouldBindQuery(&params); err != nil {
+			logger.Error(c, err.Error())
+			RespondFailure(c, invalidParams(err))
+			return
+		}
+
+		if len(params.Addresses) == 0 || len(params.Addresses) > cfg.MaxStreamAddresses {
+			RespondFailure(c, invalidParams(fmt.Errorf("between 1 and %d addresses are required", cfg.MaxStreamAddresses)))
+			return
+		}
+
+		addresses := make(map[string]struct{}, len(params.Addresses))
+		for _, s := range params.Addresses {
+			address, err := entity.ParseAddressKey(s, cfg.EnforceChecksum)
+			if err != nil {
+				RespondFailure(c, err)
+				return
+			}
+			if !parser.Allows(address) {
+				RespondFailure(c, entity.ErrNotSubscribed)
+				return
+			}
+			addresses[address] = struct{}{}
+		}
+
+		var last *streamPosition
+		if lastEventID := c.GetHeader("Last-Event-ID"); lastEventID != "" {
+			position, err := parseStreamPosition(lastEventID)
+			if err != nil {
+				RespondFailure(c, err)
+				return
+			}
+			last = &position
+		}
+
+		// Subscribe before replaying so nothing saved in between is missed, the events already replayed are skipped.
+		// They are told apart by transaction and address: the events of a transaction are not published in stream
+		// order, the one of its sender comes first.
+		sub := bus.Subscribe(cfg.StreamBufferSize, func(event entity.Event) bool {
+			_, ok := addresses[event.Address]
+			return ok && event.Type == entity.EventTypeTx
+		})
+		defer sub.Close()
+
+		var replay []*entity.TxRecord
+		if last != nil {
+			var err error
+			replay, err = replayRecords(parser, addresses, *last)
+			if err != nil {
+				RespondFailure(c, err)
+				return
+			}
+		}
+
+		c.Header("Content-Type", sse.ContentType)
+		c.Header("Cache-Control", "no-cache")
+		c.Header("Connection", "keep-alive")
+		c.Header("X-Accel-Buffering", "no")
+		c.Status(200)
+		c.Writer.Flush()
+
+		send := func(record *entity.TxRecord) {
+			c.Render(-1, sse.Event{
+				Event: string(entity.EventTypeTx),
+				Id:    positionOf(record).String(),
+				Data:  NewTxRecordResponse(record),
+			})
+			c.Writer.Flush()
+		}
+
+		replayed := make(map[streamKey]struct{}, len(replay))
+		for _, record := range replay {
+			replayed[keyOf(record)] = struct{}{}
+			send(record)
+		}
+
+		heartbeat := time.NewTicker(cfg.StreamHeartbeat)
+		defer heartbeat.Stop()
+
+		for {
+			select {
+			case <-c.Request.Context().Done():
+				return
+
+			case event, ok := <-sub.C():
+				if !ok {
+					// Dropped for being too slow, the client reconnects with Last-Event-ID.
+					return
+				}
+				if _, ok := replayed[keyOf(event.Record)]; ok {
+					continue
+				}
+				send(event.Record)
+
+			case <-heartbeat.C:
+				_, _ = c.Writer.WriteString(": keepalive\n\n")
+				c.Writer.Flush()
+			}
+		}
+	}
+}
+
+// replayRecords returns the records of the addresses saved after the given position, in stream order.
+func replayRecords(parser IParser, addresses map[string]struct{}, last streamPosition) ([]*entity.TxRecord, error) {
+	var records []*entity.TxRecord
+	for address := range addresses {
+		query := entity.TxQuery{
+			FromBlock: &last.blockNumber,
+			Order:     entity.SortOrderAsc,
+			Limit:     replayPageLimit,
+		}
+
+		for {
+			page, err := parser.QueryTxRecords(address, query)
+			if err != nil {
+				return nil, err
+			}
+
+			for _, record := range page.Records {
+				if positionOf(record).after(last) {
+					records = append(records, record)
+				}
+			}
+
+			if page.NextCursor == "" {
+				break
+			}
+			query.Cursor = page.NextCursor
+		}
+	}
+
+	sort.Slice(records, func(i, j int) bool {
+		return positionOf(records[j]).after(positionOf(records[i]))
+	})
+
+	return records, nil
+}
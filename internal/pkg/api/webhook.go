@@ -0,0 +1,242 @@
+package api
+
+import (
+	"fmt"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/vuquang23/trustme/internal/pkg/entity"
+	"github.com/vuquang23/trustme/pkg/logger"
+)
+
+type CreateWebhookParams struct {
+	Address string   `json:"address"`
+	URL     string   `json:"url"`
+	Secret  string   `json:"secret"`
+	Events  []string `json:"events"`
+}
+
+// CreateWebhook registers a webhook for a subscribed address. The response is the only one carrying the secret.
+func CreateWebhook(cfg Config, tenants ITenants, webhooks IWebhooks) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		parser := tenants.Parser(c.Request.Context())
+
+		var params CreateWebhookParams
+		if err := c.ShouldBindJSON(&params); err != nil {
+			logger.Error(c, err.Error())
+			RespondFailure(c, invalidParams(err))
+			return
+		}
+
+		address, err := entity.ParseAddressKey(params.Address, cfg.EnforceChecksum)
+		if err != nil {
+			RespondFailure(c, err)
+			return
+		}
+		if !parser.Allows(address) {
+			RespondFailure(c, entity.ErrNotSubscribed)
+			return
+		}
+
+		events := make([]entity.EventType, 0, len(params.Events))
+		for _, e := range params.Events {
+			event := entity.EventType(e)
+			if !entity.IsEventType(event) {
+				RespondFailure(c, invalidParams(fmt.Errorf("unknown event: %s", e)))
+				return
+			}
+			events = append(events, event)
+		}
+
+		webhook := &entity.Webhook{
+			Address: address,
+			URL:     params.URL,
+			Secret:  params.Secret,
+			Events:  events,
+			Tenant:  parser.Tenant(),
+		}
+		if err := webhooks.CreateWebhook(webhook); err != nil {
+			RespondFailure(c, err)
+			return
+		}
+
+		RespondSuccess(c, newWebhookResponse(webhook, true))
+	}
+}
+
+type GetWebhooksParams struct {
+	Address string `form:"address"`
+}
+
+func GetWebhooks(cfg Config, tenants ITenants, webhooks IWebhooks) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		tenant := tenants.Parser(c.Request.Context()).Tenant()
+
+		var params GetWebhooksParams
+		if err := c.ShouldBindQuery(&params); err != nil {
+			logger.Error(c, err.Error())
+			RespondFailure(c, invalidParams(err))
+			return
+		}
+
+		var address string
+		if params.Address != "" {
+			var err error
+			if address, err = entity.ParseAddressKey(params.Address, cfg.EnforceChecksum); err != nil {
+				RespondFailure(c, err)
+				return
+			}
+		}
+
+		list, err := webhooks.ListWebhooks(address)
+		if err != nil {
+			RespondFailure(c, err)
+			return
+		}
+
+		items := make([]WebhookResponse, 0, len(list))
+		for _, webhook := range list {
+			if tenant == "" || webhook.Tenant == tenant {
+				items = append(items, newWebhookResponse(webhook, false))
+			}
+		}
+		RespondSuccess(c, items)
+	}
+}
+
+type WebhookIDParams struct {
+	ID string `uri:"id" binding:"required"`
+}
+
+func DeleteWebhook(tenants ITenants, webhooks IWebhooks) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		tenant := tenants.Parser(c.Request.Context()).Tenant()
+
+		var params WebhookIDParams
+		if err := c.ShouldBindUri(&params); err != nil {
+			logger.Error(c, err.Error())
+			RespondFailure(c, invalidParams(err))
+			return
+		}
+
+		webhook, err := webhooks.GetWebhook(params.ID)
+		if err != nil {
+			RespondFailure(c, err)
+			return
+		}
+		if tenant != "" && webhook.Tenant != tenant {
+			RespondFailure(c, entity.ErrWebhookNotFound)
+			return
+		}
+
+		if err := webhooks.DeleteWebhook(params.ID); err != nil {
+			RespondFailure(c, err)
+			return
+		}
+
+		RespondSuccess(c, true)
+	}
+}
+
+const defaultDeliveryPageLimit = 100
+
+type GetDeliveriesParams struct {
+	WebhookID string `form:"webhookId"`
+	Status    string `form:"status" binding:"omitempty,oneof=pending delivered dead"`
+	Cursor    string `form:"cursor"`
+	Limit     int    `form:"limit" binding:"omitempty,min=1,max=1000"`
+}
+
+// GetDeliveries lists webhook deliveries newest first, status=dead being the dead-letter list.
+func GetDeliveries(tenants ITenants, webhooks IWebhooks) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		tenant := tenants.Parser(c.Request.Context()).Tenant()
+
+		var params GetDeliveriesParams
+		if err := c.ShouldBindQuery(&params); err != nil {
+			logger.Error(c, err.Error())
+			RespondFailure(c, invalidParams(err))
+			return
+		}
+
+		if params.Limit == 0 {
+			params.Limit = defaultDeliveryPageLimit
+		}
+
+		page, err := webhooks.ListDeliveries(entity.DeliveryQuery{
+			WebhookID: params.WebhookID,
+			Status:    entity.DeliveryStatus(params.Status),
+			Tenant:    tenant,
+			Cursor:    params.Cursor,
+			Limit:     params.Limit,
+		})
+		if err != nil {
+			RespondFailure(c, err)
+			return
+		}
+
+		RespondSuccess(c, newDeliveryPageResponse(page))
+	}
+}
+
+type DeliveryIDParams struct {
+	ID string `uri:"id" binding:"required"`
+}
+
+func GetDelivery(tenants ITenants, webhooks IWebhooks) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		tenant := tenants.Parser(c.Request.Context()).Tenant()
+
+		var params DeliveryIDParams
+		if err := c.ShouldBindUri(&params); err != nil {
+			logger.Error(c, err.Error())
+			RespondFailure(c, invalidParams(err))
+			return
+		}
+
+		delivery, err := webhooks.GetDelivery(params.ID)
+		if err != nil {
+			RespondFailure(c, err)
+			return
+		}
+		if tenant != "" && delivery.Tenant != tenant {
+			RespondFailure(c, entity.ErrDeliveryNotFound)
+			return
+		}
+
+		RespondSuccess(c, newDeliveryResponse(delivery))
+	}
+}
+
+func ReplayDelivery(tenants ITenants, webhooks IWebhooks) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		tenant := tenants.Parser(c.Request.Context()).Tenant()
+
+		var params DeliveryIDParams
+		if err := c.ShouldBindUri(&params); err != nil {
+			logger.Error(c, err.Error())
+			RespondFailure(c, invalidParams(err))
+			return
+		}
+
+		if tenant != "" {
+			delivery, err := webhooks.GetDelivery(params.ID)
+			if err != nil {
+				RespondFailure(c, err)
+				return
+			}
+			if delivery.Tenant != tenant {
+				RespondFailure(c, entity.ErrDeliveryNotFound)
+				return
+			}
+		}
+
+		delivery, err := webhooks.Replay(params.ID)
+		if err != nil {
+			RespondFailure(c, err)
+			return
+		}
+
+		RespondSuccess(c, newDeliveryResponse(delivery))
+	}
+}
@@ -0,0 +1,301 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+
+	"github.com/vuquang23/trustme/internal/pkg/entity"
+	"github.com/vuquang23/trustme/internal/pkg/tenant"
+	"github.com/vuquang23/trustme/pkg/logger"
+)
+
+const (
+	wsOpSubscribe   = "subscribe"
+	wsOpUnsubscribe = "unsubscribe"
+	wsOpPing        = "ping"
+
+	wsTypeAck   = "ack"
+	wsTypeError = "error"
+	wsTypeEvent = "event"
+	wsTypePong  = "pong"
+
+	wsWriteTimeout = 10 * time.Second
+	wsReadLimit    = 64 * 1024
+)
+
+// WSRequest is a message sent by a WebSocket client.
+// Events defaults to every event type when empty.
+type WSRequest struct {
+	ID        string   `json:"id,omitempty"`
+	Op        string   `json:"op"`
+	Addresses []string `json:"addresses"`
+	Events    []string `json:"events"`
+}
+
+// WSMessage is a message sent to a WebSocket client: an ack or error answering a request, a pong, or an event.
+type WSMessage struct {
+	ID      string         `json:"id,omitempty"`
+	Type    string         `json:"type"`
+	Event   string         `json:"event,omitempty"`
+	Address string         `json:"address,omitempty"`
+	Data    interface{}    `json:"data,omitempty"`
+	Error   *ErrorResponse `json:"error,omitempty"`
+}
+
+// WebSocket upgrades the connection and lets the client subscribe and unsubscribe to the events of addresses.
+// Every connection has its own bounded event queue, a client too slow to drain it is disconnected.
+func WebSocket(cfg Config, tenants ITenants, bus IEventBus) gin.HandlerFunc {
+	upgrader := websocket.Upgrader{
+		// The CORS middleware refuses the upgrades from origins other than the Http.AllowOrigins ones, none by default.
+		CheckOrigin: func(*http.Request) bool { return true },
+	}
+
+	return func(c *gin.Context) {
+		conn, err := upgrader.Upgrade(c.Writer, c.Request, nil)
+		if err != nil {
+			logger.Warnf(c, "websocket upgrade failed: %s", err)
+			return
+		}
+		defer conn.Close()
+
+		session := &wsSession{
+			cfg:           cfg,
+			parser:        tenants.Parser(c.Request.Context()),
+			conn:          conn,
+			subscriptions: make(map[string]map[entity.EventType]struct{}),
+			replies:       make(chan WSMessage, 16),
+			done:          make(chan struct{}),
+			stopped:       make(chan struct{}),
+		}
+
+		sub := bus.Subscribe(cfg.WSBufferSize, session.accepts)
+		defer sub.Close()
+
+		go session.writeLoop(c, sub.C())
+		session.readLoop(c)
+	}
+}
+
+type wsSession struct {
+	cfg    Config
+	parser *tenant.Parser
+	conn   *websocket.Conn
+
+	mu            sync.RWMutex
+	subscriptions map[string]map[entity.EventType]struct{}
+
+	replies chan WSMessage
+	// done is closed when the read loop exits, stopped when the write loop does.
+	done    chan struct{}
+	stopped chan struct{}
+}
+
+func (s *wsSession) accepts(event entity.Event) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	_, ok := s.subscriptions[event.Address][event.Type]
+	return ok
+}
+
+func (s *wsSession) readLoop(c *gin.Context) {
+	defer close(s.done)
+
+	s.conn.SetReadLimit(wsReadLimit)
+	_ = s.conn.SetReadDeadline(time.Now().Add(2 * s.cfg.WSPingInterval))
+	s.conn.SetPongHandler(func(string) error {
+		return s.conn.SetReadDeadline(time.Now().Add(2 * s.cfg.WSPingInterval))
+	})
+
+	for {
+		var req WSRequest
+		if err := s.conn.ReadJSON(&req); err != nil {
+			if websocket.IsUnexpectedCloseError(err, websocket.CloseNormalClosure, websocket.CloseGoingAway) {
+				logger.Warnf(c, "websocket read failed: %s", err)
+			}
+			return
+		}
+
+		select {
+		case s.replies <- s.handle(req):
+		case <-s.stopped:
+			return
+		}
+	}
+}
+
+func (s *wsSession) handle(req WSRequest) WSMessage {
+	var err error
+	switch req.Op {
+	case wsOpSubscribe:
+		err = s.subscribe(req)
+	case wsOpUnsubscribe:
+		err = s.unsubscribe(req)
+	case wsOpPing:
+		return WSMessage{ID: req.ID, Type: wsTypePong}
+	default:
+		err = invalidParams(fmt.Errorf("unknown op: %s", req.Op))
+	}
+
+	if err != nil {
+		response := responseFromErr(err)
+		if response.HTTPStatus < http.StatusInternalServerError && response.Message != err.Error() {
+			response.Details = []interface{}{err.Error()}
+		}
+		return WSMessage{ID: req.ID, Type: wsTypeError, Error: &response}
+	}
+
+	return WSMessage{ID: req.ID, Type: wsTypeAck, Data: s.snapshot()}
+}
+
+func (s *wsSession) parseRequest(req WSRequest) ([]string, []entity.EventType, error) {
+	addresses := make([]string, 0, len(req.Addresses))
+	for _, a := range req.Addresses {
+		address, err := entity.ParseAddressKey(a, s.cfg.EnforceChecksum)
+		if err != nil {
+			return nil, nil, err
+		}
+		addresses = append(addresses, address)
+	}
+
+	if len(req.Events) == 0 {
+		return addresses, entity.EventTypes, nil
+	}
+
+	events := make([]entity.EventType, 0, len(req.Events))
+	for _, e := range req.Events {
+		event := entity.EventType(e)
+		if !entity.IsEventType(event) {
+			return nil, nil, invalidParams(fmt.Errorf("unknown event: %s", e))
+		}
+		events = append(events, event)
+	}
+	return addresses, events, nil
+}
+
+func (s *wsSession) subscribe(req WSRequest) error {
+	addresses, events, err := s.parseRequest(req)
+	if err != nil {
+		return err
+	}
+	for _, address := range addresses {
+		if !s.parser.Allows(address) {
+			return fmt.Errorf("%w: %s", entity.ErrNotSubscribed, address)
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	added := 0
+	for _, address := range addresses {
+		if _, ok := s.subscriptions[address]; !ok {
+			added++
+		}
+	}
+	if len(s.subscriptions)+added > s.cfg.WSMaxSubscriptions {
+		return invalidParams(fmt.Errorf("at most %d addresses per connection", s.cfg.WSMaxSubscriptions))
+	}
+
+	for _, address := range addresses {
+		if _, ok := s.subscriptions[address]; !ok {
+			s.subscriptions[address] = make(map[entity.EventType]struct{})
+		}
+		for _, event := range events {
+			s.subscriptions[address][event] = struct{}{}
+		}
+	}
+	return nil
+}
+
+func (s *wsSession) unsubscribe(req WSRequest) error {
+	addresses, events, err := s.parseRequest(req)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, address := range addresses {
+		for _, event := range events {
+			delete(s.subscriptions[address], event)
+		}
+		if len(s.subscriptions[address]) == 0 {
+			delete(s.subscriptions, address)
+		}
+	}
+	return nil
+}
+
+// snapshot lists the current subscriptions of the connection.
+func (s *wsSession) snapshot() map[string][]entity.EventType {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	snapshot := make(map[string][]entity.EventType, len(s.subscriptions))
+	for address, events := range s.subscriptions {
+		checksummed := common.HexToAddress(address).Hex()
+		for _, event := range entity.EventTypes {
+			if _, ok := events[event]; ok {
+				snapshot[checksummed] = append(snapshot[checksummed], event)
+			}
+		}
+	}
+	return snapshot
+}
+
+// writeLoop is the only writer of the connection: replies, events and pings.
+func (s *wsSession) writeLoop(c *gin.Context, events <-chan entity.Event) {
+	ping := time.NewTicker(s.cfg.WSPingInterval)
+	defer ping.Stop()
+	defer close(s.stopped)
+	defer s.conn.Close()
+
+	for {
+		var err error
+		select {
+		case <-s.done:
+			return
+
+		case reply := <-s.replies:
+			err = s.write(reply)
+
+		case event, ok := <-events:
+			if !ok {
+				logger.Warn(c, "websocket client too slow, disconnecting")
+				_ = s.conn.WriteControl(
+					websocket.CloseMessage,
+					websocket.FormatCloseMessage(websocket.CloseTryAgainLater, "slow consumer"),
+					time.Now().Add(wsWriteTimeout),
+				)
+				return
+			}
+			err = s.write(WSMessage{
+				Type:    wsTypeEvent,
+				Event:   string(event.Type),
+				Address: common.HexToAddress(event.Address).Hex(),
+				Data:    EventData(event),
+			})
+
+		case <-ping.C:
+			err = s.conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(wsWriteTimeout))
+		}
+
+		if err != nil {
+			logger.Warnf(c, "websocket write failed: %s", err)
+			return
+		}
+	}
+}
+
+func (s *wsSession) write(message WSMessage) error {
+	_ = s.conn.SetWriteDeadline(time.Now().Add(wsWriteTimeout))
+	return s.conn.WriteJSON(message)
+}
@@ -0,0 +1,217 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+
+	"github.com/vuquang23/trustme/internal/pkg/entity"
+	"github.com/vuquang23/trustme/internal/pkg/util/id"
+)
+
+// KeyPrefix starts every API key, telling them apart from JWTs in a bearer token.
+const KeyPrefix = "tm_"
+
+var jwtMethods = []string{"RS256", "RS384", "RS512", "PS256", "PS384", "PS512", "ES256", "ES384", "ES512", "EdDSA"}
+
+// Authenticator resolves the principal of a credential, an API key or a JWT, and manages the API keys.
+type Authenticator struct {
+	cfg        Config
+	repo       IKeyRepository
+	staticKeys map[string]*entity.APIKey
+	jwks       []verificationKey
+	jwtParser  *jwt.Parser
+}
+
+func New(cfg Config, repo IKeyRepository) (*Authenticator, error) {
+	a := &Authenticator{
+		cfg:        cfg,
+		repo:       repo,
+		staticKeys: make(map[string]*entity.APIKey, len(cfg.Keys)),
+	}
+
+	for i, key := range cfg.Keys {
+		hash := strings.ToLower(key.Hash)
+		if b, err := hex.DecodeString(hash); err != nil || len(b) != sha256.Size {
+			return nil, fmt.Errorf("auth key %q: hash must be a hex SHA-256", key.Name)
+		}
+		if key.Tenant == "" && !key.Admin {
+			return nil, fmt.Errorf("auth key %q: a tenant is required for non admin keys", key.Name)
+		}
+		a.staticKeys[hash] = &entity.APIKey{
+			ID:     fmt.Sprintf("config-%d", i),
+			Name:   key.Name,
+			Tenant: key.Tenant,
+			Admin:  key.Admin,
+			Hash:   hash,
+		}
+	}
+
+	if cfg.JWT.JWKSFile != "" {
+		jwks, err := loadJWKS(cfg.JWT.JWKSFile)
+		if err != nil {
+			return nil, err
+		}
+		a.jwks = jwks
+
+		options := []jwt.ParserOption{jwt.WithValidMethods(jwtMethods), jwt.WithExpirationRequired(), jwt.WithLeeway(time.Minute)}
+		if cfg.JWT.Issuer != "" {
+			options = append(options, jwt.WithIssuer(cfg.JWT.Issuer))
+		}
+		if cfg.JWT.Audience != "" {
+			options = append(options, jwt.WithAudience(cfg.JWT.Audience))
+		}
+		a.jwtParser = jwt.NewParser(options...)
+	}
+
+	return a, nil
+}
+
+// Authenticate returns the principal of an API key or a JWT, ErrUnauthorized when it is not valid.
+func (a *Authenticator) Authenticate(credential string) (*entity.Principal, error) {
+	if credential == "" {
+		return nil, fmt.Errorf("%w: missing credentials", entity.ErrUnauthorized)
+	}
+	if strings.HasPrefix(credential, KeyPrefix) {
+		return a.authenticateKey(credential)
+	}
+	return a.authenticateJWT(credential)
+}
+
+func (a *Authenticator) authenticateKey(key string) (*entity.Principal, error) {
+	hash := HashKey(key)
+
+	apiKey, ok := a.staticKeys[hash]
+	if !ok {
+		var err error
+		if apiKey, err = a.repo.GetKeyByHash(hash); err != nil {
+			return nil, entity.BackendError(err)
+		}
+		if apiKey == nil {
+			return nil, fmt.Errorf("%w: invalid api key", entity.ErrUnauthorized)
+		}
+	}
+
+	return &entity.Principal{KeyID: apiKey.ID, Tenant: apiKey.Tenant, Admin: apiKey.Admin}, nil
+}
+
+func (a *Authenticator) authenticateJWT(token string) (*entity.Principal, error) {
+	if a.jwtParser == nil {
+		return nil, fmt.Errorf("%w: invalid api key", entity.ErrUnauthorized)
+	}
+
+	claims := jwt.MapClaims{}
+	if _, err := a.jwtParser.ParseWithClaims(token, claims, a.verificationKey); err != nil {
+		return nil, fmt.Errorf("%w: %v", entity.ErrUnauthorized, err)
+	}
+
+	principal := &entity.Principal{}
+	principal.KeyID, _ = claims.GetSubject()
+	principal.Tenant, _ = claims[a.cfg.JWT.TenantClaim].(string)
+	if scope, ok := claims["scope"].(string); ok && a.cfg.JWT.AdminScope != "" {
+		for _, s := range strings.Fields(scope) {
+			if s == a.cfg.JWT.AdminScope {
+				principal.Admin = true
+			}
+		}
+	}
+
+	if principal.Tenant == "" && !principal.Admin {
+		return nil, fmt.Errorf("%w: token has no %s claim", entity.ErrUnauthorized, a.cfg.JWT.TenantClaim)
+	}
+	return principal, nil
+}
+
+// verificationKey picks the key of the token kid, or the only key of the set when the token has none.
+func (a *Authenticator) verificationKey(token *jwt.Token) (interface{}, error) {
+	kid, _ := token.Header["kid"].(string)
+	for _, key := range a.jwks {
+		if key.kid != kid && (kid != "" || len(a.jwks) > 1) {
+			continue
+		}
+		if key.alg != "" && key.alg != token.Method.Alg() {
+			return nil, fmt.Errorf("key %q is not for %s", key.kid, token.Method.Alg())
+		}
+		return key.key, nil
+	}
+	return nil, fmt.Errorf("unknown key %q", kid)
+}
+
+// CreateKey generates an API key, returned along with its stored form. The key can't be recovered afterwards.
+func (a *Authenticator) CreateKey(name, tenant string, admin bool) (string, *entity.APIKey, error) {
+	if tenant == "" && !admin {
+		return "", nil, fmt.Errorf("%w: a tenant is required for non admin keys", entity.ErrInvalidParams)
+	}
+
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return "", nil, err
+	}
+	key := KeyPrefix + base64.RawURLEncoding.EncodeToString(secret)
+
+	apiKey := &entity.APIKey{
+		ID:        id.New(),
+		Name:      name,
+		Tenant:    tenant,
+		Admin:     admin,
+		Hash:      HashKey(key),
+		CreatedAt: time.Now().UTC(),
+	}
+	if err := a.repo.CreateKey(apiKey); err != nil {
+		return "", nil, entity.BackendError(err)
+	}
+
+	return key, apiKey, nil
+}
+
+// ListKeys returns the stored keys, the ones of the configuration aside.
+func (a *Authenticator) ListKeys() ([]*entity.APIKey, error) {
+	keys, err := a.repo.ListKeys()
+	if err != nil {
+		return nil, entity.BackendError(err)
+	}
+	return keys, nil
+}
+
+// RevokeKey deletes a stored key, requests made with it are refused from then on.
+func (a *Authenticator) RevokeKey(id string) error {
+	key, err := a.repo.GetKey(id)
+	if err != nil {
+		return entity.BackendError(err)
+	}
+	if key == nil {
+		return entity.ErrAPIKeyNotFound
+	}
+
+	if err := a.repo.DeleteKey(id); err != nil {
+		return entity.BackendError(err)
+	}
+	return nil
+}
+
+// Enabled tells whether requests need credentials.
+func (a *Authenticator) Enabled() bool {
+	return a.cfg.Enabled
+}
+
+// IsPublic tells whether a path is served without credentials.
+func (a *Authenticator) IsPublic(path string) bool {
+	for _, prefix := range a.cfg.PublicPaths {
+		if strings.HasPrefix(path, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// HashKey is the stored form of an API key.
+func HashKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,220 @@
+package auth
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+
+	"github.com/vuquang23/trustme/internal/pkg/entity"
+	"github.com/vuquang23/trustme/internal/pkg/repository/apikey"
+)
+
+func TestAPIKeys(t *testing.T) {
+	a, err := New(Config{Enabled: true}, apikey.NewMemRepository())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	key, stored, err := a.CreateKey("deposits", "acme", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(key, KeyPrefix) {
+		t.Fatalf("got key %q without the %s prefix", key, KeyPrefix)
+	}
+	// only the hash of the key is stored
+	if stored.Hash != HashKey(key) || strings.Contains(stored.Hash, strings.TrimPrefix(key, KeyPrefix)) {
+		t.Fatalf("got stored hash %q for key %q", stored.Hash, key)
+	}
+
+	principal, err := a.Authenticate(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if principal.KeyID != stored.ID || principal.Tenant != "acme" || principal.Admin {
+		t.Fatalf("got principal %+v", principal)
+	}
+
+	for _, credential := range []string{"", key + "x", KeyPrefix + "unknown"} {
+		if _, err := a.Authenticate(credential); !errors.Is(err, entity.ErrUnauthorized) {
+			t.Fatalf("%q: got %v, want %v", credential, err, entity.ErrUnauthorized)
+		}
+	}
+
+	if err := a.RevokeKey(stored.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := a.Authenticate(key); !errors.Is(err, entity.ErrUnauthorized) {
+		t.Fatalf("got %v with a revoked key, want %v", err, entity.ErrUnauthorized)
+	}
+	if err := a.RevokeKey(stored.ID); !errors.Is(err, entity.ErrAPIKeyNotFound) {
+		t.Fatalf("got %v revoking twice, want %v", err, entity.ErrAPIKeyNotFound)
+	}
+
+	if _, _, err := a.CreateKey("no tenant", "", false); !errors.Is(err, entity.ErrInvalidParams) {
+		t.Fatalf("got %v for a key without tenant, want %v", err, entity.ErrInvalidParams)
+	}
+}
+
+func TestStaticKeys(t *testing.T) {
+	const key = KeyPrefix + "static"
+
+	a, err := New(Config{Keys: []StaticKey{{Name: "ops", Admin: true, Hash: strings.ToUpper(HashKey(key))}}}, apikey.NewMemRepository())
+	if err != nil {
+		t.Fatal(err)
+	}
+	principal, err := a.Authenticate(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if principal.KeyID != "config-0" || !principal.Admin {
+		t.Fatalf("got principal %+v", principal)
+	}
+
+	for _, static := range []StaticKey{
+		{Name: "plain text", Tenant: "acme", Hash: key},
+		{Name: "no tenant", Hash: HashKey(key)},
+	} {
+		if _, err := New(Config{Keys: []StaticKey{static}}, apikey.NewMemRepository()); err == nil {
+			t.Fatalf("%s: got no error", static.Name)
+		}
+	}
+}
+
+// writeJWKS writes a JSON Web Key Set of the public key of signer under kid.
+func writeJWKS(t *testing.T, kid string, signer *ecdsa.PrivateKey) string {
+	t.Helper()
+
+	encode := func(b []byte) string { return base64.RawURLEncoding.EncodeToString(b) }
+	set := map[string]interface{}{
+		"keys": []map[string]string{{
+			"kty": "EC",
+			"kid": kid,
+			"use": "sig",
+			"alg": "ES256",
+			"crv": "P-256",
+			"x":   encode(signer.PublicKey.X.FillBytes(make([]byte, 32))),
+			"y":   encode(signer.PublicKey.Y.FillBytes(make([]byte, 32))),
+		}},
+	}
+	b, err := json.Marshal(set)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	if err := os.WriteFile(path, b, 0600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestJWT(t *testing.T) {
+	signer, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var cfg Config
+	cfg.JWT = JWTConfig{
+		JWKSFile:    writeJWKS(t, "k1", signer),
+		Issuer:      "https://issuer.example",
+		Audience:    "trustme",
+		TenantClaim: "tenant",
+		AdminScope:  "admin",
+	}
+	a, err := New(cfg, apikey.NewMemRepository())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	claims := func(change func(jwt.MapClaims)) jwt.MapClaims {
+		c := jwt.MapClaims{
+			"sub":    "user-1",
+			"iss":    "https://issuer.example",
+			"aud":    "trustme",
+			"exp":    time.Now().Add(time.Hour).Unix(),
+			"tenant": "acme",
+		}
+		if change != nil {
+			change(c)
+		}
+		return c
+	}
+	sign := func(key *ecdsa.PrivateKey, kid string, c jwt.MapClaims) string {
+		token := jwt.NewWithClaims(jwt.SigningMethodES256, c)
+		token.Header["kid"] = kid
+		s, err := token.SignedString(key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return s
+	}
+
+	principal, err := a.Authenticate(sign(signer, "k1", claims(nil)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if principal.KeyID != "user-1" || principal.Tenant != "acme" || principal.Admin {
+		t.Fatalf("got principal %+v", principal)
+	}
+
+	admin, err := a.Authenticate(sign(signer, "k1", claims(func(c jwt.MapClaims) {
+		delete(c, "tenant")
+		c["scope"] = "read admin"
+	})))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !admin.Admin {
+		t.Fatalf("got principal %+v, want an admin", admin)
+	}
+
+	hmacToken, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims(nil)).SignedString([]byte("secret"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	unsigned, err := jwt.NewWithClaims(jwt.SigningMethodNone, claims(nil)).SignedString(jwt.UnsafeAllowNoneSignatureType)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for name, token := range map[string]string{
+		"expired":        sign(signer, "k1", claims(func(c jwt.MapClaims) { c["exp"] = time.Now().Add(-time.Hour).Unix() })),
+		"no expiry":      sign(signer, "k1", claims(func(c jwt.MapClaims) { delete(c, "exp") })),
+		"other issuer":   sign(signer, "k1", claims(func(c jwt.MapClaims) { c["iss"] = "https://evil.example" })),
+		"other audience": sign(signer, "k1", claims(func(c jwt.MapClaims) { c["aud"] = "other" })),
+		"no tenant":      sign(signer, "k1", claims(func(c jwt.MapClaims) { delete(c, "tenant") })),
+		"other key":      sign(other, "k1", claims(nil)),
+		"unknown kid":    sign(signer, "k2", claims(nil)),
+		"hmac":           hmacToken,
+		"unsigned":       unsigned,
+	} {
+		if _, err := a.Authenticate(token); !errors.Is(err, entity.ErrUnauthorized) {
+			t.Errorf("%s: got %v, want %v", name, err, entity.ErrUnauthorized)
+		}
+	}
+
+	// without a key set, only API keys are accepted
+	noJWT, err := New(Config{}, apikey.NewMemRepository())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := noJWT.Authenticate(sign(signer, "k1", claims(nil))); !errors.Is(err, entity.ErrUnauthorized) {
+		t.Fatalf("got %v, want %v", err, entity.ErrUnauthorized)
+	}
+}
@@ -0,0 +1,31 @@
+package auth
+
+type Config struct {
+	// Enabled requires an API key or a JWT bearer token on every route but the PublicPaths.
+	Enabled bool
+	// PublicPaths are the path prefixes served without credentials.
+	PublicPaths []string `default:"[/healthz,/readyz,/metrics,/api/openapi.json,/api/docs/]"`
+	// Keys are API keys from the configuration, given by the hex SHA-256 hash of the key.
+	Keys []StaticKey
+
+	JWT JWTConfig
+}
+
+type StaticKey struct {
+	Name   string
+	Tenant string
+	Admin  bool
+	Hash   string
+}
+
+type JWTConfig struct {
+	// JWKSFile is a local JSON Web Key Set verifying bearer tokens, JWTs are refused when empty.
+	JWKSFile string
+	// Issuer and Audience are required to match the iss and aud claims when set.
+	Issuer   string
+	Audience string
+	// TenantClaim is the claim holding the tenant of the token.
+	TenantClaim string `default:"tenant"`
+	// AdminScope in the space separated scope claim makes the token an admin one.
+	AdminScope string `default:"admin"`
+}
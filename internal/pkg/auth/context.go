@@ -0,0 +1,19 @@
+package auth
+
+import (
+	"context"
+
+	"github.com/vuquang23/trustme/internal/pkg/entity"
+)
+
+type principalKey struct{}
+
+func WithPrincipal(ctx context.Context, principal *entity.Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, principal)
+}
+
+// PrincipalFromContext returns the principal of an authenticated request, nil when authentication is disabled.
+func PrincipalFromContext(ctx context.Context) *entity.Principal {
+	principal, _ := ctx.Value(principalKey{}).(*entity.Principal)
+	return principal
+}
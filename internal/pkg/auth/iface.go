@@ -0,0 +1,14 @@
+package auth
+
+import "github.com/vuquang23/trustme/internal/pkg/entity"
+
+type IKeyRepository interface {
+	CreateKey(key *entity.APIKey) error
+	// nil when the key does not exist
+	GetKey(id string) (*entity.APIKey, error)
+	// nil when no key has the hash
+	GetKeyByHash(hash string) (*entity.APIKey, error)
+	// keys ordered by ID
+	ListKeys() ([]*entity.APIKey, error)
+	DeleteKey(id string) error
+}
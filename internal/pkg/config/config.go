@@ -3,19 +3,47 @@ package config
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"log"
 	"strings"
 
 	"github.com/mcuadros/go-defaults"
 	"github.com/spf13/viper"
 
+	"github.com/vuquang23/trustme/internal/pkg/api"
+	"github.com/vuquang23/trustme/internal/pkg/auth"
+	"github.com/vuquang23/trustme/internal/pkg/graph"
+	"github.com/vuquang23/trustme/internal/pkg/grpcserver"
+	"github.com/vuquang23/trustme/internal/pkg/health"
+	"github.com/vuquang23/trustme/internal/pkg/jsonrpc"
+	"github.com/vuquang23/trustme/internal/pkg/metrics"
+	"github.com/vuquang23/trustme/internal/pkg/parser"
+	"github.com/vuquang23/trustme/internal/pkg/ratelimit"
+	"github.com/vuquang23/trustme/internal/pkg/repository"
 	"github.com/vuquang23/trustme/internal/pkg/server"
+	"github.com/vuquang23/trustme/internal/pkg/sink"
+	"github.com/vuquang23/trustme/internal/pkg/tracing"
+	"github.com/vuquang23/trustme/internal/pkg/webhook"
 	"github.com/vuquang23/trustme/pkg/logger"
 )
 
 type Config struct {
-	Http server.Config
-	Log  logger.Config
+	Http       server.Config
+	GRPC       grpcserver.Config
+	API        api.Config
+	Auth       auth.Config
+	RateLimit  ratelimit.Config
+	GraphQL    graph.Config
+	JSONRPC    jsonrpc.Config
+	Health     health.Config
+	Metrics    metrics.Config
+	Tracing    tracing.Config
+	Log        logger.Config
+	Parser     parser.Config
+	Repository repository.Config
+	Webhook    webhook.Config
+	Sink       sink.Config
 }
 
 func New() Config {
@@ -51,3 +79,47 @@ func (c *Config) Load(cfgFile string) error {
 
 	return nil
 }
+
+// Validate checks the loaded configuration: the keys of the file that match no setting, and the values the server
+// refuses when starting.
+func (c *Config) Validate() error {
+	var errs []error
+
+	exact := New()
+	defaults.SetDefaults(&exact)
+	if err := viper.UnmarshalExact(&exact); err != nil {
+		errs = append(errs, err)
+	}
+
+	if c.Http.BindAddress == "" {
+		errs = append(errs, errors.New("Http.BindAddress is required"))
+	}
+	switch c.Repository.Backend {
+	case repository.BackendMemory, repository.BackendBolt:
+	default:
+		errs = append(errs, fmt.Errorf("unsupported repository backend: %s", c.Repository.Backend))
+	}
+	switch c.Sink.Driver {
+	case "", sink.DriverNATS, sink.DriverKafka:
+	default:
+		errs = append(errs, fmt.Errorf("unsupported sink driver: %s", c.Sink.Driver))
+	}
+	switch c.Tracing.Protocol {
+	case tracing.ProtocolGRPC, tracing.ProtocolHTTP:
+	default:
+		errs = append(errs, fmt.Errorf("unsupported tracing protocol: %s", c.Tracing.Protocol))
+	}
+	if c.Tracing.SampleRatio < 0 || c.Tracing.SampleRatio > 1 {
+		errs = append(errs, fmt.Errorf("Tracing.SampleRatio must be between 0 and 1, got %v", c.Tracing.SampleRatio))
+	}
+
+	// the static keys, the JWKS file and the rate limits are checked by building what uses them
+	if _, err := auth.New(c.Auth, nil); err != nil {
+		errs = append(errs, err)
+	}
+	if _, err := ratelimit.New(c.RateLimit); err != nil {
+		errs = append(errs, err)
+	}
+
+	return errors.Join(errs...)
+}
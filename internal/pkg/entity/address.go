@@ -0,0 +1,40 @@
+package entity
+
+import (
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// ParseAddress validates a 0x-prefixed hex address.
+// A mixed-case address must carry a valid EIP-55 checksum; with enforceChecksum, all-lowercase and
+// all-uppercase addresses are rejected as well.
+func ParseAddress(s string, enforceChecksum bool) (common.Address, error) {
+	if !strings.HasPrefix(s, "0x") || len(s) != 2+2*common.AddressLength || !common.IsHexAddress(s) {
+		return common.Address{}, ErrInvalidAddress
+	}
+
+	address := common.HexToAddress(s)
+
+	hex := s[2:]
+	isMixedCase := strings.ToLower(hex) != hex && strings.ToUpper(hex) != hex
+	if (isMixedCase || enforceChecksum) && address.Hex() != s {
+		return common.Address{}, ErrInvalidChecksum
+	}
+
+	return address, nil
+}
+
+// ParseAddressKey validates an address like ParseAddress and returns its repository key.
+func ParseAddressKey(s string, enforceChecksum bool) (string, error) {
+	address, err := ParseAddress(s, enforceChecksum)
+	if err != nil {
+		return "", err
+	}
+	return AddressKey(address), nil
+}
+
+// AddressKey is the normalized form addresses are stored under in the repositories.
+func AddressKey(address common.Address) string {
+	return strings.ToLower(address.Hex())
+}
@@ -0,0 +1,22 @@
+package entity
+
+import "time"
+
+// APIKey authenticates requests. Only the SHA-256 hash of the key is stored, the key itself is shown once.
+type APIKey struct {
+	ID   string
+	Name string
+	// Tenant scopes the subscriptions and transactions the key sees, admin keys see everything.
+	Tenant    string
+	Admin     bool
+	Hash      string
+	CreatedAt time.Time
+}
+
+// Principal is who a request is authenticated as.
+type Principal struct {
+	// KeyID is the ID of the API key, or the subject of the JWT.
+	KeyID  string
+	Tenant string
+	Admin  bool
+}
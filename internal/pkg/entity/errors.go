@@ -0,0 +1,32 @@
+package entity
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	ErrInvalidParams      = errors.New("invalid params")
+	ErrInvalidAddress     = errors.New("invalid address")
+	ErrInvalidChecksum    = errors.New("invalid address checksum")
+	ErrInvalidCursor      = errors.New("invalid cursor")
+	ErrInvalidTxHash      = errors.New("invalid tx hash")
+	ErrTxNotFound         = errors.New("transaction not found")
+	ErrNotSubscribed      = errors.New("address is not subscribed")
+	ErrWebhookNotFound    = errors.New("webhook not found")
+	ErrDeliveryNotFound   = errors.New("webhook delivery not found")
+	ErrAPIKeyNotFound     = errors.New("api key not found")
+	ErrAlreadySubscribed  = errors.New("address is already subscribed")
+	ErrReprocessRunning   = errors.New("a reprocess is already running")
+	ErrUnauthorized       = errors.New("unauthorized")
+	ErrForbidden          = errors.New("forbidden")
+	ErrRateLimited        = errors.New("too many requests")
+	ErrQuotaExceeded      = errors.New("quota exceeded")
+	ErrBackendUnavailable = errors.New("backend unavailable")
+	ErrNotReady           = errors.New("not ready")
+)
+
+// BackendError marks a repository failure so the APIs report the backend as unavailable.
+func BackendError(err error) error {
+	return fmt.Errorf("%w: %v", ErrBackendUnavailable, err)
+}
@@ -0,0 +1,84 @@
+package entity
+
+import (
+	"slices"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+type EventType string
+
+const (
+	// EventTypeTx is published when a transaction of a subscribed address is saved.
+	EventTypeTx EventType = "tx"
+	// EventTypeTokenTransfer is published when a token Transfer log involves a subscribed address.
+	EventTypeTokenTransfer EventType = "token_transfer"
+	// EventTypeConfirmation is published once a saved transaction reaches the configured number of confirmations.
+	EventTypeConfirmation EventType = "confirmation"
+	// EventTypeReorg is published when a saved transaction is removed because its block was reorged out.
+	EventTypeReorg EventType = "reorg"
+
+	// EventTypeBlock is published once a block is processed, it is not about an address.
+	EventTypeBlock EventType = "block"
+)
+
+// EventTypes are the event types of a subscribed address.
+var EventTypes = []EventType{EventTypeTx, EventTypeTokenTransfer, EventTypeConfirmation, EventTypeReorg}
+
+// IsEventType tells whether event is one of EventTypes.
+func IsEventType(event EventType) bool {
+	return slices.Contains(EventTypes, event)
+}
+
+// Event is a notification published by the parser on the event bus.
+type Event struct {
+	Type EventType
+	// Address is the subscribed address the event is about.
+	Address string
+
+	// Record is set for tx, confirmation and reorg events.
+	Record *TxRecord
+	// Transfer is set for token_transfer events.
+	Transfer *TokenTransfer
+	// Confirmations is set for confirmation events.
+	Confirmations uint64
+	// Block is set for block events.
+	Block *BlockSummary
+}
+
+// EventPayload is the body of a webhook delivery or a message published to a broker.
+type EventPayload struct {
+	ID        string      `json:"id"`
+	Event     string      `json:"event"`
+	Address   string      `json:"address,omitempty"`
+	CreatedAt time.Time   `json:"createdAt"`
+	Data      interface{} `json:"data"`
+}
+
+// EventDataFunc encodes the data of an event, the way the API serves it.
+type EventDataFunc func(event Event) interface{}
+
+func NewEventPayload(id string, event Event, createdAt time.Time, data interface{}) EventPayload {
+	payload := EventPayload{
+		ID:        id,
+		Event:     string(event.Type),
+		CreatedAt: createdAt,
+		Data:      data,
+	}
+	if event.Address != "" {
+		payload.Address = common.HexToAddress(event.Address).Hex()
+	}
+	return payload
+}
+
+// BlockSummary describes a processed block.
+type BlockSummary struct {
+	Number     uint64
+	Hash       common.Hash
+	ParentHash common.Hash
+	Timestamp  uint64
+	TxCount    int
+	// MatchedTxs is the number of saved transaction records.
+	MatchedTxs int
+}
@@ -0,0 +1,26 @@
+package entity
+
+type ImportStatus string
+
+const (
+	ImportStatusCreated   ImportStatus = "created"
+	ImportStatusDuplicate ImportStatus = "duplicate"
+	ImportStatusInvalid   ImportStatus = "invalid"
+	ImportStatusFailed    ImportStatus = "failed"
+)
+
+type ImportItemResult struct {
+	// Line is the 1-based position of the item in the input.
+	Line    int
+	Address string
+	Status  ImportStatus
+	Error   string
+}
+
+type ImportResult struct {
+	Items      []ImportItemResult
+	Created    int
+	Duplicates int
+	Invalid    int
+	Failed     int
+}
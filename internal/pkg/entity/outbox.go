@@ -0,0 +1,15 @@
+package entity
+
+import "time"
+
+// OutboxMessage is an event waiting to be published to the message broker.
+type OutboxMessage struct {
+	// Seq is assigned by the outbox, messages are published in Seq order.
+	Seq   uint64
+	ID    string
+	Event EventType
+	// Key orders the messages on the broker: the address, or the block number for block events.
+	Key       string
+	Payload   []byte
+	CreatedAt time.Time
+}
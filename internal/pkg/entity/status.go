@@ -0,0 +1,91 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// SyncStatus is the progress of the parser against the chain.
+type SyncStatus struct {
+	// CurrentBlock is the last processed block.
+	CurrentBlock uint64
+	// ChainHead is the highest block seen on the node, 0 until the first head.
+	ChainHead uint64
+	// LastBlockTime is the timestamp of the last processed block.
+	LastBlockTime time.Time
+	// LastProcessedAt is when the last block was processed.
+	LastProcessedAt time.Time
+	WS              WSStatus
+	// Backfill is set while a range of blocks is being processed.
+	Backfill *BackfillProgress
+}
+
+// Lag is the number of blocks between the chain head and the last processed block.
+func (s SyncStatus) Lag() uint64 {
+	if s.ChainHead <= s.CurrentBlock {
+		return 0
+	}
+	return s.ChainHead - s.CurrentBlock
+}
+
+// WSStatus is the state of the new heads subscription of the parser.
+type WSStatus struct {
+	Connected   bool
+	ConnectedAt time.Time
+	// LastHeadAt is when the last new head was received.
+	LastHeadAt time.Time
+	// Reconnects counts the subscriptions lost since the start.
+	Reconnects int
+	LastError  string
+}
+
+// BackfillProgress is the range of a reprocess and the block it is at.
+type BackfillProgress struct {
+	From    uint64
+	To      uint64
+	Current uint64
+}
+
+// HealthCheck is the result of one readiness check, Error is empty when it passed.
+type HealthCheck struct {
+	Name  string
+	Error string
+}
+
+type Readiness struct {
+	Checks []HealthCheck
+}
+
+func (r Readiness) Ready() bool {
+	for _, check := range r.Checks {
+		if check.Error != "" {
+			return false
+		}
+	}
+	return true
+}
+
+// FailedBlock is a block the parser failed to process, kept until it gets processed or newer failures evict it.
+type FailedBlock struct {
+	// Hash is zero when the block could not be looked up by number.
+	Hash          common.Hash
+	Number        uint64
+	Error         string
+	Attempts      int
+	FirstFailedAt time.Time
+	LastFailedAt  time.Time
+}
+
+// ParserInternals is the state of the parser shown to the operators.
+type ParserInternals struct {
+	Paused bool
+	// Checkpoint is the block stored as processed, where the parser starts from after a restart.
+	Checkpoint    uint64
+	QueueDepth    int
+	QueueCapacity int
+	// PendingConfirmations counts the records waiting for their confirmation event.
+	PendingConfirmations int
+	FailedBlocks         int
+	Sync                 SyncStatus
+}
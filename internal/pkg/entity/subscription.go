@@ -0,0 +1,20 @@
+package entity
+
+import "time"
+
+// Subscription is an address watched by the parser with the metadata given when it was subscribed.
+type Subscription struct {
+	Address   string
+	Label     string
+	Tags      []string
+	Owner     string
+	Notes     string
+	CreatedAt time.Time
+}
+
+type SubscriptionPage struct {
+	Subscriptions []*Subscription
+	// NextCursor is the address to resume listing after, empty on the last page.
+	NextCursor string
+	Total      int
+}
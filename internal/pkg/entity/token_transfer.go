@@ -0,0 +1,24 @@
+package entity
+
+import (
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// TokenTransfer is an ERC-20 or ERC-721 Transfer log.
+type TokenTransfer struct {
+	Token common.Address
+	From  common.Address
+	To    common.Address
+	// Value is the amount of an ERC-20 transfer, nil for ERC-721.
+	Value *big.Int
+	// TokenID is the token of an ERC-721 transfer, nil for ERC-20.
+	TokenID *big.Int
+
+	TxHash      common.Hash
+	LogIndex    uint
+	BlockNumber uint64
+	BlockHash   common.Hash
+	TxIndex     uint
+}
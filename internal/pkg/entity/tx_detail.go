@@ -0,0 +1,39 @@
+package entity
+
+import (
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// TxDetail is everything known about one transaction.
+// When Indexed is false the transaction isn't stored and the detail comes straight from the RPC.
+type TxDetail struct {
+	Indexed bool
+	Pending bool
+
+	Tx   *types.Transaction
+	From common.Address
+
+	BlockNumber uint64
+	BlockHash   common.Hash
+	Timestamp   uint64
+	TxIndex     uint
+
+	// Receipt is nil for pending transactions or when it couldn't be fetched.
+	Receipt *types.Receipt
+	Logs    []DecodedLog
+	// Transfers are the token Transfer logs of the receipt.
+	Transfers []*TokenTransfer
+
+	// Records holds one record per subscribed party the transaction is indexed under.
+	Records []*TxRecord
+}
+
+// DecodedLog is a receipt log with its event decoded when it's one of the well-known token events.
+type DecodedLog struct {
+	Log *types.Log
+
+	// Event is empty when the log couldn't be decoded.
+	Event string
+	Args  map[string]string
+}
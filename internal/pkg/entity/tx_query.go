@@ -0,0 +1,197 @@
+package entity
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+type SortOrder string
+
+const (
+	SortOrderAsc  SortOrder = "asc"
+	SortOrderDesc SortOrder = "desc"
+)
+
+// TxQuery selects a page of the transaction history of one address. Nil and zero fields do not filter.
+type TxQuery struct {
+	FromBlock    *uint64
+	ToBlock      *uint64
+	FromTime     *uint64
+	ToTime       *uint64
+	Direction    TxDirection
+	MinValue     *big.Int
+	MaxValue     *big.Int
+	Status       TxStatus
+	Counterparty *common.Address
+	TxType       *uint8
+
+	Order  SortOrder
+	Cursor string
+	Limit  int
+}
+
+// TxQueryParams are the filters of a transaction query as the APIs receive them, the values and the counterparty
+// not parsed yet. The limit is resolved by each API.
+type TxQueryParams struct {
+	FromBlock    *uint64
+	ToBlock      *uint64
+	FromTime     *uint64
+	ToTime       *uint64
+	Direction    string
+	MinValue     string
+	MaxValue     string
+	Status       string
+	Counterparty string
+	TxType       *uint8
+
+	Order  SortOrder
+	Cursor string
+	Limit  int
+}
+
+// ParseTxQuery validates the params and converts them to a query.
+func ParseTxQuery(params TxQueryParams, enforceChecksum bool) (TxQuery, error) {
+	query := TxQuery{
+		FromBlock: params.FromBlock,
+		ToBlock:   params.ToBlock,
+		FromTime:  params.FromTime,
+		ToTime:    params.ToTime,
+		Direction: TxDirection(params.Direction),
+		Status:    TxStatus(params.Status),
+		TxType:    params.TxType,
+		Order:     params.Order,
+		Cursor:    params.Cursor,
+		Limit:     params.Limit,
+	}
+
+	switch query.Direction {
+	case "", TxDirectionIn, TxDirectionOut, TxDirectionSelf:
+	default:
+		return query, fmt.Errorf("%w: invalid direction: %s", ErrInvalidParams, params.Direction)
+	}
+
+	switch query.Status {
+	case "", TxStatusSuccess, TxStatusFailed:
+	default:
+		return query, fmt.Errorf("%w: invalid status: %s", ErrInvalidParams, params.Status)
+	}
+
+	switch query.Order {
+	case "", SortOrderAsc, SortOrderDesc:
+	default:
+		return query, fmt.Errorf("%w: invalid order: %s", ErrInvalidParams, params.Order)
+	}
+
+	if query.Cursor != "" {
+		if _, _, err := DecodeTxCursor(query.Cursor); err != nil {
+			return query, err
+		}
+	}
+
+	if params.MinValue != "" {
+		v, ok := new(big.Int).SetString(params.MinValue, 10)
+		if !ok {
+			return query, fmt.Errorf("%w: invalid minValue: %s", ErrInvalidParams, params.MinValue)
+		}
+		query.MinValue = v
+	}
+
+	if params.MaxValue != "" {
+		v, ok := new(big.Int).SetString(params.MaxValue, 10)
+		if !ok {
+			return query, fmt.Errorf("%w: invalid maxValue: %s", ErrInvalidParams, params.MaxValue)
+		}
+		query.MaxValue = v
+	}
+
+	if params.Counterparty != "" {
+		counterparty, err := ParseAddress(params.Counterparty, enforceChecksum)
+		if err != nil {
+			return query, err
+		}
+		query.Counterparty = &counterparty
+	}
+
+	return query, nil
+}
+
+type TxPage struct {
+	Records    []*TxRecord
+	NextCursor string
+	// Total is the number of records matching the filters, nil when the repository can't count it cheaply.
+	Total *int
+}
+
+// HasFilters reports whether the query narrows the history down, ignoring pagination.
+func (q TxQuery) HasFilters() bool {
+	return q.FromBlock != nil || q.ToBlock != nil || q.FromTime != nil || q.ToTime != nil ||
+		q.Direction != "" || q.MinValue != nil || q.MaxValue != nil || q.Status != "" ||
+		q.Counterparty != nil || q.TxType != nil
+}
+
+// Match applies the filters of the query to a record.
+func (q TxQuery) Match(record *TxRecord) bool {
+	if q.FromBlock != nil && record.BlockNumber < *q.FromBlock {
+		return false
+	}
+	if q.ToBlock != nil && record.BlockNumber > *q.ToBlock {
+		return false
+	}
+	if q.FromTime != nil && record.Timestamp < *q.FromTime {
+		return false
+	}
+	if q.ToTime != nil && record.Timestamp > *q.ToTime {
+		return false
+	}
+	if q.Direction != "" && record.Direction != q.Direction {
+		return false
+	}
+	if q.MinValue != nil && record.Tx.Value().Cmp(q.MinValue) < 0 {
+		return false
+	}
+	if q.MaxValue != nil && record.Tx.Value().Cmp(q.MaxValue) > 0 {
+		return false
+	}
+	if q.Status != "" && record.Status != q.Status {
+		return false
+	}
+	if q.Counterparty != nil && record.Counterparty() != *q.Counterparty {
+		return false
+	}
+	if q.TxType != nil && record.Tx.Type() != *q.TxType {
+		return false
+	}
+	return true
+}
+
+// Counterparty is the other side of the transaction from the subscriber point of view.
+// Contract creations have no counterparty and return the zero address.
+func (r *TxRecord) Counterparty() common.Address {
+	if r.Direction != TxDirectionOut {
+		return r.From
+	}
+	if r.Tx.To() == nil {
+		return common.Address{}
+	}
+	return *r.Tx.To()
+}
+
+// EncodeTxCursor builds the opaque cursor pointing at a position in the history.
+func EncodeTxCursor(blockNumber uint64, txIndex uint) string {
+	b := make([]byte, 12)
+	binary.BigEndian.PutUint64(b[:8], blockNumber)
+	binary.BigEndian.PutUint32(b[8:], uint32(txIndex))
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func DecodeTxCursor(cursor string) (uint64, uint, error) {
+	b, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil || len(b) != 12 {
+		return 0, 0, ErrInvalidCursor
+	}
+	return binary.BigEndian.Uint64(b[:8]), uint(binary.BigEndian.Uint32(b[8:])), nil
+}
@@ -0,0 +1,87 @@
+package entity
+
+import (
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+type TxDirection string
+
+const (
+	TxDirectionIn   TxDirection = "in"
+	TxDirectionOut  TxDirection = "out"
+	TxDirectionSelf TxDirection = "self"
+)
+
+type TxStatus string
+
+const (
+	TxStatusSuccess TxStatus = "success"
+	TxStatusFailed  TxStatus = "failed"
+)
+
+// TxRecord is a transaction seen by the parser together with the context it was found in.
+// The same transaction is recorded once per subscribed party.
+type TxRecord struct {
+	// Address is the subscriber the record is indexed under.
+	Address   string
+	Direction TxDirection
+
+	Tx   *types.Transaction
+	From common.Address
+
+	BlockNumber uint64
+	BlockHash   common.Hash
+	Timestamp   uint64
+	TxIndex     uint
+
+	Status            TxStatus
+	GasUsed           uint64
+	EffectiveGasPrice *big.Int
+}
+
+func (r *TxRecord) Hash() common.Hash {
+	return r.Tx.Hash()
+}
+
+// Fee is the amount of wei paid for the gas used by the transaction.
+func (r *TxRecord) Fee() *big.Int {
+	return fee(r.GasUsed, r.EffectiveGasPrice)
+}
+
+// ReceiptStatus maps the status of a receipt.
+func ReceiptStatus(receipt *types.Receipt) TxStatus {
+	if receipt.Status != types.ReceiptStatusSuccessful {
+		return TxStatusFailed
+	}
+	return TxStatusSuccess
+}
+
+// ReceiptFee is the amount of wei paid for the gas used by the transaction of a receipt.
+func ReceiptFee(receipt *types.Receipt) *big.Int {
+	return fee(receipt.GasUsed, receipt.EffectiveGasPrice)
+}
+
+func fee(gasUsed uint64, effectiveGasPrice *big.Int) *big.Int {
+	if effectiveGasPrice == nil {
+		return new(big.Int)
+	}
+	return new(big.Int).Mul(new(big.Int).SetUint64(gasUsed), effectiveGasPrice)
+}
+
+// DirectionOf tells how a transaction from -> to relates to address.
+func DirectionOf(address string, from common.Address, to *common.Address) TxDirection {
+	isFrom := common.HexToAddress(address) == from
+	isTo := to != nil && common.HexToAddress(address) == *to
+
+	switch {
+	case isFrom && isTo:
+		return TxDirectionSelf
+	case isFrom:
+		return TxDirectionOut
+	default:
+		return TxDirectionIn
+	}
+}
@@ -0,0 +1,85 @@
+package entity
+
+import "time"
+
+// Webhook is a URL receiving the events of a subscribed address.
+type Webhook struct {
+	ID      string
+	Address string
+	URL     string
+	// Secret signs the deliveries with HMAC-SHA256.
+	Secret string
+	// Events limits the delivered event types, empty means all of them.
+	Events []EventType
+	// Tenant created the webhook, empty when it was created without one.
+	Tenant    string
+	CreatedAt time.Time
+}
+
+func (w *Webhook) Accepts(eventType EventType) bool {
+	if len(w.Events) == 0 {
+		return true
+	}
+	for _, t := range w.Events {
+		if t == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+type DeliveryStatus string
+
+const (
+	// DeliveryStatusPending deliveries are waiting for their next attempt in the outbox.
+	DeliveryStatusPending   DeliveryStatus = "pending"
+	DeliveryStatusDelivered DeliveryStatus = "delivered"
+	// DeliveryStatusDead deliveries ran out of attempts, they are only sent again when replayed.
+	DeliveryStatusDead DeliveryStatus = "dead"
+)
+
+// WebhookDelivery is one event to POST to a webhook, with the history of its attempts.
+type WebhookDelivery struct {
+	ID        string
+	WebhookID string
+	Event     EventType
+	Address   string
+	// Payload is the JSON body, signed and sent as is on every attempt.
+	Payload  []byte
+	Status   DeliveryStatus
+	Attempts []DeliveryAttempt
+	// Failures counts the failed attempts since the delivery was enqueued or last replayed.
+	Failures      int
+	NextAttemptAt time.Time
+	// Tenant is the tenant of the webhook.
+	Tenant    string
+	CreatedAt time.Time
+}
+
+type DeliveryAttempt struct {
+	At         time.Time
+	StatusCode int
+	Error      string
+	Duration   time.Duration
+}
+
+// DeliveryQuery selects deliveries, newest first. Empty fields match everything.
+type DeliveryQuery struct {
+	WebhookID string
+	Status    DeliveryStatus
+	Tenant    string
+	// Cursor is the ID of the last delivery of the previous page.
+	Cursor string
+	Limit  int
+}
+
+func (q DeliveryQuery) Match(delivery *WebhookDelivery) bool {
+	return (q.WebhookID == "" || delivery.WebhookID == q.WebhookID) &&
+		(q.Status == "" || delivery.Status == q.Status) &&
+		(q.Tenant == "" || delivery.Tenant == q.Tenant)
+}
+
+type DeliveryPage struct {
+	Deliveries []*WebhookDelivery
+	NextCursor string
+}
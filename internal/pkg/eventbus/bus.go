@@ -0,0 +1,102 @@
+package eventbus
+
+import (
+	"sync"
+
+	"github.com/vuquang23/trustme/internal/pkg/entity"
+)
+
+// Bus fans out events published by the parser to in-process subscribers.
+// Publishing never blocks: a subscriber whose buffer is full is dropped and its channel closed,
+// it is expected to resubscribe and catch up from the repositories.
+type Bus struct {
+	mu          sync.RWMutex
+	nextID      uint64
+	subscribers map[uint64]*Subscription
+}
+
+type Subscription struct {
+	id     uint64
+	bus    *Bus
+	ch     chan entity.Event
+	filter func(entity.Event) bool
+	once   sync.Once
+}
+
+func New() *Bus {
+	return &Bus{
+		subscribers: make(map[uint64]*Subscription),
+	}
+}
+
+// Subscribe registers a subscriber receiving the events accepted by filter, a nil filter accepts everything.
+func (b *Bus) Subscribe(buffer int, filter func(entity.Event) bool) *Subscription {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.nextID++
+	sub := &Subscription{
+		id:     b.nextID,
+		bus:    b,
+		ch:     make(chan entity.Event, buffer),
+		filter: filter,
+	}
+	b.subscribers[sub.id] = sub
+
+	return sub
+}
+
+// Publish never fails, the subscribers that can't keep up are dropped instead.
+func (b *Bus) Publish(event entity.Event) error {
+	b.mu.RLock()
+	var overflowed []*Subscription
+	for _, sub := range b.subscribers {
+		if sub.filter != nil && !sub.filter(event) {
+			continue
+		}
+
+		select {
+		case sub.ch <- event:
+		default:
+			overflowed = append(overflowed, sub)
+		}
+	}
+	b.mu.RUnlock()
+
+	for _, sub := range overflowed {
+		sub.Close()
+	}
+	return nil
+}
+
+// C delivers the events, it is closed when the subscription is closed or dropped.
+func (s *Subscription) C() <-chan entity.Event {
+	return s.ch
+}
+
+func (s *Subscription) Close() {
+	s.once.Do(func() {
+		s.bus.mu.Lock()
+		delete(s.bus.subscribers, s.id)
+		s.bus.mu.Unlock()
+
+		close(s.ch)
+	})
+}
+
+type Publisher interface {
+	Publish(event entity.Event) error
+}
+
+// Publishers publishes every event to each of its publishers, in order. It stops at the first failure,
+// the publishers before it see the event again when the block is processed again.
+type Publishers []Publisher
+
+func (p Publishers) Publish(event entity.Event) error {
+	for _, publisher := range p {
+		if err := publisher.Publish(event); err != nil {
+			return err
+		}
+	}
+	return nil
+}
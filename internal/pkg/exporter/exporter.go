@@ -0,0 +1,122 @@
+package exporter
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/vuquang23/trustme/internal/pkg/api"
+)
+
+type Format string
+
+const (
+	FormatJSON   Format = "json"
+	FormatCSV    Format = "csv"
+	FormatNDJSON Format = "ndjson"
+)
+
+var ErrUnsupportedFormat = errors.New("unsupported export format")
+
+var csvHeader = []string{
+	"hash", "address", "direction", "from", "to", "type", "nonce", "input",
+	"value", "valueEther", "gas", "gasUsed", "effectiveGasPrice", "fee", "feeEther",
+	"blockNumber", "blockHash", "timestamp", "txIndex", "status",
+}
+
+// FormatFromFilename guesses the format from a file extension, defaulting to CSV.
+func FormatFromFilename(name string) Format {
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".json":
+		return FormatJSON
+	case ".ndjson", ".jsonl":
+		return FormatNDJSON
+	default:
+		return FormatCSV
+	}
+}
+
+// Encoder writes transaction records one at a time, in the shape of GET /api/v2/txs. In CSV, the first row is the
+// header with the JSON field names. Close must be called once all records are written.
+type Encoder struct {
+	format Format
+	w      io.Writer
+	csv    *csv.Writer
+	count  int
+}
+
+func NewEncoder(w io.Writer, format Format) (*Encoder, error) {
+	e := &Encoder{format: format, w: w}
+
+	switch format {
+	case FormatJSON, FormatNDJSON:
+	case FormatCSV:
+		e.csv = csv.NewWriter(w)
+		if err := e.csv.Write(csvHeader); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedFormat, format)
+	}
+
+	return e, nil
+}
+
+func (e *Encoder) Encode(record api.TxRecordResponse) error {
+	defer func() { e.count++ }()
+
+	if e.format == FormatCSV {
+		return e.csv.Write(csvRow(record))
+	}
+
+	b, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+
+	prefix := ""
+	if e.format == FormatJSON {
+		prefix = ",\n"
+		if e.count == 0 {
+			prefix = "[\n"
+		}
+	}
+	_, err = fmt.Fprintf(e.w, "%s%s", prefix, b)
+	if err == nil && e.format == FormatNDJSON {
+		_, err = io.WriteString(e.w, "\n")
+	}
+	return err
+}
+
+// Close ends the JSON array, or flushes the CSV rows.
+func (e *Encoder) Close() error {
+	switch e.format {
+	case FormatCSV:
+		e.csv.Flush()
+		return e.csv.Error()
+	case FormatJSON:
+		end := "\n]\n"
+		if e.count == 0 {
+			end = "[]\n"
+		}
+		_, err := io.WriteString(e.w, end)
+		return err
+	}
+	return nil
+}
+
+func csvRow(r api.TxRecordResponse) []string {
+	return []string{
+		r.Hash, r.Address, r.Direction, r.From, r.To,
+		strconv.FormatUint(uint64(r.Type), 10), strconv.FormatUint(r.Nonce, 10), r.Input,
+		r.Value, r.ValueEther, strconv.FormatUint(r.Gas, 10), strconv.FormatUint(r.GasUsed, 10),
+		r.EffectiveGasPrice, r.Fee, r.FeeEther,
+		strconv.FormatUint(r.BlockNumber, 10), r.BlockHash, strconv.FormatUint(r.Timestamp, 10),
+		strconv.FormatUint(uint64(r.TxIndex), 10), r.Status,
+	}
+}
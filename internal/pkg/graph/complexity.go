@@ -0,0 +1,52 @@
+package graph
+
+import (
+	"github.com/vuquang23/trustme/internal/pkg/graph/generated"
+	"github.com/vuquang23/trustme/internal/pkg/graph/model"
+)
+
+// Estimated costs of the fields hitting more than the repositories, the other fields cost 1 plus their children.
+const (
+	// rpcCost is the cost of a field calling the RPC node.
+	rpcCost = 10
+	// scanCostDivisor turns GraphQL.MaxCounterpartyScan into the cost of scanning the history of an address.
+	scanCostDivisor = 100
+)
+
+func newComplexity(cfg Config) generated.ComplexityRoot {
+	var c generated.ComplexityRoot
+
+	c.Query.Subscriptions = func(childComplexity int, first *int, _ *string) int {
+		return connectionCost(childComplexity, first, defaultPageLimit)
+	}
+	c.Query.Transactions = func(childComplexity int, _ string, _ *model.TxFilter, _ *model.Order, first *int, _ *string) int {
+		return connectionCost(childComplexity, first, defaultPageLimit)
+	}
+	c.Query.Transaction = func(childComplexity int, _ string) int {
+		return rpcCost + childComplexity
+	}
+	c.AddressSubscription.Transactions = func(childComplexity int, _ *model.TxFilter, _ *model.Order, first *int, _ *string) int {
+		return connectionCost(childComplexity, first, defaultPageLimit)
+	}
+	c.Transaction.Transfers = func(childComplexity int) int {
+		return rpcCost + childComplexity
+	}
+	c.TransactionDetail.Transfers = func(childComplexity int) int {
+		return 1 + childComplexity
+	}
+	c.AddressSummary.Counterparties = func(childComplexity int, first *int) int {
+		return cfg.MaxCounterpartyScan/scanCostDivisor + connectionCost(childComplexity, first, defaultCounterparties)
+	}
+
+	return c
+}
+
+// connectionCost counts the children once per requested item, defaultLimit items when first is omitted.
+func connectionCost(childComplexity int, first *int, defaultLimit int) int {
+	limit := defaultLimit
+	if first != nil {
+		// an invalid first is rejected by the resolver
+		limit = max(*first, 1)
+	}
+	return 1 + limit*childComplexity
+}
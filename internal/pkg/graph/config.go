@@ -0,0 +1,12 @@
+package graph
+
+type Config struct {
+	// Enabled serves the GraphQL endpoint at /api/graphql.
+	Enabled bool `default:"true"`
+	// Playground serves the GraphiQL playground at /api/graphql/playground.
+	Playground bool `default:"false"`
+	// MaxComplexity rejects queries whose estimated cost is higher, see complexity.go.
+	MaxComplexity int `default:"1000"`
+	// MaxCounterpartyScan is the number of latest transactions the counterparties of an address are computed from.
+	MaxCounterpartyScan int `default:"1000"`
+}
@@ -0,0 +1,45 @@
+package graph
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+
+	"github.com/vuquang23/trustme/internal/pkg/api"
+	"github.com/vuquang23/trustme/pkg/logger"
+)
+
+// presentError adds the code of the HTTP API to the extensions of resolver errors, so both APIs report
+// the same errors. Messages of server errors are not leaked.
+func presentError(ctx context.Context, err error) *gqlerror.Error {
+	gqlErr := graphql.DefaultErrorPresenter(ctx, err)
+
+	domainErr := gqlErr.Err
+	if domainErr == nil {
+		return gqlErr
+	}
+
+	if errors.Is(domainErr, context.Canceled) {
+		gqlErr.Message = "request was canceled"
+		gqlErr.Extensions = map[string]interface{}{"code": 4990}
+		return gqlErr
+	}
+
+	response := api.ResponseFromError(domainErr)
+	if response.HTTPStatus >= http.StatusInternalServerError {
+		logger.WithFields(ctx, logger.Fields{"error": domainErr}).Warn("graphql resolver failure")
+		gqlErr.Message = response.Message
+	}
+	gqlErr.Extensions = map[string]interface{}{"code": response.Code}
+
+	return gqlErr
+}
+
+func recoverPanic(ctx context.Context, err interface{}) error {
+	logger.WithFields(ctx, logger.Fields{"panic": err}).Error("graphql resolver panic")
+	return fmt.Errorf("panic: %v", err)
+}
@@ -0,0 +1,58 @@
+package graph
+
+import (
+	"github.com/99designs/gqlgen/graphql/handler"
+	"github.com/99designs/gqlgen/graphql/handler/extension"
+	"github.com/99designs/gqlgen/graphql/handler/lru"
+	"github.com/99designs/gqlgen/graphql/handler/transport"
+	"github.com/99designs/gqlgen/graphql/playground"
+	"github.com/gin-gonic/gin"
+
+	"github.com/vuquang23/trustme/internal/pkg/graph/generated"
+)
+
+//go:generate go run github.com/99designs/gqlgen@v0.17.45 generate
+
+const (
+	endpoint       = "/api/graphql"
+	queryCacheSize = 1000
+)
+
+// SetupRoute serves the GraphQL endpoint, over GET and POST, next to the HTTP API.
+func SetupRoute(engine *gin.Engine, cfg Config, enforceChecksum bool, tenants ITenants) {
+	if !cfg.Enabled {
+		return
+	}
+
+	h := gin.WrapH(NewHandler(cfg, enforceChecksum, tenants))
+	engine.GET(endpoint, h)
+	engine.POST(endpoint, h)
+
+	if cfg.Playground {
+		engine.GET(endpoint+"/playground", gin.WrapH(playground.Handler("trustme", endpoint)))
+	}
+}
+
+func NewHandler(cfg Config, enforceChecksum bool, tenants ITenants) *handler.Server {
+	srv := handler.New(generated.NewExecutableSchema(generated.Config{
+		Resolvers: &Resolver{
+			cfg:             cfg,
+			enforceChecksum: enforceChecksum,
+			tenants:         tenants,
+		},
+		Complexity: newComplexity(cfg),
+	}))
+
+	srv.AddTransport(transport.Options{})
+	srv.AddTransport(transport.GET{})
+	srv.AddTransport(transport.POST{})
+	srv.SetQueryCache(lru.New(queryCacheSize))
+
+	srv.Use(extension.Introspection{})
+	srv.Use(extension.FixedComplexityLimit(cfg.MaxComplexity))
+
+	srv.SetErrorPresenter(presentError)
+	srv.SetRecoverFunc(recoverPanic)
+
+	return srv
+}
@@ -0,0 +1,12 @@
+package graph
+
+import (
+	"context"
+
+	"github.com/vuquang23/trustme/internal/pkg/tenant"
+)
+
+type ITenants interface {
+	// the parser as seen by the tenant of the request, unrestricted without one
+	Parser(ctx context.Context) *tenant.Parser
+}
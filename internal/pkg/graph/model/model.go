@@ -0,0 +1,193 @@
+package model
+
+import (
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+
+	"github.com/vuquang23/trustme/internal/pkg/entity"
+	"github.com/vuquang23/trustme/internal/pkg/util/ether"
+)
+
+// The types below wrap the entities, gqlgen binds the schema fields to their methods.
+// Fields needing the repositories or the RPC are resolved by the graph package.
+
+type Block struct {
+	Number    int
+	Hash      *string
+	Timestamp *int
+}
+
+func NewBlock(number uint64, hash common.Hash, timestamp uint64) *Block {
+	h := hash.Hex()
+	t := int(timestamp)
+	return &Block{Number: int(number), Hash: &h, Timestamp: &t}
+}
+
+type AddressSubscription struct {
+	Subscription *entity.Subscription
+}
+
+func (s *AddressSubscription) Address() string {
+	return common.HexToAddress(s.Subscription.Address).Hex()
+}
+func (s *AddressSubscription) Label() string { return s.Subscription.Label }
+func (s *AddressSubscription) Owner() string { return s.Subscription.Owner }
+func (s *AddressSubscription) Notes() string { return s.Subscription.Notes }
+
+func (s *AddressSubscription) Tags() []string {
+	if s.Subscription.Tags == nil {
+		return []string{}
+	}
+	return s.Subscription.Tags
+}
+
+func (s *AddressSubscription) CreatedAt() time.Time { return s.Subscription.CreatedAt }
+
+type Transaction struct {
+	Record *entity.TxRecord
+}
+
+func (t *Transaction) Hash() string    { return t.Record.Hash().Hex() }
+func (t *Transaction) Address() string { return common.HexToAddress(t.Record.Address).Hex() }
+func (t *Transaction) Direction() Direction {
+	return Direction(strings.ToUpper(string(t.Record.Direction)))
+}
+func (t *Transaction) From() string       { return t.Record.From.Hex() }
+func (t *Transaction) To() *string        { return addressHex(t.Record.Tx.To()) }
+func (t *Transaction) Type() int          { return int(t.Record.Tx.Type()) }
+func (t *Transaction) Nonce() int         { return int(t.Record.Tx.Nonce()) }
+func (t *Transaction) Input() string      { return hexutil.Encode(t.Record.Tx.Data()) }
+func (t *Transaction) Value() string      { return t.Record.Tx.Value().String() }
+func (t *Transaction) ValueEther() string { return ether.FormatWei(t.Record.Tx.Value()) }
+func (t *Transaction) Gas() int           { return int(t.Record.Tx.Gas()) }
+func (t *Transaction) GasUsed() int       { return int(t.Record.GasUsed) }
+func (t *Transaction) Fee() string        { return t.Record.Fee().String() }
+func (t *Transaction) FeeEther() string   { return ether.FormatWei(t.Record.Fee()) }
+func (t *Transaction) TxIndex() int       { return int(t.Record.TxIndex) }
+func (t *Transaction) Status() TxStatus   { return TxStatus(strings.ToUpper(string(t.Record.Status))) }
+func (t *Transaction) Cursor() string {
+	return entity.EncodeTxCursor(t.Record.BlockNumber, t.Record.TxIndex)
+}
+func (t *Transaction) Block() *Block {
+	return NewBlock(t.Record.BlockNumber, t.Record.BlockHash, t.Record.Timestamp)
+}
+
+// Counterparty is null for contract creations.
+func (t *Transaction) Counterparty() *string {
+	counterparty := t.Record.Counterparty()
+	if counterparty == (common.Address{}) {
+		return nil
+	}
+	return ptr(counterparty.Hex())
+}
+
+func (t *Transaction) EffectiveGasPrice() *string {
+	if t.Record.EffectiveGasPrice == nil {
+		return nil
+	}
+	return ptr(t.Record.EffectiveGasPrice.String())
+}
+
+type TokenTransfer struct {
+	Transfer *entity.TokenTransfer
+}
+
+func (t *TokenTransfer) Token() string  { return t.Transfer.Token.Hex() }
+func (t *TokenTransfer) From() string   { return t.Transfer.From.Hex() }
+func (t *TokenTransfer) To() string     { return t.Transfer.To.Hex() }
+func (t *TokenTransfer) TxHash() string { return t.Transfer.TxHash.Hex() }
+func (t *TokenTransfer) LogIndex() int  { return int(t.Transfer.LogIndex) }
+func (t *TokenTransfer) TxIndex() int   { return int(t.Transfer.TxIndex) }
+func (t *TokenTransfer) Block() *Block {
+	return &Block{Number: int(t.Transfer.BlockNumber), Hash: ptr(t.Transfer.BlockHash.Hex())}
+}
+
+func (t *TokenTransfer) Value() *string {
+	if t.Transfer.Value == nil {
+		return nil
+	}
+	return ptr(t.Transfer.Value.String())
+}
+
+func (t *TokenTransfer) TokenID() *string {
+	if t.Transfer.TokenID == nil {
+		return nil
+	}
+	return ptr(t.Transfer.TokenID.String())
+}
+
+func NewTokenTransfers(transfers []*entity.TokenTransfer) []*TokenTransfer {
+	result := make([]*TokenTransfer, 0, len(transfers))
+	for _, transfer := range transfers {
+		result = append(result, &TokenTransfer{Transfer: transfer})
+	}
+	return result
+}
+
+type TransactionDetail struct {
+	Detail *entity.TxDetail
+}
+
+func (t *TransactionDetail) Hash() string       { return t.Detail.Tx.Hash().Hex() }
+func (t *TransactionDetail) Indexed() bool      { return t.Detail.Indexed }
+func (t *TransactionDetail) Pending() bool      { return t.Detail.Pending }
+func (t *TransactionDetail) From() string       { return t.Detail.From.Hex() }
+func (t *TransactionDetail) To() *string        { return addressHex(t.Detail.Tx.To()) }
+func (t *TransactionDetail) Type() int          { return int(t.Detail.Tx.Type()) }
+func (t *TransactionDetail) Nonce() int         { return int(t.Detail.Tx.Nonce()) }
+func (t *TransactionDetail) Input() string      { return hexutil.Encode(t.Detail.Tx.Data()) }
+func (t *TransactionDetail) Gas() int           { return int(t.Detail.Tx.Gas()) }
+func (t *TransactionDetail) Value() string      { return t.Detail.Tx.Value().String() }
+func (t *TransactionDetail) ValueEther() string { return ether.FormatWei(t.Detail.Tx.Value()) }
+func (t *TransactionDetail) TxIndex() int       { return int(t.Detail.TxIndex) }
+
+func (t *TransactionDetail) Block() *Block {
+	if t.Detail.Pending {
+		return nil
+	}
+	return NewBlock(t.Detail.BlockNumber, t.Detail.BlockHash, t.Detail.Timestamp)
+}
+
+func (t *TransactionDetail) Status() *TxStatus {
+	if t.Detail.Receipt == nil {
+		return nil
+	}
+	status := TxStatus(strings.ToUpper(string(entity.ReceiptStatus(t.Detail.Receipt))))
+	return &status
+}
+
+func (t *TransactionDetail) Parties() []*TxParty {
+	parties := make([]*TxParty, 0, len(t.Detail.Records))
+	for _, record := range t.Detail.Records {
+		parties = append(parties, &TxParty{
+			Address:   common.HexToAddress(record.Address).Hex(),
+			Direction: Direction(strings.ToUpper(string(record.Direction))),
+		})
+	}
+	return parties
+}
+
+func (t *TransactionDetail) Transfers() []*TokenTransfer {
+	return NewTokenTransfers(t.Detail.Transfers)
+}
+
+// AddressSummary is resolved lazily from the repository key of the address.
+type AddressSummary struct {
+	Key string
+}
+
+func (s *AddressSummary) Address() string { return common.HexToAddress(s.Key).Hex() }
+
+func addressHex(address *common.Address) *string {
+	if address == nil {
+		return nil
+	}
+	return ptr(address.Hex())
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
@@ -0,0 +1,191 @@
+// Code generated by github.com/99designs/gqlgen, DO NOT EDIT.
+
+package model
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+)
+
+type Counterparty struct {
+	Address  string `json:"address"`
+	TxCount  int    `json:"txCount"`
+	InCount  int    `json:"inCount"`
+	OutCount int    `json:"outCount"`
+	LastSeen int    `json:"lastSeen"`
+}
+
+type PageInfo struct {
+	HasNextPage bool    `json:"hasNextPage"`
+	EndCursor   *string `json:"endCursor,omitempty"`
+}
+
+type Query struct {
+}
+
+type SubscriptionConnection struct {
+	Edges      []*SubscriptionEdge `json:"edges"`
+	PageInfo   *PageInfo           `json:"pageInfo"`
+	TotalCount int                 `json:"totalCount"`
+}
+
+type SubscriptionEdge struct {
+	Cursor string               `json:"cursor"`
+	Node   *AddressSubscription `json:"node"`
+}
+
+type TransactionConnection struct {
+	Edges    []*TransactionEdge `json:"edges"`
+	PageInfo *PageInfo          `json:"pageInfo"`
+	// Only set when it is cheap to compute.
+	TotalCount *int `json:"totalCount,omitempty"`
+}
+
+type TransactionEdge struct {
+	Cursor string       `json:"cursor"`
+	Node   *Transaction `json:"node"`
+}
+
+type TxFilter struct {
+	FromBlock    *int       `json:"fromBlock,omitempty"`
+	ToBlock      *int       `json:"toBlock,omitempty"`
+	FromTime     *int       `json:"fromTime,omitempty"`
+	ToTime       *int       `json:"toTime,omitempty"`
+	Direction    *Direction `json:"direction,omitempty"`
+	MinValue     *string    `json:"minValue,omitempty"`
+	MaxValue     *string    `json:"maxValue,omitempty"`
+	Status       *TxStatus  `json:"status,omitempty"`
+	Counterparty *string    `json:"counterparty,omitempty"`
+	Type         *int       `json:"type,omitempty"`
+}
+
+type TxParty struct {
+	Address   string    `json:"address"`
+	Direction Direction `json:"direction"`
+}
+
+type Direction string
+
+const (
+	DirectionIn   Direction = "IN"
+	DirectionOut  Direction = "OUT"
+	DirectionSelf Direction = "SELF"
+)
+
+var AllDirection = []Direction{
+	DirectionIn,
+	DirectionOut,
+	DirectionSelf,
+}
+
+func (e Direction) IsValid() bool {
+	switch e {
+	case DirectionIn, DirectionOut, DirectionSelf:
+		return true
+	}
+	return false
+}
+
+func (e Direction) String() string {
+	return string(e)
+}
+
+func (e *Direction) UnmarshalGQL(v interface{}) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = Direction(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid Direction", str)
+	}
+	return nil
+}
+
+func (e Direction) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+type Order string
+
+const (
+	OrderAsc  Order = "ASC"
+	OrderDesc Order = "DESC"
+)
+
+var AllOrder = []Order{
+	OrderAsc,
+	OrderDesc,
+}
+
+func (e Order) IsValid() bool {
+	switch e {
+	case OrderAsc, OrderDesc:
+		return true
+	}
+	return false
+}
+
+func (e Order) String() string {
+	return string(e)
+}
+
+func (e *Order) UnmarshalGQL(v interface{}) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = Order(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid Order", str)
+	}
+	return nil
+}
+
+func (e Order) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+type TxStatus string
+
+const (
+	TxStatusSuccess TxStatus = "SUCCESS"
+	TxStatusFailed  TxStatus = "FAILED"
+)
+
+var AllTxStatus = []TxStatus{
+	TxStatusSuccess,
+	TxStatusFailed,
+}
+
+func (e TxStatus) IsValid() bool {
+	switch e {
+	case TxStatusSuccess, TxStatusFailed:
+		return true
+	}
+	return false
+}
+
+func (e TxStatus) String() string {
+	return string(e)
+}
+
+func (e *TxStatus) UnmarshalGQL(v interface{}) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = TxStatus(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid TxStatus", str)
+	}
+	return nil
+}
+
+func (e TxStatus) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
@@ -0,0 +1,372 @@
+package graph
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+
+	"github.com/vuquang23/trustme/internal/pkg/entity"
+	"github.com/vuquang23/trustme/internal/pkg/graph/generated"
+	"github.com/vuquang23/trustme/internal/pkg/graph/model"
+)
+
+const (
+	defaultPageLimit      = 100
+	maxPageLimit          = 1000
+	maxCounterpartyLimit  = 100
+	defaultCounterparties = 10
+)
+
+// Resolver answers the queries of schema.graphqls from the parser, like the HTTP API does.
+type Resolver struct {
+	cfg             Config
+	enforceChecksum bool
+	tenants         ITenants
+}
+
+func (r *Resolver) Query() generated.QueryResolver { return &queryResolver{r} }
+
+func (r *Resolver) AddressSubscription() generated.AddressSubscriptionResolver {
+	return &addressSubscriptionResolver{r}
+}
+
+func (r *Resolver) AddressSummary() generated.AddressSummaryResolver {
+	return &addressSummaryResolver{r}
+}
+
+func (r *Resolver) Transaction() generated.TransactionResolver { return &transactionResolver{r} }
+
+type queryResolver struct{ *Resolver }
+
+func (r *queryResolver) CurrentBlock(ctx context.Context) (*model.Block, error) {
+	return &model.Block{Number: r.tenants.Parser(ctx).GetCurrentBlock()}, nil
+}
+
+func (r *queryResolver) Subscription(ctx context.Context, address string) (*model.AddressSubscription, error) {
+	key, err := entity.ParseAddressKey(address, r.enforceChecksum)
+	if err != nil {
+		return nil, err
+	}
+	return r.getSubscription(ctx, key)
+}
+
+func (r *queryResolver) Subscriptions(ctx context.Context, first *int, after *string) (*model.SubscriptionConnection, error) {
+	limit, err := pageLimit(first)
+	if err != nil {
+		return nil, err
+	}
+
+	var cursor string
+	if after != nil {
+		cursor = strings.ToLower(*after)
+	}
+
+	page, err := r.tenants.Parser(ctx).GetSubscriptions(cursor, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	connection := &model.SubscriptionConnection{
+		Edges:      make([]*model.SubscriptionEdge, 0, len(page.Subscriptions)),
+		PageInfo:   &model.PageInfo{HasNextPage: page.NextCursor != ""},
+		TotalCount: page.Total,
+	}
+	for _, subscription := range page.Subscriptions {
+		connection.Edges = append(connection.Edges, &model.SubscriptionEdge{
+			Cursor: subscription.Address,
+			Node:   &model.AddressSubscription{Subscription: subscription},
+		})
+	}
+	if len(connection.Edges) > 0 {
+		connection.PageInfo.EndCursor = &connection.Edges[len(connection.Edges)-1].Cursor
+	}
+	return connection, nil
+}
+
+func (r *queryResolver) Transactions(
+	ctx context.Context, address string, filter *model.TxFilter, order *model.Order, first *int, after *string,
+) (*model.TransactionConnection, error) {
+	key, err := entity.ParseAddressKey(address, r.enforceChecksum)
+	if err != nil {
+		return nil, err
+	}
+	return r.transactions(ctx, key, filter, order, first, after)
+}
+
+func (r *queryResolver) Transaction(ctx context.Context, hash string) (*model.TransactionDetail, error) {
+	b, err := hexutil.Decode(hash)
+	if err != nil || len(b) != common.HashLength {
+		return nil, entity.ErrInvalidTxHash
+	}
+
+	detail, err := r.tenants.Parser(ctx).GetTxDetail(ctx, common.BytesToHash(b))
+	if errors.Is(err, entity.ErrTxNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.TransactionDetail{Detail: detail}, nil
+}
+
+func (r *queryResolver) Address(_ context.Context, address string) (*model.AddressSummary, error) {
+	key, err := entity.ParseAddressKey(address, r.enforceChecksum)
+	if err != nil {
+		return nil, err
+	}
+	return &model.AddressSummary{Key: key}, nil
+}
+
+type addressSubscriptionResolver struct{ *Resolver }
+
+func (r *addressSubscriptionResolver) Transactions(
+	ctx context.Context, obj *model.AddressSubscription, filter *model.TxFilter, order *model.Order, first *int, after *string,
+) (*model.TransactionConnection, error) {
+	return r.transactions(ctx, obj.Subscription.Address, filter, order, first, after)
+}
+
+func (r *addressSubscriptionResolver) Summary(_ context.Context, obj *model.AddressSubscription) (*model.AddressSummary, error) {
+	return &model.AddressSummary{Key: obj.Subscription.Address}, nil
+}
+
+type addressSummaryResolver struct{ *Resolver }
+
+func (r *addressSummaryResolver) Subscribed(ctx context.Context, obj *model.AddressSummary) (bool, error) {
+	subscription, err := r.getSubscription(ctx, obj.Key)
+	if err != nil {
+		return false, err
+	}
+	return subscription != nil, nil
+}
+
+func (r *addressSummaryResolver) Subscription(ctx context.Context, obj *model.AddressSummary) (*model.AddressSubscription, error) {
+	return r.getSubscription(ctx, obj.Key)
+}
+
+func (r *addressSummaryResolver) TxCount(ctx context.Context, obj *model.AddressSummary) (*int, error) {
+	page, err := r.tenants.Parser(ctx).QueryTxRecords(obj.Key, entity.TxQuery{Limit: 1})
+	if err != nil {
+		return nil, err
+	}
+	return page.Total, nil
+}
+
+func (r *addressSummaryResolver) FirstTransaction(ctx context.Context, obj *model.AddressSummary) (*model.Transaction, error) {
+	return r.edgeTransaction(ctx, obj.Key, entity.SortOrderAsc)
+}
+
+func (r *addressSummaryResolver) LastTransaction(ctx context.Context, obj *model.AddressSummary) (*model.Transaction, error) {
+	return r.edgeTransaction(ctx, obj.Key, entity.SortOrderDesc)
+}
+
+// Counterparties ranks the counterparties of the latest GraphQL.MaxCounterpartyScan transactions by number of
+// transactions, then by last activity.
+func (r *addressSummaryResolver) Counterparties(ctx context.Context, obj *model.AddressSummary, first *int) ([]*model.Counterparty, error) {
+	limit := defaultCounterparties
+	if first != nil {
+		limit = *first
+	}
+	if limit < 1 || limit > maxCounterpartyLimit {
+		return nil, fmt.Errorf("%w: first must be between 1 and %d", entity.ErrInvalidParams, maxCounterpartyLimit)
+	}
+
+	page, err := r.tenants.Parser(ctx).QueryTxRecords(obj.Key, entity.TxQuery{
+		Order: entity.SortOrderDesc,
+		Limit: r.cfg.MaxCounterpartyScan,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	byAddress := make(map[common.Address]*model.Counterparty)
+	for _, record := range page.Records {
+		address := record.Counterparty()
+		if address == (common.Address{}) {
+			continue
+		}
+
+		counterparty, ok := byAddress[address]
+		if !ok {
+			// records come newest first
+			counterparty = &model.Counterparty{Address: address.Hex(), LastSeen: int(record.Timestamp)}
+			byAddress[address] = counterparty
+		}
+		counterparty.TxCount++
+		switch record.Direction {
+		case entity.TxDirectionIn:
+			counterparty.InCount++
+		case entity.TxDirectionOut:
+			counterparty.OutCount++
+		}
+	}
+
+	counterparties := make([]*model.Counterparty, 0, len(byAddress))
+	for _, counterparty := range byAddress {
+		counterparties = append(counterparties, counterparty)
+	}
+	sort.Slice(counterparties, func(i, j int) bool {
+		if counterparties[i].TxCount != counterparties[j].TxCount {
+			return counterparties[i].TxCount > counterparties[j].TxCount
+		}
+		if counterparties[i].LastSeen != counterparties[j].LastSeen {
+			return counterparties[i].LastSeen > counterparties[j].LastSeen
+		}
+		return counterparties[i].Address < counterparties[j].Address
+	})
+
+	if len(counterparties) > limit {
+		counterparties = counterparties[:limit]
+	}
+	return counterparties, nil
+}
+
+type transactionResolver struct{ *Resolver }
+
+func (r *transactionResolver) Transfers(ctx context.Context, obj *model.Transaction) ([]*model.TokenTransfer, error) {
+	transfers, err := r.tenants.Parser(ctx).GetTokenTransfers(ctx, obj.Record.Hash())
+	if err != nil {
+		return nil, err
+	}
+	return model.NewTokenTransfers(transfers), nil
+}
+
+// getSubscription returns nil when the address is not subscribed.
+func (r *Resolver) getSubscription(ctx context.Context, key string) (*model.AddressSubscription, error) {
+	subscription, err := r.tenants.Parser(ctx).GetSubscription(key)
+	if errors.Is(err, entity.ErrNotSubscribed) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &model.AddressSubscription{Subscription: subscription}, nil
+}
+
+func (r *Resolver) edgeTransaction(ctx context.Context, key string, order entity.SortOrder) (*model.Transaction, error) {
+	page, err := r.tenants.Parser(ctx).QueryTxRecords(key, entity.TxQuery{Order: order, Limit: 1})
+	if err != nil {
+		return nil, err
+	}
+	if len(page.Records) == 0 {
+		return nil, nil
+	}
+	return &model.Transaction{Record: page.Records[0]}, nil
+}
+
+func (r *Resolver) transactions(
+	ctx context.Context, key string, filter *model.TxFilter, order *model.Order, first *int, after *string,
+) (*model.TransactionConnection, error) {
+	query, err := r.txQuery(filter, order, first, after)
+	if err != nil {
+		return nil, err
+	}
+
+	page, err := r.tenants.Parser(ctx).QueryTxRecords(key, query)
+	if err != nil {
+		return nil, err
+	}
+
+	connection := &model.TransactionConnection{
+		Edges:      make([]*model.TransactionEdge, 0, len(page.Records)),
+		PageInfo:   &model.PageInfo{HasNextPage: page.NextCursor != ""},
+		TotalCount: page.Total,
+	}
+	for _, record := range page.Records {
+		node := &model.Transaction{Record: record}
+		connection.Edges = append(connection.Edges, &model.TransactionEdge{Cursor: node.Cursor(), Node: node})
+	}
+	if len(connection.Edges) > 0 {
+		connection.PageInfo.EndCursor = &connection.Edges[len(connection.Edges)-1].Cursor
+	}
+	return connection, nil
+}
+
+func (r *Resolver) txQuery(filter *model.TxFilter, order *model.Order, first *int, after *string) (entity.TxQuery, error) {
+	limit, err := pageLimit(first)
+	if err != nil {
+		return entity.TxQuery{}, err
+	}
+
+	params := entity.TxQueryParams{
+		Order: entity.SortOrderAsc,
+		Limit: limit,
+	}
+
+	if order != nil && *order == model.OrderDesc {
+		params.Order = entity.SortOrderDesc
+	}
+
+	if after != nil {
+		params.Cursor = *after
+	}
+
+	if filter == nil {
+		return entity.ParseTxQuery(params, r.enforceChecksum)
+	}
+
+	for _, bound := range []struct {
+		name  string
+		value *int
+		field **uint64
+	}{
+		{"fromBlock", filter.FromBlock, &params.FromBlock},
+		{"toBlock", filter.ToBlock, &params.ToBlock},
+		{"fromTime", filter.FromTime, &params.FromTime},
+		{"toTime", filter.ToTime, &params.ToTime},
+	} {
+		if bound.value == nil {
+			continue
+		}
+		if *bound.value < 0 {
+			return entity.TxQuery{}, fmt.Errorf("%w: invalid %s: %d", entity.ErrInvalidParams, bound.name, *bound.value)
+		}
+		v := uint64(*bound.value)
+		*bound.field = &v
+	}
+
+	if filter.Direction != nil {
+		params.Direction = strings.ToLower(string(*filter.Direction))
+	}
+
+	if filter.Status != nil {
+		params.Status = strings.ToLower(string(*filter.Status))
+	}
+
+	if filter.Type != nil {
+		if *filter.Type < 0 || *filter.Type > 0xff {
+			return entity.TxQuery{}, fmt.Errorf("%w: invalid type: %d", entity.ErrInvalidParams, *filter.Type)
+		}
+		txType := uint8(*filter.Type)
+		params.TxType = &txType
+	}
+
+	if filter.MinValue != nil {
+		params.MinValue = *filter.MinValue
+	}
+
+	if filter.MaxValue != nil {
+		params.MaxValue = *filter.MaxValue
+	}
+
+	if filter.Counterparty != nil {
+		params.Counterparty = *filter.Counterparty
+	}
+
+	return entity.ParseTxQuery(params, r.enforceChecksum)
+}
+
+func pageLimit(first *int) (int, error) {
+	if first == nil {
+		return defaultPageLimit, nil
+	}
+	if *first < 1 || *first > maxPageLimit {
+		return 0, fmt.Errorf("%w: first must be between 1 and %d", entity.ErrInvalidParams, maxPageLimit)
+	}
+	return *first, nil
+}
@@ -0,0 +1,59 @@
+package grpcserver
+
+import (
+	"context"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+
+	"github.com/vuquang23/trustme/internal/pkg/auth"
+)
+
+// metadataKeyAPIKey and metadataKeyAuthorization carry the credentials, like the X-API-Key and Authorization headers.
+const (
+	metadataKeyAPIKey        = "x-api-key"
+	metadataKeyAuthorization = "authorization"
+)
+
+// authenticate puts the principal of the request credentials in the context, like the gin middleware does.
+func authenticate(ctx context.Context, authenticator IAuthenticator) (context.Context, error) {
+	if !authenticator.Enabled() {
+		return ctx, nil
+	}
+
+	md, _ := metadata.FromIncomingContext(ctx)
+
+	var credential string
+	if values := md.Get(metadataKeyAPIKey); len(values) > 0 {
+		credential = values[0]
+	} else if values := md.Get(metadataKeyAuthorization); len(values) > 0 {
+		credential, _ = strings.CutPrefix(values[0], "Bearer ")
+	}
+
+	principal, err := authenticator.Authenticate(strings.TrimSpace(credential))
+	if err != nil {
+		return nil, statusError(err)
+	}
+	return auth.WithPrincipal(ctx, principal), nil
+}
+
+func AuthUnaryInterceptor(authenticator IAuthenticator) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		ctx, err := authenticate(ctx, authenticator)
+		if err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+func AuthStreamInterceptor(authenticator IAuthenticator) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, _ *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, err := authenticate(ss.Context(), authenticator)
+		if err != nil {
+			return err
+		}
+		return handler(srv, &serverStream{ServerStream: ss, ctx: ctx})
+	}
+}
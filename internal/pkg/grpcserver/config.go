@@ -0,0 +1,10 @@
+package grpcserver
+
+type Config struct {
+	// BindAddress is the address of the gRPC listener, empty disables the gRPC API.
+	BindAddress string `default:":9090"`
+	// MaxWatchAddresses is the maximum number of addresses of a Watch call.
+	MaxWatchAddresses int `default:"100"`
+	// WatchBufferSize is the number of events queued per Watch call before a slow client is aborted.
+	WatchBufferSize int `default:"256"`
+}
@@ -0,0 +1,154 @@
+package grpcserver
+
+import (
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+
+	"github.com/vuquang23/trustme/internal/pkg/entity"
+	pb "github.com/vuquang23/trustme/pkg/pb/trustme/v1"
+)
+
+func newSubscription(subscription *entity.Subscription) *pb.Subscription {
+	return &pb.Subscription{
+		Address:   common.HexToAddress(subscription.Address).Hex(),
+		Label:     subscription.Label,
+		Tags:      subscription.Tags,
+		Owner:     subscription.Owner,
+		Notes:     subscription.Notes,
+		CreatedAt: subscription.CreatedAt.Unix(),
+	}
+}
+
+func newTransaction(record *entity.TxRecord) *pb.Transaction {
+	tx := &pb.Transaction{
+		Hash:        record.Hash().Hex(),
+		Address:     common.HexToAddress(record.Address).Hex(),
+		Direction:   string(record.Direction),
+		From:        record.From.Hex(),
+		Type:        uint32(record.Tx.Type()),
+		Nonce:       record.Tx.Nonce(),
+		Input:       hexutil.Encode(record.Tx.Data()),
+		Value:       record.Tx.Value().String(),
+		Gas:         record.Tx.Gas(),
+		GasUsed:     record.GasUsed,
+		Fee:         record.Fee().String(),
+		BlockNumber: record.BlockNumber,
+		BlockHash:   record.BlockHash.Hex(),
+		Timestamp:   record.Timestamp,
+		TxIndex:     uint64(record.TxIndex),
+		Status:      string(record.Status),
+	}
+	if record.Tx.To() != nil {
+		tx.To = record.Tx.To().Hex()
+	}
+	if record.EffectiveGasPrice != nil {
+		tx.EffectiveGasPrice = record.EffectiveGasPrice.String()
+	}
+	return tx
+}
+
+func newTransactions(records []*entity.TxRecord) []*pb.Transaction {
+	txs := make([]*pb.Transaction, 0, len(records))
+	for _, record := range records {
+		txs = append(txs, newTransaction(record))
+	}
+	return txs
+}
+
+func newGetTransactionResponse(detail *entity.TxDetail) *pb.GetTransactionResponse {
+	response := &pb.GetTransactionResponse{
+		Hash:        detail.Tx.Hash().Hex(),
+		Indexed:     detail.Indexed,
+		Pending:     detail.Pending,
+		From:        detail.From.Hex(),
+		Type:        uint32(detail.Tx.Type()),
+		Nonce:       detail.Tx.Nonce(),
+		Input:       hexutil.Encode(detail.Tx.Data()),
+		Gas:         detail.Tx.Gas(),
+		Value:       detail.Tx.Value().String(),
+		BlockNumber: detail.BlockNumber,
+		Timestamp:   detail.Timestamp,
+		TxIndex:     uint64(detail.TxIndex),
+	}
+	if detail.Tx.To() != nil {
+		response.To = detail.Tx.To().Hex()
+	}
+	if detail.BlockHash != (common.Hash{}) {
+		response.BlockHash = detail.BlockHash.Hex()
+	}
+
+	for _, record := range detail.Records {
+		response.Parties = append(response.Parties, &pb.TxParty{
+			Address:   common.HexToAddress(record.Address).Hex(),
+			Direction: string(record.Direction),
+		})
+	}
+
+	if receipt := detail.Receipt; receipt != nil {
+		response.Receipt = &pb.Receipt{
+			Status:            string(entity.ReceiptStatus(receipt)),
+			GasUsed:           receipt.GasUsed,
+			CumulativeGasUsed: receipt.CumulativeGasUsed,
+			Fee:               entity.ReceiptFee(receipt).String(),
+		}
+		if receipt.EffectiveGasPrice != nil {
+			response.Receipt.EffectiveGasPrice = receipt.EffectiveGasPrice.String()
+		}
+		if receipt.ContractAddress != (common.Address{}) {
+			response.Receipt.ContractAddress = receipt.ContractAddress.Hex()
+		}
+
+		for _, log := range detail.Logs {
+			topics := make([]string, 0, len(log.Log.Topics))
+			for _, topic := range log.Log.Topics {
+				topics = append(topics, topic.Hex())
+			}
+
+			response.Receipt.Logs = append(response.Receipt.Logs, &pb.Log{
+				LogIndex: uint64(log.Log.Index),
+				Address:  log.Log.Address.Hex(),
+				Topics:   topics,
+				Data:     hexutil.Encode(log.Log.Data),
+				Event:    log.Event,
+				Args:     log.Args,
+			})
+		}
+	}
+
+	return response
+}
+
+func newTokenTransfer(transfer *entity.TokenTransfer) *pb.TokenTransfer {
+	response := &pb.TokenTransfer{
+		Token:       transfer.Token.Hex(),
+		From:        transfer.From.Hex(),
+		To:          transfer.To.Hex(),
+		TxHash:      transfer.TxHash.Hex(),
+		LogIndex:    uint64(transfer.LogIndex),
+		BlockNumber: transfer.BlockNumber,
+		BlockHash:   transfer.BlockHash.Hex(),
+		TxIndex:     uint64(transfer.TxIndex),
+	}
+	if transfer.Value != nil {
+		response.Value = transfer.Value.String()
+	}
+	if transfer.TokenID != nil {
+		response.TokenId = transfer.TokenID.String()
+	}
+	return response
+}
+
+func newEvent(event entity.Event) *pb.Event {
+	response := &pb.Event{
+		Type:          string(event.Type),
+		Address:       common.HexToAddress(event.Address).Hex(),
+		Confirmations: event.Confirmations,
+	}
+	if event.Record != nil {
+		response.Transaction = newTransaction(event.Record)
+	}
+	if event.Transfer != nil {
+		response.Transfer = newTokenTransfer(event.Transfer)
+	}
+	return response
+}
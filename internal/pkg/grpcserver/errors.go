@@ -0,0 +1,57 @@
+package grpcserver
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/vuquang23/trustme/internal/pkg/api"
+)
+
+const errorDomain = "trustme"
+
+var codeByHTTPStatus = map[int]codes.Code{
+	http.StatusBadRequest:          codes.InvalidArgument,
+	http.StatusUnauthorized:        codes.Unauthenticated,
+	http.StatusForbidden:           codes.PermissionDenied,
+	http.StatusNotFound:            codes.NotFound,
+	http.StatusConflict:            codes.AlreadyExists,
+	http.StatusTooManyRequests:     codes.ResourceExhausted,
+	http.StatusServiceUnavailable:  codes.Unavailable,
+	http.StatusInternalServerError: codes.Internal,
+}
+
+// statusError converts a domain error to a gRPC status carrying the code of the HTTP API, so both APIs
+// report the same errors.
+func statusError(err error) error {
+	if errors.Is(err, context.Canceled) {
+		return status.Error(codes.Canceled, err.Error())
+	}
+
+	response := api.ResponseFromError(err)
+
+	code, ok := codeByHTTPStatus[response.HTTPStatus]
+	if !ok {
+		code = codes.Unknown
+	}
+
+	message := response.Message
+	if response.HTTPStatus < http.StatusInternalServerError && err.Error() != message {
+		message = err.Error()
+	}
+
+	st, detailErr := status.New(code, message).WithDetails(&errdetails.ErrorInfo{
+		Reason:   response.Message,
+		Domain:   errorDomain,
+		Metadata: map[string]string{"code": strconv.Itoa(response.Code)},
+	})
+	if detailErr != nil {
+		return status.Error(code, message)
+	}
+	return st.Err()
+}
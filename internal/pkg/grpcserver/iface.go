@@ -0,0 +1,31 @@
+package grpcserver
+
+import (
+	"context"
+	"time"
+
+	"github.com/vuquang23/trustme/internal/pkg/entity"
+	"github.com/vuquang23/trustme/internal/pkg/eventbus"
+	"github.com/vuquang23/trustme/internal/pkg/tenant"
+)
+
+type ITenants interface {
+	// the parser as seen by the tenant of the request, unrestricted without one
+	Parser(ctx context.Context) *tenant.Parser
+}
+
+type IAuthenticator interface {
+	// whether requests need credentials
+	Enabled() bool
+	// principal of an API key or a JWT
+	Authenticate(credential string) (*entity.Principal, error)
+}
+
+type IRateLimiter interface {
+	// take a token of the client for the route group of a method, or tell how long to wait for one
+	Allow(client, path string) (bool, time.Duration)
+}
+
+type IEventBus interface {
+	Subscribe(buffer int, filter func(entity.Event) bool) *eventbus.Subscription
+}
@@ -0,0 +1,128 @@
+package grpcserver
+
+import (
+	"context"
+	"fmt"
+	"runtime/debug"
+	"time"
+
+	"github.com/google/uuid"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/vuquang23/trustme/internal/pkg/util/requestid"
+	"github.com/vuquang23/trustme/pkg/logger"
+)
+
+// metadataKeyRequestID is the request ID header, gRPC metadata keys being lowercase.
+const metadataKeyRequestID = "x-request-id"
+
+// withRequest takes the request ID from the metadata or generates one, sends it back in the response
+// headers and puts it with a request logger in the context, like the gin middlewares do.
+func withRequest(ctx context.Context, fullMethod string) (context.Context, logger.Logger, string) {
+	md, _ := metadata.FromIncomingContext(ctx)
+
+	var requestID string
+	if values := md.Get(metadataKeyRequestID); len(values) > 0 && values[0] != "" {
+		requestID = values[0]
+	} else {
+		requestID = uuid.NewString()
+	}
+	_ = grpc.SetHeader(ctx, metadata.Pairs(metadataKeyRequestID, requestID))
+
+	var clientIP string
+	if p, ok := peer.FromContext(ctx); ok {
+		clientIP = p.Addr.String()
+	}
+
+	var userAgent string
+	if values := md.Get("user-agent"); len(values) > 0 {
+		userAgent = values[0]
+	}
+
+	reqLogger := logger.WithFieldsNonContext(logger.Fields{"request.id": requestID})
+	reqLogger.WithFields(logger.Fields{
+		"request.method":     fullMethod,
+		"request.client_ip":  clientIP,
+		"request.user_agent": userAgent,
+	}).Info("inbound request")
+
+	ctx = requestid.SetRequestIDToContext(ctx, requestID)
+	ctx = context.WithValue(ctx, logger.CtxLoggerKey, reqLogger) //nolint:staticcheck // key shared with gin.Context.Set
+	return ctx, reqLogger, requestID
+}
+
+func logResponse(reqLogger logger.Logger, fields logger.Fields, startTime time.Time, err error) {
+	fields["response.status"] = status.Code(err).String()
+	fields["response.duration_ms"] = time.Since(startTime).Milliseconds()
+	if err != nil {
+		fields["response.error"] = err.Error()
+	}
+	reqLogger.WithFields(fields).Info("inbound response")
+}
+
+func recovered(ctx context.Context, r interface{}) error {
+	logger.Errorf(ctx, "panic: %v\n%s", r, debug.Stack())
+	return status.Error(codes.Internal, fmt.Sprintf("panic: %v", r))
+}
+
+func UnaryInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp interface{}, err error) {
+	startTime := time.Now()
+	ctx, reqLogger, _ := withRequest(ctx, info.FullMethod)
+
+	defer func() {
+		if r := recover(); r != nil {
+			err = recovered(ctx, r)
+		}
+
+		fields := logger.Fields{
+			"request.body": marshalMessage(req),
+		}
+		if err == nil {
+			fields["response.body"] = marshalMessage(resp)
+		}
+		logResponse(reqLogger, fields, startTime, err)
+	}()
+
+	return handler(ctx, req)
+}
+
+func StreamInterceptor(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) (err error) {
+	startTime := time.Now()
+	ctx, reqLogger, _ := withRequest(ss.Context(), info.FullMethod)
+
+	defer func() {
+		if r := recover(); r != nil {
+			err = recovered(ctx, r)
+		}
+		logResponse(reqLogger, logger.Fields{}, startTime, err)
+	}()
+
+	return handler(srv, &serverStream{ServerStream: ss, ctx: ctx})
+}
+
+type serverStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *serverStream) Context() context.Context {
+	return s.ctx
+}
+
+func marshalMessage(v interface{}) string {
+	m, ok := v.(proto.Message)
+	if !ok {
+		return ""
+	}
+	b, err := protojson.Marshal(m)
+	if err != nil {
+		return ""
+	}
+	return string(b)
+}
@@ -0,0 +1,60 @@
+package grpcserver
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net"
+	"strconv"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+
+	"github.com/vuquang23/trustme/internal/pkg/api"
+	"github.com/vuquang23/trustme/internal/pkg/entity"
+)
+
+const metadataKeyRetryAfter = "retry-after"
+
+// allow applies the rate limit of the client to a method, returning the retry-after header to send when refused.
+func allow(ctx context.Context, limiter IRateLimiter, method string) (metadata.MD, error) {
+	var ip string
+	if p, ok := peer.FromContext(ctx); ok {
+		ip = p.Addr.String()
+		if host, _, err := net.SplitHostPort(ip); err == nil {
+			ip = host
+		}
+	}
+
+	ok, retryAfter := limiter.Allow(api.RateLimitClient(ctx, ip), method)
+	if ok {
+		return nil, nil
+	}
+
+	seconds := int(math.Ceil(retryAfter.Seconds()))
+	header := metadata.Pairs(metadataKeyRetryAfter, strconv.Itoa(seconds))
+	return header, statusError(fmt.Errorf("%w: retry in %ds", entity.ErrRateLimited, seconds))
+}
+
+func RateLimitUnaryInterceptor(limiter IRateLimiter) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		header, err := allow(ctx, limiter, info.FullMethod)
+		if err != nil {
+			_ = grpc.SetHeader(ctx, header)
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+func RateLimitStreamInterceptor(limiter IRateLimiter) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		header, err := allow(ss.Context(), limiter, info.FullMethod)
+		if err != nil {
+			_ = ss.SetHeader(header)
+			return err
+		}
+		return handler(srv, ss)
+	}
+}
@@ -0,0 +1,57 @@
+package grpcserver
+
+import (
+	"context"
+	"net"
+	"time"
+
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/reflection"
+
+	"github.com/vuquang23/trustme/pkg/logger"
+	pb "github.com/vuquang23/trustme/pkg/pb/trustme/v1"
+)
+
+const shutdownTimeout = 5 * time.Second
+
+// New builds the gRPC server with tracing, and the request ID, logging, recovery, authentication and rate limiting
+// interceptors.
+func New(server *Server, authenticator IAuthenticator, limiter IRateLimiter) *grpc.Server {
+	srv := grpc.NewServer(
+		grpc.StatsHandler(otelgrpc.NewServerHandler()),
+		grpc.ChainUnaryInterceptor(UnaryInterceptor, AuthUnaryInterceptor(authenticator), RateLimitUnaryInterceptor(limiter)),
+		grpc.ChainStreamInterceptor(StreamInterceptor, AuthStreamInterceptor(authenticator), RateLimitStreamInterceptor(limiter)),
+	)
+	pb.RegisterTrustmeServiceServer(srv, server)
+	reflection.Register(srv)
+	return srv
+}
+
+func Run(ctx context.Context, address string, srv *grpc.Server) error {
+	lis, err := net.Listen("tcp", address)
+	if err != nil {
+		return err
+	}
+
+	go func() {
+		if err := srv.Serve(lis); err != nil && err != grpc.ErrServerStopped {
+			logger.Error(ctx, err.Error())
+		}
+	}()
+	<-ctx.Done()
+	logger.Info(ctx, "gRPC server shutdown")
+
+	// Watch streams only end with the server, do not wait for them for long.
+	stopped := make(chan struct{})
+	go func() {
+		srv.GracefulStop()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+	case <-time.After(shutdownTimeout):
+		srv.Stop()
+	}
+	return nil
+}
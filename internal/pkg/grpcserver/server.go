@@ -0,0 +1,245 @@
+package grpcserver
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/vuquang23/trustme/internal/pkg/entity"
+	pb "github.com/vuquang23/trustme/pkg/pb/trustme/v1"
+)
+
+const (
+	defaultPageLimit = 100
+	maxPageLimit     = 1000
+)
+
+// Server implements the TrustmeService of proto/trustme/v1 over the parser, like the HTTP API does.
+type Server struct {
+	pb.UnimplementedTrustmeServiceServer
+
+	cfg             Config
+	enforceChecksum bool
+	tenants         ITenants
+	bus             IEventBus
+}
+
+func NewServer(cfg Config, enforceChecksum bool, tenants ITenants, bus IEventBus) *Server {
+	return &Server{
+		cfg:             cfg,
+		enforceChecksum: enforceChecksum,
+		tenants:         tenants,
+		bus:             bus,
+	}
+}
+
+func (s *Server) GetCurrentBlock(ctx context.Context, _ *pb.GetCurrentBlockRequest) (*pb.GetCurrentBlockResponse, error) {
+	return &pb.GetCurrentBlockResponse{BlockNumber: int64(s.tenants.Parser(ctx).GetCurrentBlock())}, nil
+}
+
+func (s *Server) Subscribe(ctx context.Context, req *pb.SubscribeRequest) (*pb.SubscribeResponse, error) {
+	address, err := entity.ParseAddressKey(req.GetAddress(), s.enforceChecksum)
+	if err != nil {
+		return nil, statusError(err)
+	}
+
+	err = s.tenants.Parser(ctx).AddSubscription(&entity.Subscription{
+		Address: address,
+		Label:   req.GetLabel(),
+		Tags:    req.GetTags(),
+		Owner:   req.GetOwner(),
+		Notes:   req.GetNotes(),
+	})
+	if err != nil {
+		return nil, statusError(err)
+	}
+
+	return &pb.SubscribeResponse{}, nil
+}
+
+func (s *Server) Unsubscribe(ctx context.Context, req *pb.UnsubscribeRequest) (*pb.UnsubscribeResponse, error) {
+	address, err := entity.ParseAddressKey(req.GetAddress(), s.enforceChecksum)
+	if err != nil {
+		return nil, statusError(err)
+	}
+
+	if err := s.tenants.Parser(ctx).Unsubscribe(address); err != nil {
+		return nil, statusError(err)
+	}
+
+	return &pb.UnsubscribeResponse{}, nil
+}
+
+func (s *Server) ListSubscriptions(ctx context.Context, req *pb.ListSubscriptionsRequest) (*pb.ListSubscriptionsResponse, error) {
+	limit, err := pageLimit(req.GetLimit())
+	if err != nil {
+		return nil, statusError(err)
+	}
+
+	page, err := s.tenants.Parser(ctx).GetSubscriptions(strings.ToLower(req.GetCursor()), limit)
+	if err != nil {
+		return nil, statusError(err)
+	}
+
+	response := &pb.ListSubscriptionsResponse{
+		NextCursor: page.NextCursor,
+		Total:      int64(page.Total),
+	}
+	for _, subscription := range page.Subscriptions {
+		response.Subscriptions = append(response.Subscriptions, newSubscription(subscription))
+	}
+	return response, nil
+}
+
+func (s *Server) ListTransactions(ctx context.Context, req *pb.ListTransactionsRequest) (*pb.ListTransactionsResponse, error) {
+	address, err := entity.ParseAddressKey(req.GetAddress(), s.enforceChecksum)
+	if err != nil {
+		return nil, statusError(err)
+	}
+
+	query, err := s.txQuery(req)
+	if err != nil {
+		return nil, statusError(err)
+	}
+
+	page, err := s.tenants.Parser(ctx).QueryTxRecords(address, query)
+	if err != nil {
+		return nil, statusError(err)
+	}
+
+	response := &pb.ListTransactionsResponse{
+		Transactions: newTransactions(page.Records),
+		NextCursor:   page.NextCursor,
+	}
+	if page.Total != nil {
+		total := int64(*page.Total)
+		response.Total = &total
+	}
+	return response, nil
+}
+
+func (s *Server) txQuery(req *pb.ListTransactionsRequest) (entity.TxQuery, error) {
+	limit, err := pageLimit(req.GetLimit())
+	if err != nil {
+		return entity.TxQuery{}, err
+	}
+
+	params := entity.TxQueryParams{
+		FromBlock:    req.FromBlock,
+		ToBlock:      req.ToBlock,
+		FromTime:     req.FromTime,
+		ToTime:       req.ToTime,
+		Direction:    req.GetDirection(),
+		MinValue:     req.GetMinValue(),
+		MaxValue:     req.GetMaxValue(),
+		Status:       req.GetStatus(),
+		Counterparty: req.GetCounterparty(),
+		Cursor:       req.GetCursor(),
+		Limit:        limit,
+	}
+
+	switch req.GetOrder() {
+	case pb.Order_ORDER_DESC:
+		params.Order = entity.SortOrderDesc
+	case pb.Order_ORDER_ASC:
+		params.Order = entity.SortOrderAsc
+	}
+
+	if req.Type != nil {
+		if *req.Type > 0xff {
+			return entity.TxQuery{}, fmt.Errorf("%w: invalid type: %d", entity.ErrInvalidParams, *req.Type)
+		}
+		txType := uint8(*req.Type)
+		params.TxType = &txType
+	}
+
+	return entity.ParseTxQuery(params, s.enforceChecksum)
+}
+
+func (s *Server) GetTransaction(ctx context.Context, req *pb.GetTransactionRequest) (*pb.GetTransactionResponse, error) {
+	b, err := hexutil.Decode(req.GetHash())
+	if err != nil || len(b) != common.HashLength {
+		return nil, statusError(entity.ErrInvalidTxHash)
+	}
+
+	detail, err := s.tenants.Parser(ctx).GetTxDetail(ctx, common.BytesToHash(b))
+	if err != nil {
+		return nil, statusError(err)
+	}
+
+	return newGetTransactionResponse(detail), nil
+}
+
+// Watch streams the events of the requested addresses. Like the WebSocket API, a client falling
+// WatchBufferSize events behind is dropped.
+func (s *Server) Watch(req *pb.WatchRequest, stream pb.TrustmeService_WatchServer) error {
+	if len(req.GetAddresses()) == 0 || len(req.GetAddresses()) > s.cfg.MaxWatchAddresses {
+		return statusError(fmt.Errorf("%w: between 1 and %d addresses", entity.ErrInvalidParams, s.cfg.MaxWatchAddresses))
+	}
+
+	parser := s.tenants.Parser(stream.Context())
+	addresses := make(map[string]struct{}, len(req.GetAddresses()))
+	for _, a := range req.GetAddresses() {
+		address, err := entity.ParseAddressKey(a, s.enforceChecksum)
+		if err != nil {
+			return statusError(err)
+		}
+		if !parser.Allows(address) {
+			return statusError(fmt.Errorf("%w: %s", entity.ErrNotSubscribed, a))
+		}
+		addresses[address] = struct{}{}
+	}
+
+	events := make(map[entity.EventType]struct{}, len(entity.EventTypes))
+	for _, e := range req.GetEvents() {
+		event := entity.EventType(e)
+		if !entity.IsEventType(event) {
+			return statusError(fmt.Errorf("%w: unknown event: %s", entity.ErrInvalidParams, e))
+		}
+		events[event] = struct{}{}
+	}
+	if len(events) == 0 {
+		for _, event := range entity.EventTypes {
+			events[event] = struct{}{}
+		}
+	}
+
+	sub := s.bus.Subscribe(s.cfg.WatchBufferSize, func(event entity.Event) bool {
+		_, okAddress := addresses[event.Address]
+		_, okEvent := events[event.Type]
+		return okAddress && okEvent
+	})
+	defer sub.Close()
+
+	ctx := stream.Context()
+	for {
+		select {
+		case <-ctx.Done():
+			return statusError(ctx.Err())
+
+		case event, ok := <-sub.C():
+			if !ok {
+				return status.Error(codes.ResourceExhausted, "slow consumer")
+			}
+			if err := stream.Send(&pb.WatchResponse{Event: newEvent(event)}); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func pageLimit(limit int32) (int, error) {
+	switch {
+	case limit == 0:
+		return defaultPageLimit, nil
+	case limit < 0 || limit > maxPageLimit:
+		return 0, fmt.Errorf("%w: limit must be between 1 and %d", entity.ErrInvalidParams, maxPageLimit)
+	default:
+		return int(limit), nil
+	}
+}
@@ -0,0 +1,107 @@
+package health
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/vuquang23/trustme/internal/pkg/entity"
+)
+
+const (
+	CheckRepositories = "repositories"
+	CheckRPC          = "rpc"
+	CheckWebSocket    = "websocket"
+	CheckLag          = "lag"
+)
+
+// Checker reports whether the service can serve traffic and how far the parser is behind the chain.
+type Checker struct {
+	cfg    Config
+	parser IParser
+	repos  IRepositories
+}
+
+func New(cfg Config, parser IParser, repos IRepositories) *Checker {
+	return &Checker{
+		cfg:    cfg,
+		parser: parser,
+		repos:  repos,
+	}
+}
+
+// Ready checks that the repositories and the RPC node are reachable, that new heads keep coming and that the
+// parser is less than MaxLag blocks behind.
+func (c *Checker) Ready(ctx context.Context) entity.Readiness {
+	ctx, cancel := context.WithTimeout(ctx, c.cfg.Timeout)
+	defer cancel()
+
+	var readiness entity.Readiness
+	add := func(name string, err error) {
+		check := entity.HealthCheck{Name: name}
+		if err != nil {
+			check.Error = err.Error()
+		}
+		readiness.Checks = append(readiness.Checks, check)
+	}
+
+	add(CheckRepositories, c.ping(ctx))
+
+	_, rpcErr := c.parser.ChainHead(ctx)
+	add(CheckRPC, rpcErr)
+
+	status := c.parser.Status()
+	add(CheckWebSocket, c.checkWebSocket(status.WS))
+	if rpcErr == nil {
+		add(CheckLag, c.checkLag(status))
+	}
+
+	return readiness
+}
+
+// Status returns the progress of the parser, with the chain head refreshed from the RPC node when reachable.
+func (c *Checker) Status(ctx context.Context) entity.SyncStatus {
+	ctx, cancel := context.WithTimeout(ctx, c.cfg.Timeout)
+	defer cancel()
+
+	_, _ = c.parser.ChainHead(ctx)
+	return c.parser.Status()
+}
+
+// ping runs the repository check under the timeout, the repositories don't take a context.
+func (c *Checker) ping(ctx context.Context) error {
+	done := make(chan error, 1)
+	go func() { done <- c.repos.Ping() }()
+
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (c *Checker) checkWebSocket(ws entity.WSStatus) error {
+	if !ws.Connected {
+		if ws.LastError != "" {
+			return fmt.Errorf("new heads subscription is down: %s", ws.LastError)
+		}
+		return fmt.Errorf("new heads subscription is down")
+	}
+
+	last := ws.ConnectedAt
+	if ws.LastHeadAt.After(last) {
+		last = ws.LastHeadAt
+	}
+	if age := time.Since(last); age > c.cfg.MaxHeadAge {
+		return fmt.Errorf("no new head for %s", age.Truncate(time.Second))
+	}
+	return nil
+}
+
+func (c *Checker) checkLag(status entity.SyncStatus) error {
+	if lag := status.Lag(); lag > c.cfg.MaxLag {
+		return fmt.Errorf("%d blocks behind the chain head, max %d", lag, c.cfg.MaxLag)
+	}
+	return nil
+}
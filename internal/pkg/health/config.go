@@ -0,0 +1,13 @@
+package health
+
+import "time"
+
+type Config struct {
+	// MaxLag is the number of blocks the parser may be behind the chain head and still be ready.
+	MaxLag uint64 `default:"10"`
+	// MaxHeadAge is how long the new heads subscription may go without a head, about 12s on mainnet,
+	// before the parser is considered stalled.
+	MaxHeadAge time.Duration `default:"2m"`
+	// Timeout bounds the RPC and repository checks.
+	Timeout time.Duration `default:"3s"`
+}
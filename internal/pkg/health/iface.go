@@ -0,0 +1,19 @@
+package health
+
+import (
+	"context"
+
+	"github.com/vuquang23/trustme/internal/pkg/entity"
+)
+
+type IParser interface {
+	// progress of the parser against the chain
+	Status() entity.SyncStatus
+	// latest block number of the RPC node
+	ChainHead(ctx context.Context) (uint64, error)
+}
+
+type IRepositories interface {
+	// check the storage backend can be read
+	Ping() error
+}
@@ -0,0 +1,173 @@
+package importer
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"github.com/vuquang23/trustme/internal/pkg/entity"
+)
+
+type Format string
+
+const (
+	FormatJSON   Format = "json"
+	FormatCSV    Format = "csv"
+	FormatNDJSON Format = "ndjson"
+)
+
+var ErrUnsupportedFormat = errors.New("unsupported import format")
+
+// Item is one subscription of an import, the same shape as the subscribe request.
+// In CSV, columns are address,label,tags,owner,notes with tags separated by ';' and an optional header row.
+type Item struct {
+	Address string   `json:"address"`
+	Label   string   `json:"label"`
+	Tags    []string `json:"tags"`
+	Owner   string   `json:"owner"`
+	Notes   string   `json:"notes"`
+}
+
+type ISubscriptionAdder interface {
+	AddSubscription(subscription *entity.Subscription) error
+}
+
+// FormatFromFilename guesses the format from a file extension, defaulting to CSV.
+func FormatFromFilename(name string) Format {
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".json":
+		return FormatJSON
+	case ".ndjson", ".jsonl":
+		return FormatNDJSON
+	default:
+		return FormatCSV
+	}
+}
+
+func Decode(r io.Reader, format Format) ([]Item, error) {
+	switch format {
+	case FormatJSON:
+		var items []Item
+		if err := json.NewDecoder(r).Decode(&items); err != nil {
+			return nil, err
+		}
+		return items, nil
+
+	case FormatNDJSON:
+		return decodeNDJSON(r)
+
+	case FormatCSV:
+		return decodeCSV(r)
+
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedFormat, format)
+	}
+}
+
+func decodeNDJSON(r io.Reader) ([]Item, error) {
+	var items []Item
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" {
+			continue
+		}
+
+		var item Item
+		if err := json.Unmarshal([]byte(text), &item); err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		items = append(items, item)
+	}
+
+	return items, scanner.Err()
+}
+
+func decodeCSV(r io.Reader) ([]Item, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+
+	if len(records) > 0 && len(records[0]) > 0 && strings.EqualFold(strings.TrimSpace(records[0][0]), "address") {
+		records = records[1:]
+	}
+
+	items := make([]Item, 0, len(records))
+	for _, record := range records {
+		var item Item
+		for i, field := range record {
+			field = strings.TrimSpace(field)
+			switch i {
+			case 0:
+				item.Address = field
+			case 1:
+				item.Label = field
+			case 2:
+				if field != "" {
+					item.Tags = strings.Split(field, ";")
+				}
+			case 3:
+				item.Owner = field
+			case 4:
+				item.Notes = field
+			}
+		}
+		items = append(items, item)
+	}
+
+	return items, nil
+}
+
+// Import subscribes every item and reports the outcome of each one. A failing item doesn't stop the import.
+func Import(items []Item, enforceChecksum bool, adder ISubscriptionAdder) *entity.ImportResult {
+	result := &entity.ImportResult{Items: make([]entity.ImportItemResult, 0, len(items))}
+
+	for i, item := range items {
+		itemResult := entity.ImportItemResult{Line: i + 1, Address: item.Address}
+
+		address, err := entity.ParseAddress(item.Address, enforceChecksum)
+		if err != nil {
+			itemResult.Status = entity.ImportStatusInvalid
+			itemResult.Error = err.Error()
+			result.Invalid++
+			result.Items = append(result.Items, itemResult)
+			continue
+		}
+		itemResult.Address = address.Hex()
+
+		err = adder.AddSubscription(&entity.Subscription{
+			Address: entity.AddressKey(address),
+			Label:   item.Label,
+			Tags:    item.Tags,
+			Owner:   item.Owner,
+			Notes:   item.Notes,
+		})
+		switch {
+		case err == nil:
+			itemResult.Status = entity.ImportStatusCreated
+			result.Created++
+		case errors.Is(err, entity.ErrAlreadySubscribed):
+			itemResult.Status = entity.ImportStatusDuplicate
+			result.Duplicates++
+		default:
+			itemResult.Status = entity.ImportStatusFailed
+			itemResult.Error = err.Error()
+			result.Failed++
+		}
+		result.Items = append(result.Items, itemResult)
+	}
+
+	return result
+}
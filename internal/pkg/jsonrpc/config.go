@@ -0,0 +1,21 @@
+package jsonrpc
+
+import "time"
+
+type Config struct {
+	// Enabled serves the JSON-RPC endpoint at /rpc, over HTTP POST and WebSocket.
+	Enabled bool `default:"true"`
+	// MaxRequestSize is the maximum size in bytes of a request or a batch.
+	MaxRequestSize int64 `default:"1048576"`
+	// MaxBatchSize is the maximum number of calls of a batch request.
+	MaxBatchSize int `default:"100"`
+
+	// MaxSubscriptions is the maximum number of eth_subscribe subscriptions of a WebSocket connection.
+	MaxSubscriptions int `default:"100"`
+	// MaxSubscriptionAddresses is the maximum number of addresses of an activity subscription.
+	MaxSubscriptionAddresses int `default:"1000"`
+	// WSBufferSize is the number of events queued per WebSocket connection before a slow client is disconnected.
+	WSBufferSize int `default:"1024"`
+	// WSPingInterval is the interval of ping frames, a client not answering within two intervals is disconnected.
+	WSPingInterval time.Duration `default:"30s"`
+}
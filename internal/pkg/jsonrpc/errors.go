@@ -0,0 +1,78 @@
+package jsonrpc
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/vuquang23/trustme/internal/pkg/api"
+)
+
+// Standard JSON-RPC 2.0 error codes, -32000 being the first of the range left to servers.
+const (
+	CodeParseError     = -32700
+	CodeInvalidRequest = -32600
+	CodeMethodNotFound = -32601
+	CodeInvalidParams  = -32602
+	CodeInternalError  = -32603
+	CodeServerError    = -32000
+)
+
+// Error is the error object of a response. Data carries the code of the HTTP API for domain errors.
+type Error struct {
+	Code    int        `json:"code"`
+	Message string     `json:"message"`
+	Data    *ErrorData `json:"data,omitempty"`
+}
+
+type ErrorData struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+func errParse(err error) *Error {
+	return &Error{Code: CodeParseError, Message: "parse error: " + err.Error()}
+}
+
+func errInvalidRequest(message string) *Error {
+	return &Error{Code: CodeInvalidRequest, Message: "invalid request: " + message}
+}
+
+func errMethodNotFound(method string) *Error {
+	return &Error{Code: CodeMethodNotFound, Message: "the method " + method + " does not exist/is not available"}
+}
+
+var errNotificationsUnsupported = &Error{Code: CodeMethodNotFound, Message: "notifications not supported"}
+
+// errorObject converts a domain error: invalid input is reported as invalid params, server failures as internal
+// errors and the other errors in the server range. Messages of server failures are not leaked.
+func errorObject(err error) *Error {
+	var rpcErr *Error
+	if errors.As(err, &rpcErr) {
+		return rpcErr
+	}
+
+	if errors.Is(err, context.Canceled) {
+		return &Error{Code: CodeServerError, Message: "request was canceled", Data: &ErrorData{Code: 4990, Message: "request was canceled"}}
+	}
+
+	response := api.ResponseFromError(err)
+	data := &ErrorData{Code: response.Code, Message: response.Message}
+
+	switch {
+	case response.HTTPStatus == http.StatusBadRequest:
+		return &Error{Code: CodeInvalidParams, Message: err.Error(), Data: data}
+	case response.HTTPStatus >= http.StatusInternalServerError:
+		code := CodeInternalError
+		if response.HTTPStatus == http.StatusServiceUnavailable {
+			code = CodeServerError
+		}
+		return &Error{Code: code, Message: response.Message, Data: data}
+	default:
+		return &Error{Code: CodeServerError, Message: err.Error(), Data: data}
+	}
+}
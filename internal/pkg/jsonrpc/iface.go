@@ -0,0 +1,18 @@
+package jsonrpc
+
+import (
+	"context"
+
+	"github.com/vuquang23/trustme/internal/pkg/entity"
+	"github.com/vuquang23/trustme/internal/pkg/eventbus"
+	"github.com/vuquang23/trustme/internal/pkg/tenant"
+)
+
+type ITenants interface {
+	// the parser as seen by the tenant of the request, unrestricted without one
+	Parser(ctx context.Context) *tenant.Parser
+}
+
+type IEventBus interface {
+	Subscribe(buffer int, filter func(entity.Event) bool) *eventbus.Subscription
+}
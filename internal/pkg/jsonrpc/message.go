@@ -0,0 +1,65 @@
+package jsonrpc
+
+import (
+	"bytes"
+	"encoding/json"
+)
+
+const (
+	version = "2.0"
+
+	notificationMethod = "eth_subscription"
+)
+
+type request struct {
+	Version string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id,omitempty"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params,omitempty"`
+}
+
+// isNotification reports whether the request has no id, in which case it is not answered.
+func (r *request) isNotification() bool {
+	return len(r.ID) == 0
+}
+
+type response struct {
+	Version string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Result  interface{}     `json:"result,omitempty"`
+	Error   *Error          `json:"error,omitempty"`
+}
+
+type notification struct {
+	Version string             `json:"jsonrpc"`
+	Method  string             `json:"method"`
+	Params  notificationParams `json:"params"`
+}
+
+type notificationParams struct {
+	Subscription string      `json:"subscription"`
+	Result       interface{} `json:"result"`
+}
+
+var nullID = json.RawMessage("null")
+
+func newResult(id json.RawMessage, result interface{}) *response {
+	if result == nil {
+		// "result" is required on success, encode it as null.
+		result = json.RawMessage("null")
+	}
+	return &response{Version: version, ID: id, Result: result}
+}
+
+func newError(id json.RawMessage, err *Error) *response {
+	if id == nil {
+		id = nullID
+	}
+	return &response{Version: version, ID: id, Error: err}
+}
+
+// isBatch tells a batch from a single call by the first non-space character of the body.
+func isBatch(body []byte) bool {
+	body = bytes.TrimLeft(body, " \t\r\n")
+	return len(body) > 0 && body[0] == '['
+}
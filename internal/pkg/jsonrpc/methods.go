@@ -0,0 +1,245 @@
+package jsonrpc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/gin-gonic/gin/binding"
+
+	"github.com/vuquang23/trustme/internal/pkg/api"
+	"github.com/vuquang23/trustme/internal/pkg/entity"
+)
+
+const (
+	defaultPageLimit = 100
+	maxPageLimit     = 1000
+)
+
+// trustme_getCurrentBlock() -> number
+func (s *Server) getCurrentBlock(ctx context.Context, _ *wsConn, params []json.RawMessage) (interface{}, error) {
+	if err := decodeParams(params, 0); err != nil {
+		return nil, err
+	}
+	return s.tenants.Parser(ctx).GetCurrentBlock(), nil
+}
+
+// trustme_subscribe(address, metadata?) -> subscription, metadata being {"label", "tags", "owner", "notes"}
+func (s *Server) subscribe(ctx context.Context, _ *wsConn, params []json.RawMessage) (interface{}, error) {
+	var (
+		address  string
+		metadata api.SubscribeAddressParams
+	)
+	if err := decodeParams(params, 1, &address, &metadata); err != nil {
+		return nil, err
+	}
+
+	key, err := entity.ParseAddressKey(address, s.enforceChecksum)
+	if err != nil {
+		return nil, err
+	}
+
+	subscription := &entity.Subscription{
+		Address: key,
+		Label:   metadata.Label,
+		Tags:    metadata.Tags,
+		Owner:   metadata.Owner,
+		Notes:   metadata.Notes,
+	}
+	if err := s.tenants.Parser(ctx).AddSubscription(subscription); err != nil {
+		return nil, err
+	}
+	return api.NewSubscriptionResponse(subscription), nil
+}
+
+// trustme_unsubscribe(address) -> true
+func (s *Server) unsubscribe(ctx context.Context, _ *wsConn, params []json.RawMessage) (interface{}, error) {
+	var address string
+	if err := decodeParams(params, 1, &address); err != nil {
+		return nil, err
+	}
+
+	key, err := entity.ParseAddressKey(address, s.enforceChecksum)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.tenants.Parser(ctx).Unsubscribe(key); err != nil {
+		return nil, err
+	}
+	return true, nil
+}
+
+// trustme_getSubscriptions(cursor?, limit?) -> page of subscriptions, as GET /api/subscriptions
+func (s *Server) getSubscriptions(ctx context.Context, _ *wsConn, params []json.RawMessage) (interface{}, error) {
+	var (
+		cursor string
+		limit  = defaultPageLimit
+	)
+	if err := decodeParams(params, 0, &cursor, &limit); err != nil {
+		return nil, err
+	}
+	if limit < 1 || limit > maxPageLimit {
+		return nil, fmt.Errorf("%w: limit must be between 1 and %d", entity.ErrInvalidParams, maxPageLimit)
+	}
+
+	page, err := s.tenants.Parser(ctx).GetSubscriptions(strings.ToLower(cursor), limit)
+	if err != nil {
+		return nil, err
+	}
+	return api.NewSubscriptionPageResponse(page), nil
+}
+
+// trustme_getTransactions(address) -> transactions, as GET /api/txs
+func (s *Server) getTransactions(ctx context.Context, _ *wsConn, params []json.RawMessage) (interface{}, error) {
+	var address string
+	if err := decodeParams(params, 1, &address); err != nil {
+		return nil, err
+	}
+
+	key, err := entity.ParseAddressKey(address, s.enforceChecksum)
+	if err != nil {
+		return nil, err
+	}
+	return api.NewRawTransactionResponses(s.tenants.Parser(ctx).GetTransactions(key)), nil
+}
+
+// trustme_queryTransactions(query) -> page of transaction records, query having the params of GET /api/v2/txs
+func (s *Server) queryTransactions(ctx context.Context, _ *wsConn, params []json.RawMessage) (interface{}, error) {
+	var query api.GetTxRecordsParams
+	if err := decodeParams(params, 1, &query); err != nil {
+		return nil, err
+	}
+	if err := binding.Validator.ValidateStruct(&query); err != nil {
+		return nil, fmt.Errorf("%w: %v", entity.ErrInvalidParams, err)
+	}
+
+	key, err := entity.ParseAddressKey(query.Address, s.enforceChecksum)
+	if err != nil {
+		return nil, err
+	}
+
+	txQuery, err := query.ToQuery(s.enforceChecksum)
+	if err != nil {
+		return nil, err
+	}
+
+	page, err := s.tenants.Parser(ctx).QueryTxRecords(key, txQuery)
+	if err != nil {
+		return nil, err
+	}
+	return api.NewTxPageResponse(page), nil
+}
+
+// trustme_getTransactionByHash(hash) -> transaction detail as GET /api/txs/{hash}, null when it does not exist
+func (s *Server) getTransactionByHash(ctx context.Context, _ *wsConn, params []json.RawMessage) (interface{}, error) {
+	var hash string
+	if err := decodeParams(params, 1, &hash); err != nil {
+		return nil, err
+	}
+
+	b, err := hexutil.Decode(hash)
+	if err != nil || len(b) != common.HashLength {
+		return nil, entity.ErrInvalidTxHash
+	}
+
+	detail, err := s.tenants.Parser(ctx).GetTxDetail(ctx, common.BytesToHash(b))
+	if errors.Is(err, entity.ErrTxNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return api.NewTxDetailResponse(detail), nil
+}
+
+// ActivityCriteria selects the events of an activity subscription. Events defaults to every event type.
+type ActivityCriteria struct {
+	Addresses []string `json:"addresses"`
+	Events    []string `json:"events"`
+}
+
+// eth_subscribe("activity", criteria) or eth_subscribe("blocks") -> subscription id, WebSocket only
+func (s *Server) ethSubscribe(ctx context.Context, conn *wsConn, params []json.RawMessage) (interface{}, error) {
+	if conn == nil {
+		return nil, errNotificationsUnsupported
+	}
+
+	var (
+		kind     string
+		criteria ActivityCriteria
+	)
+	if err := decodeParams(params, 1, &kind, &criteria); err != nil {
+		return nil, err
+	}
+
+	sub := &subscription{
+		id:     newSubscriptionID(),
+		events: make(map[entity.EventType]struct{}),
+	}
+
+	switch kind {
+	case subscriptionBlocks:
+		if len(params) > 1 {
+			return nil, fmt.Errorf("%w: blocks subscriptions take no criteria", entity.ErrInvalidParams)
+		}
+		sub.events[entity.EventTypeBlock] = struct{}{}
+
+	case subscriptionActivity:
+		if len(criteria.Addresses) == 0 || len(criteria.Addresses) > s.cfg.MaxSubscriptionAddresses {
+			return nil, fmt.Errorf("%w: between 1 and %d addresses are required", entity.ErrInvalidParams, s.cfg.MaxSubscriptionAddresses)
+		}
+
+		parser := s.tenants.Parser(ctx)
+		sub.addresses = make(map[string]struct{}, len(criteria.Addresses))
+		for _, address := range criteria.Addresses {
+			key, err := entity.ParseAddressKey(address, s.enforceChecksum)
+			if err != nil {
+				return nil, err
+			}
+			if !parser.Allows(key) {
+				return nil, fmt.Errorf("%w: %s", entity.ErrNotSubscribed, address)
+			}
+			sub.addresses[key] = struct{}{}
+		}
+
+		events := criteria.Events
+		if len(events) == 0 {
+			for _, event := range entity.EventTypes {
+				events = append(events, string(event))
+			}
+		}
+		for _, e := range events {
+			event := entity.EventType(e)
+			if !entity.IsEventType(event) {
+				return nil, fmt.Errorf("%w: unknown event: %s", entity.ErrInvalidParams, e)
+			}
+			sub.events[event] = struct{}{}
+		}
+
+	default:
+		return nil, fmt.Errorf("%w: no %q subscription", entity.ErrInvalidParams, kind)
+	}
+
+	if err := conn.add(sub); err != nil {
+		return nil, err
+	}
+	return sub.id, nil
+}
+
+// eth_unsubscribe(id) -> whether the subscription existed, WebSocket only
+func (s *Server) ethUnsubscribe(_ context.Context, conn *wsConn, params []json.RawMessage) (interface{}, error) {
+	if conn == nil {
+		return nil, errNotificationsUnsupported
+	}
+
+	var id string
+	if err := decodeParams(params, 1, &id); err != nil {
+		return nil, err
+	}
+	return conn.remove(id), nil
+}
@@ -0,0 +1,82 @@
+package jsonrpc
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+
+	"github.com/vuquang23/trustme/pkg/logger"
+)
+
+const endpoint = "/rpc"
+
+// SetupRoute serves JSON-RPC calls POSTed to /rpc, and over a WebSocket opened on the same path.
+func SetupRoute(engine *gin.Engine, cfg Config, enforceChecksum bool, tenants ITenants, bus IEventBus) {
+	if !cfg.Enabled {
+		return
+	}
+
+	s := NewServer(cfg, enforceChecksum, tenants, bus)
+
+	engine.POST(endpoint, s.HTTP)
+	engine.GET(endpoint, s.WebSocket)
+}
+
+func (s *Server) HTTP(c *gin.Context) {
+	body, err := io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, s.cfg.MaxRequestSize))
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			c.JSON(http.StatusRequestEntityTooLarge, newError(nil, errInvalidRequest(err.Error())))
+			return
+		}
+		c.JSON(http.StatusBadRequest, newError(nil, errParse(err)))
+		return
+	}
+
+	message := s.handle(requestContext(c), nil, body)
+	if message == nil {
+		c.Status(http.StatusNoContent)
+		return
+	}
+
+	c.JSON(http.StatusOK, message)
+}
+
+// WebSocket upgrades the connection and answers the calls sent over it. Every connection has its own bounded event
+// queue, a client too slow to drain it is disconnected.
+func (s *Server) WebSocket(c *gin.Context) {
+	upgrader := websocket.Upgrader{
+		// The CORS middleware refuses the upgrades from origins other than the Http.AllowOrigins ones, none by default.
+		CheckOrigin: func(*http.Request) bool { return true },
+	}
+
+	conn, err := upgrader.Upgrade(c.Writer, c.Request, nil)
+	if err != nil {
+		logger.Warnf(c, "websocket upgrade failed: %s", err)
+		return
+	}
+	defer conn.Close()
+
+	ctx := requestContext(c)
+	wsConn := newWSConn(s.cfg, conn)
+
+	sub := s.bus.Subscribe(s.cfg.WSBufferSize, wsConn.accepts)
+	defer sub.Close()
+
+	go wsConn.writeLoop(ctx, sub.C())
+	wsConn.readLoop(ctx, s)
+}
+
+// requestContext is the context of the request carrying the request logger set by the gin middleware.
+func requestContext(c *gin.Context) context.Context {
+	ctx := c.Request.Context()
+	if reqLogger, ok := c.Get(string(logger.CtxLoggerKey)); ok {
+		ctx = context.WithValue(ctx, logger.CtxLoggerKey, reqLogger) //nolint:staticcheck // key shared with gin.Context.Set
+	}
+	return ctx
+}
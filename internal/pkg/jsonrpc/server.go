@@ -0,0 +1,172 @@
+package jsonrpc
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/vuquang23/trustme/internal/pkg/entity"
+	"github.com/vuquang23/trustme/pkg/logger"
+)
+
+// method runs a call with its positional params. conn is nil for calls made over HTTP.
+type method func(ctx context.Context, conn *wsConn, params []json.RawMessage) (interface{}, error)
+
+// Server answers JSON-RPC 2.0 calls, single or batched, mirroring the operations of the parser.
+// Over WebSocket, eth_subscribe and eth_unsubscribe push the events of the parser as eth_subscription notifications.
+type Server struct {
+	cfg             Config
+	enforceChecksum bool
+	tenants         ITenants
+	bus             IEventBus
+
+	methods map[string]method
+}
+
+func NewServer(cfg Config, enforceChecksum bool, tenants ITenants, bus IEventBus) *Server {
+	s := &Server{
+		cfg:             cfg,
+		enforceChecksum: enforceChecksum,
+		tenants:         tenants,
+		bus:             bus,
+	}
+
+	s.methods = map[string]method{
+		"trustme_getCurrentBlock":      s.getCurrentBlock,
+		"trustme_subscribe":            s.subscribe,
+		"trustme_unsubscribe":          s.unsubscribe,
+		"trustme_getSubscriptions":     s.getSubscriptions,
+		"trustme_getTransactions":      s.getTransactions,
+		"trustme_queryTransactions":    s.queryTransactions,
+		"trustme_getTransactionByHash": s.getTransactionByHash,
+		"eth_subscribe":                s.ethSubscribe,
+		"eth_unsubscribe":              s.ethUnsubscribe,
+	}
+
+	return s
+}
+
+// handle answers a body holding a single call or a batch. It returns nil when there is nothing to answer,
+// i.e. the body only held notifications.
+func (s *Server) handle(ctx context.Context, conn *wsConn, body []byte) interface{} {
+	if !isBatch(body) {
+		var req request
+		if err := json.Unmarshal(body, &req); err != nil {
+			return newError(nil, errParse(err))
+		}
+		if resp := s.call(ctx, conn, &req); resp != nil {
+			return resp
+		}
+		return nil
+	}
+
+	var batch []json.RawMessage
+	if err := json.Unmarshal(body, &batch); err != nil {
+		return newError(nil, errParse(err))
+	}
+	if len(batch) == 0 {
+		return newError(nil, errInvalidRequest("empty batch"))
+	}
+	if len(batch) > s.cfg.MaxBatchSize {
+		return newError(nil, errInvalidRequest(fmt.Sprintf("batch has %d calls, max is %d", len(batch), s.cfg.MaxBatchSize)))
+	}
+
+	responses := make([]*response, 0, len(batch))
+	for _, raw := range batch {
+		var req request
+		if err := json.Unmarshal(raw, &req); err != nil {
+			responses = append(responses, newError(nil, errInvalidRequest(err.Error())))
+			continue
+		}
+		if resp := s.call(ctx, conn, &req); resp != nil {
+			responses = append(responses, resp)
+		}
+	}
+
+	if len(responses) == 0 {
+		return nil
+	}
+	return responses
+}
+
+// call runs one call, the response is nil for notifications.
+func (s *Server) call(ctx context.Context, conn *wsConn, req *request) *response {
+	if req.Version != version || req.Method == "" || !isValidID(req.ID) {
+		return newError(nil, errInvalidRequest(`"jsonrpc" must be "2.0", "method" is required and "id" must be a string or a number`))
+	}
+
+	result, err := s.run(ctx, conn, req)
+	if req.isNotification() {
+		return nil
+	}
+
+	if err != nil {
+		rpcErr := errorObject(err)
+		if rpcErr.Code == CodeInternalError {
+			logger.WithFields(ctx, logger.Fields{"method": req.Method, "error": err}).Warn("json-rpc call failure")
+		}
+		return newError(req.ID, rpcErr)
+	}
+
+	return newResult(req.ID, result)
+}
+
+func (s *Server) run(ctx context.Context, conn *wsConn, req *request) (interface{}, error) {
+	m, ok := s.methods[req.Method]
+	if !ok {
+		return nil, errMethodNotFound(req.Method)
+	}
+
+	params, err := splitParams(req.Params)
+	if err != nil {
+		return nil, err
+	}
+
+	return m(ctx, conn, params)
+}
+
+// isValidID accepts the ids allowed by the specification: absent, null, a string or a number.
+func isValidID(id json.RawMessage) bool {
+	if len(id) == 0 {
+		return true
+	}
+	switch id[0] {
+	case '{', '[', 't', 'f':
+		return false
+	}
+	return true
+}
+
+// splitParams only accepts positional params, like the Ethereum JSON-RPC API.
+func splitParams(raw json.RawMessage) ([]json.RawMessage, error) {
+	if len(raw) == 0 || string(raw) == "null" {
+		return nil, nil
+	}
+
+	var params []json.RawMessage
+	if err := json.Unmarshal(raw, &params); err != nil {
+		return nil, fmt.Errorf("%w: params must be an array", entity.ErrInvalidParams)
+	}
+	return params, nil
+}
+
+// decodeParams decodes the params into args, in order. The first required args are mandatory, the others may be
+// omitted and keep their value.
+func decodeParams(params []json.RawMessage, required int, args ...interface{}) error {
+	if len(params) < required {
+		return fmt.Errorf("%w: missing value for required argument %d", entity.ErrInvalidParams, len(params))
+	}
+	if len(params) > len(args) {
+		return fmt.Errorf("%w: too many arguments, want at most %d", entity.ErrInvalidParams, len(args))
+	}
+
+	for i, param := range params {
+		if string(param) == "null" && i >= required {
+			continue
+		}
+		if err := json.Unmarshal(param, args[i]); err != nil {
+			return fmt.Errorf("%w: invalid argument %d: %v", entity.ErrInvalidParams, i, err)
+		}
+	}
+	return nil
+}
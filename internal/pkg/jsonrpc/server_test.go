@@ -0,0 +1,460 @@
+package jsonrpc
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+	"github.com/mcuadros/go-defaults"
+
+	"github.com/vuquang23/trustme/internal/pkg/entity"
+	"github.com/vuquang23/trustme/internal/pkg/eventbus"
+	"github.com/vuquang23/trustme/internal/pkg/repository/subscriber"
+	tenantrepo "github.com/vuquang23/trustme/internal/pkg/repository/tenant"
+	"github.com/vuquang23/trustme/internal/pkg/tenant"
+)
+
+func init() {
+	gin.SetMode(gin.TestMode)
+}
+
+const address = "0x1f9090aae28b8a3dceadf281b0f12828e676c326"
+
+// fakeParser only keeps subscriptions, it has no transaction.
+type fakeParser struct {
+	subscribers *subscriber.MemRepository
+}
+
+func (p *fakeParser) GetCurrentBlock() int {
+	return 42
+}
+
+func (p *fakeParser) AddSubscription(subscription *entity.Subscription) error {
+	if p.subscribers.IsSubscriber(subscription.Address) {
+		return entity.ErrAlreadySubscribed
+	}
+	return p.subscribers.Create(subscription)
+}
+
+func (p *fakeParser) Unsubscribe(address string) error {
+	if !p.subscribers.IsSubscriber(address) {
+		return entity.ErrNotSubscribed
+	}
+	return p.subscribers.Delete(address)
+}
+
+func (p *fakeParser) GetSubscription(address string) (*entity.Subscription, error) {
+	subscription, err := p.subscribers.Get(address)
+	if err != nil || subscription == nil {
+		return nil, entity.ErrNotSubscribed
+	}
+	return subscription, nil
+}
+
+func (p *fakeParser) GetSubscriptions(cursor string, limit int) (*entity.SubscriptionPage, error) {
+	return p.subscribers.List(cursor, limit)
+}
+
+func (p *fakeParser) GetTransactions(string) []*types.Transaction {
+	return []*types.Transaction{}
+}
+
+func (p *fakeParser) QueryTxRecords(string, entity.TxQuery) (*entity.TxPage, error) {
+	return &entity.TxPage{Records: []*entity.TxRecord{}}, nil
+}
+
+func (p *fakeParser) GetTxDetail(context.Context, common.Hash) (*entity.TxDetail, error) {
+	return nil, entity.ErrTxNotFound
+}
+
+func (p *fakeParser) GetTokenTransfers(context.Context, common.Hash) ([]*entity.TokenTransfer, error) {
+	return nil, entity.ErrTxNotFound
+}
+
+type noQuota struct{}
+
+func (noQuota) ConsumeSubscription(string) error { return nil }
+
+func (noQuota) RefundSubscription(string) {}
+
+func newTestServer(t *testing.T) (*httptest.Server, *eventbus.Bus) {
+	t.Helper()
+
+	var cfg Config
+	defaults.SetDefaults(&cfg)
+	cfg.MaxBatchSize = 3
+
+	bus := eventbus.New()
+	tenants := tenant.New(&fakeParser{subscribers: subscriber.NewMemRepository()}, tenantrepo.NewMemRepository(), noQuota{})
+
+	engine := gin.New()
+	SetupRoute(engine, cfg, false, tenants, bus)
+
+	srv := httptest.NewServer(engine)
+	t.Cleanup(srv.Close)
+	return srv, bus
+}
+
+func post(t *testing.T, srv *httptest.Server, body string) (int, string) {
+	t.Helper()
+
+	resp, err := http.Post(srv.URL+endpoint, "application/json", strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	b, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return resp.StatusCode, string(b)
+}
+
+// errorCodes returns the error code of every response of a body, 0 for a result, in order.
+func errorCodes(t *testing.T, body string) []int {
+	t.Helper()
+
+	var responses []response
+	if isBatch([]byte(body)) {
+		if err := json.Unmarshal([]byte(body), &responses); err != nil {
+			t.Fatalf("%s: %s", body, err)
+		}
+	} else {
+		var resp response
+		if err := json.Unmarshal([]byte(body), &resp); err != nil {
+			t.Fatalf("%s: %s", body, err)
+		}
+		responses = append(responses, resp)
+	}
+
+	codes := make([]int, 0, len(responses))
+	for _, resp := range responses {
+		if resp.Version != version {
+			t.Fatalf("got version %q in %s", resp.Version, body)
+		}
+		code := 0
+		if resp.Error != nil {
+			code = resp.Error.Code
+		}
+		codes = append(codes, code)
+	}
+	return codes
+}
+
+func TestHTTPCalls(t *testing.T) {
+	srv, _ := newTestServer(t)
+
+	for _, tc := range []struct {
+		name string
+		body string
+		want string
+	}{
+		{
+			name: "call",
+			body: `{"jsonrpc": "2.0", "id": 1, "method": "trustme_getCurrentBlock"}`,
+			want: `{"jsonrpc":"2.0","id":1,"result":42}`,
+		},
+		{
+			name: "string id",
+			body: `{"jsonrpc": "2.0", "id": "a", "method": "trustme_getCurrentBlock", "params": []}`,
+			want: `{"jsonrpc":"2.0","id":"a","result":42}`,
+		},
+		{
+			name: "parse error",
+			body: `{"jsonrpc": "2.0", "id": 1, "method"`,
+			want: `{"jsonrpc":"2.0","id":null,"error":{"code":-32700}}`,
+		},
+		{
+			name: "batch parse error",
+			body: `[{"jsonrpc": "2.0", "id": 1, "method": "trustme_getCurrentBlock"},`,
+			want: `{"jsonrpc":"2.0","id":null,"error":{"code":-32700}}`,
+		},
+		{
+			name: "wrong version",
+			body: `{"jsonrpc": "1.0", "id": 1, "method": "trustme_getCurrentBlock"}`,
+			want: `{"jsonrpc":"2.0","id":null,"error":{"code":-32600}}`,
+		},
+		{
+			name: "object id",
+			body: `{"jsonrpc": "2.0", "id": {}, "method": "trustme_getCurrentBlock"}`,
+			want: `{"jsonrpc":"2.0","id":null,"error":{"code":-32600}}`,
+		},
+		{
+			name: "no method",
+			body: `{"jsonrpc": "2.0", "id": 1}`,
+			want: `{"jsonrpc":"2.0","id":null,"error":{"code":-32600}}`,
+		},
+		{
+			name: "unknown method",
+			body: `{"jsonrpc": "2.0", "id": 1, "method": "eth_blockNumber"}`,
+			want: `{"jsonrpc":"2.0","id":1,"error":{"code":-32601}}`,
+		},
+		{
+			name: "named params",
+			body: `{"jsonrpc": "2.0", "id": 1, "method": "trustme_subscribe", "params": {"address": "` + address + `"}}`,
+			want: `{"jsonrpc":"2.0","id":1,"error":{"code":-32602}}`,
+		},
+		{
+			name: "invalid address",
+			body: `{"jsonrpc": "2.0", "id": 1, "method": "trustme_subscribe", "params": ["0x1234"]}`,
+			want: `{"jsonrpc":"2.0","id":1,"error":{"code":-32602,"data":{"code":4001}}}`,
+		},
+		{
+			name: "not subscribed",
+			body: `{"jsonrpc": "2.0", "id": 1, "method": "trustme_unsubscribe", "params": ["` + address + `"]}`,
+			want: `{"jsonrpc":"2.0","id":1,"error":{"code":-32000,"data":{"code":4040}}}`,
+		},
+		{
+			name: "eth_subscribe over http",
+			body: `{"jsonrpc": "2.0", "id": 1, "method": "eth_subscribe", "params": ["blocks"]}`,
+			want: `{"jsonrpc":"2.0","id":1,"error":{"code":-32601}}`,
+		},
+		{
+			name: "empty batch",
+			body: `[]`,
+			want: `{"jsonrpc":"2.0","id":null,"error":{"code":-32600}}`,
+		},
+		{
+			name: "batch over the limit",
+			body: `[1, 2, 3, 4]`,
+			want: `{"jsonrpc":"2.0","id":null,"error":{"code":-32600}}`,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			status, body := post(t, srv, tc.body)
+			if status != http.StatusOK {
+				t.Fatalf("got status %d, want %d", status, http.StatusOK)
+			}
+			checkSubset(t, body, tc.want)
+		})
+	}
+}
+
+// checkSubset fails unless every field of want is in got with the same value.
+func checkSubset(t *testing.T, got, want string) {
+	t.Helper()
+
+	var g, w interface{}
+	if err := json.Unmarshal([]byte(got), &g); err != nil {
+		t.Fatalf("%s: %s", got, err)
+	}
+	if err := json.Unmarshal([]byte(want), &w); err != nil {
+		t.Fatal(err)
+	}
+	if !subset(g, w) {
+		t.Fatalf("got %s, want %s", got, want)
+	}
+}
+
+func subset(got, want interface{}) bool {
+	wantObject, ok := want.(map[string]interface{})
+	if !ok {
+		return got == want
+	}
+	gotObject, ok := got.(map[string]interface{})
+	if !ok {
+		return false
+	}
+	for key, value := range wantObject {
+		if !subset(gotObject[key], value) {
+			return false
+		}
+	}
+	return true
+}
+
+func TestHTTPBatch(t *testing.T) {
+	srv, _ := newTestServer(t)
+
+	status, body := post(t, srv, `[
+		{"jsonrpc": "2.0", "id": 1, "method": "trustme_getCurrentBlock"},
+		{"jsonrpc": "2.0", "method": "trustme_subscribe", "params": ["`+address+`"]},
+		{"jsonrpc": "2.0", "id": 3, "method": "eth_blockNumber"}
+	]`)
+	if status != http.StatusOK {
+		t.Fatalf("got status %d, want %d", status, http.StatusOK)
+	}
+	// the notification is run without being answered
+	if got := errorCodes(t, body); len(got) != 2 || got[0] != 0 || got[1] != CodeMethodNotFound {
+		t.Fatalf("got error codes %v in %s, want a result then %d", got, body, CodeMethodNotFound)
+	}
+
+	_, body = post(t, srv, `[1, {"jsonrpc": "2.0", "id": 2, "method": "trustme_subscribe", "params": ["`+address+`"]}]`)
+	if got := errorCodes(t, body); len(got) != 2 || got[0] != CodeInvalidRequest || got[1] != CodeServerError {
+		t.Fatalf("got error codes %v in %s, want %d then %d for the subscription made by the notification",
+			got, body, CodeInvalidRequest, CodeServerError)
+	}
+}
+
+func TestHTTPNotifications(t *testing.T) {
+	srv, _ := newTestServer(t)
+
+	for _, body := range []string{
+		`{"jsonrpc": "2.0", "method": "trustme_getCurrentBlock"}`,
+		`{"jsonrpc": "2.0", "method": "eth_blockNumber"}`,
+		`[{"jsonrpc": "2.0", "method": "trustme_getCurrentBlock"}, {"jsonrpc": "2.0", "method": "eth_blockNumber"}]`,
+	} {
+		if status, got := post(t, srv, body); status != http.StatusNoContent || got != "" {
+			t.Fatalf("%s: got status %d with %q, want %d without body", body, status, got, http.StatusNoContent)
+		}
+	}
+}
+
+type wsClient struct {
+	t    *testing.T
+	conn *websocket.Conn
+}
+
+func dial(t *testing.T, srv *httptest.Server) *wsClient {
+	t.Helper()
+
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(srv.URL, "http")+endpoint, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+	return &wsClient{t: t, conn: conn}
+}
+
+// call sends a call and returns the next message, decoded into result.
+func (c *wsClient) call(id int, method string, params string, result interface{}) {
+	c.t.Helper()
+
+	if err := c.conn.WriteJSON(map[string]interface{}{
+		"jsonrpc": version, "id": id, "method": method, "params": json.RawMessage(params),
+	}); err != nil {
+		c.t.Fatal(err)
+	}
+
+	var resp struct {
+		ID     int             `json:"id"`
+		Result json.RawMessage `json:"result"`
+		Error  *Error          `json:"error"`
+	}
+	c.read(&resp)
+	if resp.ID != id || resp.Error != nil {
+		c.t.Fatalf("%s: got id %d, error %v", method, resp.ID, resp.Error)
+	}
+	if err := json.Unmarshal(resp.Result, result); err != nil {
+		c.t.Fatal(err)
+	}
+}
+
+func (c *wsClient) read(message interface{}) {
+	c.t.Helper()
+
+	_ = c.conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	if err := c.conn.ReadJSON(message); err != nil {
+		c.t.Fatal(err)
+	}
+}
+
+// notification reads the next message as a notification.
+func (c *wsClient) notification() (string, entity.EventPayload) {
+	c.t.Helper()
+
+	var message struct {
+		Method string `json:"method"`
+		Params struct {
+			Subscription string              `json:"subscription"`
+			Result       entity.EventPayload `json:"result"`
+		} `json:"params"`
+	}
+	c.read(&message)
+	if message.Method != notificationMethod {
+		c.t.Fatalf("got method %q, want %q", message.Method, notificationMethod)
+	}
+	return message.Params.Subscription, message.Params.Result
+}
+
+func TestWebSocketSubscriptions(t *testing.T) {
+	srv, bus := newTestServer(t)
+	client := dial(t, srv)
+
+	var subscribed map[string]interface{}
+	client.call(1, "trustme_subscribe", `["`+address+`"]`, &subscribed)
+
+	var activity, blocks string
+	client.call(2, "eth_subscribe", `["activity", {"addresses": ["`+address+`"], "events": ["tx"]}]`, &activity)
+	client.call(3, "eth_subscribe", `["blocks"]`, &blocks)
+	// the subscriptions receive events once their id is written, the answer of a later call comes after
+	var block int
+	client.call(4, "trustme_getCurrentBlock", `[]`, &block)
+
+	to := common.HexToAddress(address)
+	txEvent := entity.Event{
+		Type:    entity.EventTypeTx,
+		Address: address,
+		Record:  &entity.TxRecord{Address: address, Direction: entity.TxDirectionIn, Tx: types.NewTx(&types.LegacyTx{To: &to})},
+	}
+	blockEvent := entity.Event{Type: entity.EventTypeBlock, Block: &entity.BlockSummary{Number: 43}}
+
+	if err := bus.Publish(txEvent); err != nil {
+		t.Fatal(err)
+	}
+	if id, payload := client.notification(); id != activity || payload.Event != string(entity.EventTypeTx) || payload.Address != to.Hex() {
+		t.Fatalf("got %s event of %s for subscription %s, want a tx event of %s for %s", payload.Event, payload.Address, id, to.Hex(), activity)
+	}
+	if err := bus.Publish(blockEvent); err != nil {
+		t.Fatal(err)
+	}
+	if id, payload := client.notification(); id != blocks || payload.Event != string(entity.EventTypeBlock) {
+		t.Fatalf("got %s event for subscription %s, want a block event for %s", payload.Event, id, blocks)
+	}
+
+	var removed bool
+	client.call(5, "eth_unsubscribe", `["`+activity+`"]`, &removed)
+	if !removed {
+		t.Fatal("got the activity subscription not removed")
+	}
+	client.call(6, "eth_unsubscribe", `["`+activity+`"]`, &removed)
+	if removed {
+		t.Fatal("got the activity subscription removed twice")
+	}
+
+	// the tx event is not pushed anymore, the block event published after it is the next message
+	if err := bus.Publish(txEvent); err != nil {
+		t.Fatal(err)
+	}
+	if err := bus.Publish(blockEvent); err != nil {
+		t.Fatal(err)
+	}
+	if id, payload := client.notification(); id != blocks || payload.Event != string(entity.EventTypeBlock) {
+		t.Fatalf("got %s event for subscription %s, want a block event for %s", payload.Event, id, blocks)
+	}
+}
+
+func TestWebSocketSubscribeErrors(t *testing.T) {
+	srv, _ := newTestServer(t)
+	client := dial(t, srv)
+
+	for _, params := range []string{
+		`["pendingTransactions"]`,
+		`["activity", {"addresses": []}]`,
+		`["activity", {"addresses": ["0x1234"]}]`,
+		`["activity", {"addresses": ["` + address + `"], "events": ["nope"]}]`,
+		`["blocks", {}]`,
+	} {
+		if err := client.conn.WriteJSON(map[string]interface{}{
+			"jsonrpc": version, "id": 1, "method": "eth_subscribe", "params": json.RawMessage(params),
+		}); err != nil {
+			t.Fatal(err)
+		}
+
+		var resp response
+		client.read(&resp)
+		if resp.Error == nil || resp.Error.Code != CodeInvalidParams {
+			t.Fatalf("%s: got error %v, want code %d", params, resp.Error, CodeInvalidParams)
+		}
+	}
+}
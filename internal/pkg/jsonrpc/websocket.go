@@ -0,0 +1,239 @@
+package jsonrpc
+
+import (
+	"context"
+	"crypto/rand"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/gorilla/websocket"
+
+	"github.com/vuquang23/trustme/internal/pkg/api"
+	"github.com/vuquang23/trustme/internal/pkg/entity"
+	"github.com/vuquang23/trustme/internal/pkg/util/id"
+	"github.com/vuquang23/trustme/pkg/logger"
+)
+
+const (
+	subscriptionActivity = "activity"
+	subscriptionBlocks   = "blocks"
+
+	wsWriteTimeout = 10 * time.Second
+)
+
+type subscription struct {
+	id string
+	// addresses is nil for blocks subscriptions, which are not about an address.
+	addresses map[string]struct{}
+	events    map[entity.EventType]struct{}
+}
+
+func (s *subscription) accepts(event entity.Event) bool {
+	if _, ok := s.events[event.Type]; !ok {
+		return false
+	}
+	if s.addresses == nil {
+		return true
+	}
+	_, ok := s.addresses[event.Address]
+	return ok
+}
+
+func newSubscriptionID() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return hexutil.Encode(b)
+}
+
+// wsReply is an answer to the calls of one message. The subscriptions it creates only start receiving events once
+// it is written, so that a client never gets a notification before the id of its subscription.
+type wsReply struct {
+	message  interface{}
+	activate []*subscription
+}
+
+type wsConn struct {
+	cfg  Config
+	conn *websocket.Conn
+
+	mu            sync.RWMutex
+	subscriptions map[string]*subscription
+	// pending are the subscriptions created by the message being handled, only touched by the read loop.
+	pending []*subscription
+
+	replies chan wsReply
+	// done is closed when the read loop exits, stopped when the write loop does.
+	done    chan struct{}
+	stopped chan struct{}
+}
+
+func newWSConn(cfg Config, conn *websocket.Conn) *wsConn {
+	return &wsConn{
+		cfg:           cfg,
+		conn:          conn,
+		subscriptions: make(map[string]*subscription),
+		replies:       make(chan wsReply, 16),
+		done:          make(chan struct{}),
+		stopped:       make(chan struct{}),
+	}
+}
+
+func (c *wsConn) accepts(event entity.Event) bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	for _, sub := range c.subscriptions {
+		if sub.accepts(event) {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *wsConn) add(sub *subscription) error {
+	c.mu.RLock()
+	count := len(c.subscriptions) + len(c.pending)
+	c.mu.RUnlock()
+
+	if count >= c.cfg.MaxSubscriptions {
+		return fmt.Errorf("%w: at most %d subscriptions per connection", entity.ErrInvalidParams, c.cfg.MaxSubscriptions)
+	}
+
+	c.pending = append(c.pending, sub)
+	return nil
+}
+
+func (c *wsConn) remove(id string) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if _, ok := c.subscriptions[id]; !ok {
+		return false
+	}
+	delete(c.subscriptions, id)
+	return true
+}
+
+func (c *wsConn) activate(subs []*subscription) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, sub := range subs {
+		c.subscriptions[sub.id] = sub
+	}
+}
+
+// matching returns the ids of the subscriptions accepting an event.
+func (c *wsConn) matching(event entity.Event) []string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	var ids []string
+	for _, sub := range c.subscriptions {
+		if sub.accepts(event) {
+			ids = append(ids, sub.id)
+		}
+	}
+	return ids
+}
+
+func (c *wsConn) readLoop(ctx context.Context, s *Server) {
+	defer close(c.done)
+
+	c.conn.SetReadLimit(s.cfg.MaxRequestSize)
+	_ = c.conn.SetReadDeadline(time.Now().Add(2 * c.cfg.WSPingInterval))
+	c.conn.SetPongHandler(func(string) error {
+		return c.conn.SetReadDeadline(time.Now().Add(2 * c.cfg.WSPingInterval))
+	})
+
+	for {
+		_, body, err := c.conn.ReadMessage()
+		if err != nil {
+			if websocket.IsUnexpectedCloseError(err, websocket.CloseNormalClosure, websocket.CloseGoingAway) {
+				logger.Warnf(ctx, "websocket read failed: %s", err)
+			}
+			return
+		}
+
+		reply := wsReply{message: s.handle(ctx, c, body), activate: c.pending}
+		c.pending = nil
+		if reply.message == nil && len(reply.activate) == 0 {
+			continue
+		}
+
+		select {
+		case c.replies <- reply:
+		case <-c.stopped:
+			return
+		}
+	}
+}
+
+// writeLoop is the only writer of the connection: replies, notifications and pings.
+func (c *wsConn) writeLoop(ctx context.Context, events <-chan entity.Event) {
+	ping := time.NewTicker(c.cfg.WSPingInterval)
+	defer ping.Stop()
+	defer close(c.stopped)
+	defer c.conn.Close()
+
+	for {
+		var err error
+		select {
+		case <-c.done:
+			return
+
+		case reply := <-c.replies:
+			if reply.message != nil {
+				err = c.write(reply.message)
+			}
+			c.activate(reply.activate)
+
+		case event, ok := <-events:
+			if !ok {
+				logger.Warn(ctx, "websocket client too slow, disconnecting")
+				_ = c.conn.WriteControl(
+					websocket.CloseMessage,
+					websocket.FormatCloseMessage(websocket.CloseTryAgainLater, "slow consumer"),
+					time.Now().Add(wsWriteTimeout),
+				)
+				return
+			}
+			err = c.notify(event)
+
+		case <-ping.C:
+			err = c.conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(wsWriteTimeout))
+		}
+
+		if err != nil {
+			logger.Warnf(ctx, "websocket write failed: %s", err)
+			return
+		}
+	}
+}
+
+func (c *wsConn) notify(event entity.Event) error {
+	ids := c.matching(event)
+	if len(ids) == 0 {
+		return nil
+	}
+
+	payload := entity.NewEventPayload(id.New(), event, time.Now().UTC(), api.EventData(event))
+	for _, subID := range ids {
+		err := c.write(notification{
+			Version: version,
+			Method:  notificationMethod,
+			Params:  notificationParams{Subscription: subID, Result: payload},
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *wsConn) write(message interface{}) error {
+	_ = c.conn.SetWriteDeadline(time.Now().Add(wsWriteTimeout))
+	return c.conn.WriteJSON(message)
+}
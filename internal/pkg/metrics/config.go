@@ -0,0 +1,8 @@
+package metrics
+
+type Config struct {
+	// Enabled serves the Prometheus metrics on the HTTP server.
+	Enabled bool `default:"true"`
+	// Path of the metrics endpoint.
+	Path string `default:"/metrics"`
+}
@@ -0,0 +1,11 @@
+package metrics
+
+import "github.com/gin-gonic/gin"
+
+// SetupRoute serves the metrics on the HTTP server.
+func SetupRoute(engine *gin.Engine, cfg Config) {
+	if !cfg.Enabled {
+		return
+	}
+	engine.GET(cfg.Path, gin.WrapH(Handler()))
+}
@@ -0,0 +1,38 @@
+package metrics
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/vuquang23/trustme/internal/pkg/util/ethrpc"
+)
+
+// RPCTransport counts and times the JSON-RPC calls going through base, labelled with the method read from the
+// request body and the host of the node.
+func RPCTransport(base http.RoundTripper) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &rpcTransport{base: base}
+}
+
+type rpcTransport struct {
+	base http.RoundTripper
+}
+
+func (t *rpcTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	method := ethrpc.Method(req)
+
+	start := time.Now()
+	resp, err := t.base.RoundTrip(req)
+
+	status := "error"
+	if err == nil {
+		status = strconv.Itoa(resp.StatusCode)
+	}
+	RPCRequests.WithLabelValues(method, req.URL.Host, status).Inc()
+	RPCDuration.WithLabelValues(method, req.URL.Host).Observe(time.Since(start).Seconds())
+
+	return resp, err
+}
@@ -0,0 +1,90 @@
+package parser
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/vuquang23/trustme/internal/pkg/entity"
+	"github.com/vuquang23/trustme/internal/pkg/tracing"
+)
+
+// Backfill saves the matched transactions of the blocks from to to, stopping at the first failure. Entries already
+// saved are kept and no event is published. With addresses, the transactions of those addresses are saved instead
+// of the ones of the subscribed addresses. It returns the number of saved records.
+func (p *Parser) Backfill(ctx context.Context, from, to uint64, addresses []string) (int, error) {
+	if from > to {
+		return 0, fmt.Errorf("%w: from %d is after to %d", entity.ErrInvalidParams, from, to)
+	}
+
+	match := p.subscriberRepo.IsSubscriber
+	if len(addresses) > 0 {
+		set := make(map[string]struct{}, len(addresses))
+		for _, address := range addresses {
+			set[address] = struct{}{}
+		}
+		match = func(address string) bool {
+			_, ok := set[address]
+			return ok
+		}
+	}
+
+	p.sync.setBackfill(&entity.BackfillProgress{From: from, To: to, Current: from})
+	defer p.sync.setBackfill(nil)
+
+	saved := 0
+	for n := from; n <= to; n++ {
+		p.sync.backfillAt(n)
+
+		records, err := p.backfillBlock(ctx, n, match)
+		if err != nil {
+			return saved, fmt.Errorf("block %d: %w", n, err)
+		}
+		saved += len(records)
+	}
+	return saved, nil
+}
+
+// ReprocessBlock processes a block again right away, replacing its entries, without publishing events.
+func (p *Parser) ReprocessBlock(ctx context.Context, number uint64) error {
+	return p.reprocessBlock(ctx, number, false)
+}
+
+func (p *Parser) backfillBlock(ctx context.Context, number uint64, match func(address string) bool) (_ []*entity.TxRecord, err error) {
+	ctx, span := tracing.Tracer().Start(ctx, "parser.backfillBlock", trace.WithAttributes(attribute.Int64("block.number", int64(number))))
+	defer func() { tracing.End(span, err) }()
+
+	_, block, receipts, err := p.fetchBlockByNumber(ctx, number)
+	if err != nil {
+		return nil, err
+	}
+
+	senders, err := p.recoverSenders(ctx, block)
+	if err != nil {
+		return nil, err
+	}
+
+	parties, _ := p.matchBlock(ctx, block, senders, match)
+
+	p.blockMu.Lock()
+	defer p.blockMu.Unlock()
+
+	return p.saveTxs(block, receipts, senders, parties, false)
+}
+
+// fetchBlockByNumber gets the canonical block at a height and its receipts. The hash is set once the header is
+// found, even when fetching the block fails.
+func (p *Parser) fetchBlockByNumber(ctx context.Context, number uint64) (common.Hash, *types.Block, types.Receipts, error) {
+	header, err := p.rpcClient.HeaderByNumber(ctx, new(big.Int).SetUint64(number))
+	if err != nil {
+		return common.Hash{}, nil, nil, err
+	}
+
+	block, receipts, err := p.fetchBlock(ctx, header.Hash())
+	return header.Hash(), block, receipts, err
+}
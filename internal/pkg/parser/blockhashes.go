@@ -0,0 +1,54 @@
+package parser
+
+import (
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// blockHashesWindow is the number of recent blocks whose hash is remembered.
+const blockHashesWindow = 256
+
+// blockHashes remembers the hash of the recently processed blocks, so a block delivered again is told apart from
+// the one replacing it even when it has no matched transaction. It lives in memory only, older blocks are looked
+// up from the hash of their stored records.
+type blockHashes struct {
+	mu     sync.Mutex
+	hashes map[uint64]common.Hash
+}
+
+func newBlockHashes() *blockHashes {
+	return &blockHashes{
+		hashes: make(map[uint64]common.Hash),
+	}
+}
+
+func (h *blockHashes) add(blockNumber uint64, hash common.Hash) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.hashes[blockNumber] = hash
+	if len(h.hashes) <= blockHashesWindow {
+		return
+	}
+	for n := range h.hashes {
+		if n+blockHashesWindow <= blockNumber {
+			delete(h.hashes, n)
+		}
+	}
+}
+
+func (h *blockHashes) get(blockNumber uint64) (common.Hash, bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	hash, ok := h.hashes[blockNumber]
+	return hash, ok
+}
+
+func (h *blockHashes) forget(blockNumber uint64) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	delete(h.hashes, blockNumber)
+}
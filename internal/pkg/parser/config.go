@@ -0,0 +1,8 @@
+package parser
+
+type Config struct {
+	// Confirmations is the number of blocks, the including one counted, after which a confirmation event is published.
+	Confirmations uint64 `default:"12"`
+	// FailedBlocks is the number of failed blocks kept for inspection, the oldest are dropped first.
+	FailedBlocks int `default:"100"`
+}
@@ -0,0 +1,73 @@
+package parser
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/vuquang23/trustme/internal/pkg/entity"
+)
+
+// confirmationTracker keeps the records saved from recent blocks until they are confirmed or reorged out.
+// It lives in memory only, records pending at shutdown never get a confirmation event.
+type confirmationTracker struct {
+	mu      sync.Mutex
+	pending map[uint64][]*entity.TxRecord
+}
+
+func newConfirmationTracker() *confirmationTracker {
+	return &confirmationTracker{
+		pending: make(map[uint64][]*entity.TxRecord),
+	}
+}
+
+func (t *confirmationTracker) track(blockNumber uint64, records []*entity.TxRecord) {
+	if len(records) == 0 {
+		return
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.pending[blockNumber] = append(t.pending[blockNumber], records...)
+}
+
+// release removes and returns the records of every block up to blockNumber.
+func (t *confirmationTracker) release(blockNumber uint64) []*entity.TxRecord {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	var records []*entity.TxRecord
+	for n, blockRecords := range t.pending {
+		if n <= blockNumber {
+			records = append(records, blockRecords...)
+			delete(t.pending, n)
+		}
+	}
+
+	sort.Slice(records, func(i, j int) bool {
+		if records[i].BlockNumber != records[j].BlockNumber {
+			return records[i].BlockNumber < records[j].BlockNumber
+		}
+		return records[i].TxIndex < records[j].TxIndex
+	})
+	return records
+}
+
+func (t *confirmationTracker) drop(blockNumber uint64) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	delete(t.pending, blockNumber)
+}
+
+// len counts the records waiting for their confirmation.
+func (t *confirmationTracker) len() int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	n := 0
+	for _, records := range t.pending {
+		n += len(records)
+	}
+	return n
+}
@@ -0,0 +1,243 @@
+package parser
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/vuquang23/trustme/internal/pkg/entity"
+	"github.com/vuquang23/trustme/internal/pkg/metrics"
+	"github.com/vuquang23/trustme/internal/pkg/tracing"
+	"github.com/vuquang23/trustme/pkg/logger"
+)
+
+var errReconnectRequested = errors.New("reconnect requested")
+
+// controlState holds what the operators change on a running parser: the pause switch and the reprocess.
+type controlState struct {
+	mu sync.Mutex
+	// runCtx is the context of Run, reprocesses are stopped with it.
+	runCtx context.Context
+	paused bool
+	// resumed is closed while the parser is not paused.
+	resumed         chan struct{}
+	cancelReprocess context.CancelFunc
+}
+
+func newControlState() controlState {
+	resumed := make(chan struct{})
+	close(resumed)
+	return controlState{resumed: resumed}
+}
+
+// Pause stops processing new blocks. New heads are still received so the status stays up to date.
+func (p *Parser) Pause() {
+	p.control.mu.Lock()
+	defer p.control.mu.Unlock()
+
+	if p.control.paused {
+		return
+	}
+	p.control.paused = true
+	p.control.resumed = make(chan struct{})
+}
+
+// Resume processes new blocks again. The blocks skipped while paused are processed before the first new block.
+func (p *Parser) Resume() {
+	p.control.mu.Lock()
+	defer p.control.mu.Unlock()
+
+	if !p.control.paused {
+		return
+	}
+	p.control.paused = false
+	close(p.control.resumed)
+}
+
+func (p *Parser) Paused() bool {
+	p.control.mu.Lock()
+	defer p.control.mu.Unlock()
+
+	return p.control.paused
+}
+
+// waitResumed blocks while the parser is paused.
+func (p *Parser) waitResumed(ctx context.Context) error {
+	p.control.mu.Lock()
+	resumed := p.control.resumed
+	p.control.mu.Unlock()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-resumed:
+		return nil
+	}
+}
+
+// Reconnect drops the new heads subscription, a new one is made right away.
+func (p *Parser) Reconnect() {
+	select {
+	case p.reconnect <- struct{}{}:
+	default:
+	}
+}
+
+// Reprocess processes the blocks from to to again in the background, replacing their entries with the ones
+// of the canonical blocks. Only blocks up to the checkpoint can be reprocessed, newer ones are left to the live
+// processing. With publish the events are published again, consumers see them twice for blocks that were
+// processed before; without it no event is published at all.
+func (p *Parser) Reprocess(from, to uint64, publish bool) error {
+	if from > to {
+		return fmt.Errorf("%w: from %d is after to %d", entity.ErrInvalidParams, from, to)
+	}
+	if checkpoint := uint64(p.currentBlock.Load()); to > checkpoint {
+		return fmt.Errorf("%w: block %d is past the checkpoint %d", entity.ErrInvalidParams, to, checkpoint)
+	}
+
+	p.control.mu.Lock()
+	defer p.control.mu.Unlock()
+
+	if p.control.runCtx == nil {
+		return fmt.Errorf("%w: parser is not running", entity.ErrBackendUnavailable)
+	}
+	if p.control.cancelReprocess != nil {
+		return entity.ErrReprocessRunning
+	}
+
+	ctx, cancel := context.WithCancel(p.control.runCtx)
+	p.control.cancelReprocess = cancel
+	p.sync.setBackfill(&entity.BackfillProgress{From: from, To: to, Current: from})
+
+	go p.reprocess(ctx, from, to, publish)
+	return nil
+}
+
+// CancelReprocess stops the running reprocess, it tells whether there was one.
+func (p *Parser) CancelReprocess() bool {
+	p.control.mu.Lock()
+	defer p.control.mu.Unlock()
+
+	if p.control.cancelReprocess == nil {
+		return false
+	}
+	p.control.cancelReprocess()
+	return true
+}
+
+func (p *Parser) reprocess(ctx context.Context, from, to uint64, publish bool) {
+	defer func() {
+		p.control.mu.Lock()
+		p.control.cancelReprocess()
+		p.control.cancelReprocess = nil
+		p.control.mu.Unlock()
+
+		p.sync.setBackfill(nil)
+	}()
+
+	log := logger.WithFields(ctx, logger.Fields{"from": from, "to": to})
+	log.Info("reprocess blocks")
+
+	for n := from; n <= to; n++ {
+		if err := p.waitResumed(ctx); err != nil {
+			log.Info("reprocess stopped")
+			return
+		}
+		p.sync.backfillAt(n)
+
+		if err := p.reprocessBlock(ctx, n, publish); err != nil {
+			if ctx.Err() != nil {
+				log.Info("reprocess stopped")
+				return
+			}
+			metrics.BlockFailures.Inc()
+			logger.WithFields(ctx, logger.Fields{
+				"blockNumber": n,
+				"errorMsg":    err.Error(),
+			}).Warn("failed to reprocess block")
+		}
+	}
+
+	log.Info("reprocess done")
+}
+
+func (p *Parser) reprocessBlock(ctx context.Context, number uint64, publish bool) (err error) {
+	ctx, span := tracing.Tracer().Start(ctx, "parser.reprocessBlock", trace.WithAttributes(attribute.Int64("block.number", int64(number))))
+	var hash common.Hash
+	defer func() {
+		tracing.End(span, err)
+		p.recordResult(hash, number, err)
+	}()
+
+	hash, block, receipts, err := p.fetchBlockByNumber(ctx, number)
+	if err != nil {
+		return err
+	}
+	span.SetAttributes(attribute.String("block.hash", hash.Hex()))
+
+	senders, err := p.recoverSenders(ctx, block)
+	if err != nil {
+		return err
+	}
+
+	subscribers, matchedTxs := p.matchBlock(ctx, block, senders, p.subscriberRepo.IsSubscriber)
+
+	p.blockMu.Lock()
+	defer p.blockMu.Unlock()
+
+	records, err := p.replaceBlock(ctx, block, receipts, senders, subscribers, publish)
+	if err != nil {
+		return err
+	}
+
+	metrics.BlocksProcessed.Inc()
+	metrics.TxsScanned.Add(float64(len(block.Transactions())))
+	metrics.TxsMatched.Add(float64(matchedTxs))
+
+	if publish {
+		p.confirmations.track(number, records)
+	}
+
+	return nil
+}
+
+// recordResult keeps a failed block for inspection, or forgets its earlier failures once it is processed.
+func (p *Parser) recordResult(hash common.Hash, number uint64, err error) {
+	if err != nil {
+		p.failures.add(hash, number, err)
+		return
+	}
+	p.failures.resolve(hash, number)
+}
+
+// FailedBlocks returns the blocks that failed to be processed and were not processed since, oldest first.
+func (p *Parser) FailedBlocks() []entity.FailedBlock {
+	return p.failures.list()
+}
+
+func (p *Parser) ClearFailedBlocks() {
+	p.failures.clear()
+}
+
+// Internals returns the state of the parser, for the operators.
+func (p *Parser) Internals() (*entity.ParserInternals, error) {
+	checkpoint, err := p.checkpointRepo.GetCheckpoint()
+	if err != nil {
+		return nil, entity.BackendError(err)
+	}
+
+	return &entity.ParserInternals{
+		Paused:               p.Paused(),
+		Checkpoint:           checkpoint,
+		QueueDepth:           len(p.headerChan),
+		QueueCapacity:        cap(p.headerChan),
+		PendingConfirmations: p.confirmations.len(),
+		FailedBlocks:         p.failures.len(),
+		Sync:                 p.Status(),
+	}, nil
+}
@@ -0,0 +1,131 @@
+package parser
+
+import (
+	"math/big"
+	"strconv"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+
+	"github.com/vuquang23/trustme/internal/pkg/entity"
+)
+
+var (
+	topicTransfer       = crypto.Keccak256Hash([]byte("Transfer(address,address,uint256)"))
+	topicApproval       = crypto.Keccak256Hash([]byte("Approval(address,address,uint256)"))
+	topicApprovalForAll = crypto.Keccak256Hash([]byte("ApprovalForAll(address,address,bool)"))
+	topicDeposit        = crypto.Keccak256Hash([]byte("Deposit(address,uint256)"))
+	topicWithdrawal     = crypto.Keccak256Hash([]byte("Withdrawal(address,uint256)"))
+)
+
+// DecodeLog decodes ERC-20/ERC-721 Transfer and Approval, ApprovalForAll and WETH Deposit/Withdrawal events.
+// ERC-20 and ERC-721 share the Transfer and Approval signatures, they are told apart by the indexed amount.
+func DecodeLog(log *types.Log) entity.DecodedLog {
+	decoded := entity.DecodedLog{Log: log}
+	if len(log.Topics) == 0 {
+		return decoded
+	}
+
+	switch {
+	case (log.Topics[0] == topicTransfer || log.Topics[0] == topicApproval) && len(log.Topics) == 3 && len(log.Data) == 32:
+		first, second := "from", "to"
+		decoded.Event = "Transfer"
+		if log.Topics[0] == topicApproval {
+			first, second = "owner", "spender"
+			decoded.Event = "Approval"
+		}
+		decoded.Args = map[string]string{
+			first:   topicAddress(log.Topics[1]).Hex(),
+			second:  topicAddress(log.Topics[2]).Hex(),
+			"value": new(big.Int).SetBytes(log.Data).String(),
+		}
+
+	case (log.Topics[0] == topicTransfer || log.Topics[0] == topicApproval) && len(log.Topics) == 4:
+		first, second := "from", "to"
+		decoded.Event = "Transfer"
+		if log.Topics[0] == topicApproval {
+			first, second = "owner", "approved"
+			decoded.Event = "Approval"
+		}
+		decoded.Args = map[string]string{
+			first:     topicAddress(log.Topics[1]).Hex(),
+			second:    topicAddress(log.Topics[2]).Hex(),
+			"tokenId": log.Topics[3].Big().String(),
+		}
+
+	case log.Topics[0] == topicApprovalForAll && len(log.Topics) == 3 && len(log.Data) == 32:
+		decoded.Event = "ApprovalForAll"
+		decoded.Args = map[string]string{
+			"owner":    topicAddress(log.Topics[1]).Hex(),
+			"operator": topicAddress(log.Topics[2]).Hex(),
+			"approved": strconv.FormatBool(new(big.Int).SetBytes(log.Data).Sign() != 0),
+		}
+
+	case (log.Topics[0] == topicDeposit || log.Topics[0] == topicWithdrawal) && len(log.Topics) == 2 && len(log.Data) == 32:
+		party := "dst"
+		decoded.Event = "Deposit"
+		if log.Topics[0] == topicWithdrawal {
+			party = "src"
+			decoded.Event = "Withdrawal"
+		}
+		decoded.Args = map[string]string{
+			party: topicAddress(log.Topics[1]).Hex(),
+			"wad": new(big.Int).SetBytes(log.Data).String(),
+		}
+	}
+
+	return decoded
+}
+
+// DecodeTokenTransfer decodes ERC-20 and ERC-721 Transfer logs.
+func DecodeTokenTransfer(log *types.Log) (*entity.TokenTransfer, bool) {
+	if len(log.Topics) == 0 || log.Topics[0] != topicTransfer {
+		return nil, false
+	}
+
+	transfer := &entity.TokenTransfer{
+		Token:       log.Address,
+		TxHash:      log.TxHash,
+		LogIndex:    log.Index,
+		BlockNumber: log.BlockNumber,
+		BlockHash:   log.BlockHash,
+		TxIndex:     log.TxIndex,
+	}
+
+	switch {
+	case len(log.Topics) == 3 && len(log.Data) == 32:
+		transfer.Value = new(big.Int).SetBytes(log.Data)
+	case len(log.Topics) == 4:
+		transfer.TokenID = log.Topics[3].Big()
+	default:
+		return nil, false
+	}
+	transfer.From = topicAddress(log.Topics[1])
+	transfer.To = topicAddress(log.Topics[2])
+
+	return transfer, true
+}
+
+// DecodeTokenTransfers returns the token Transfer logs among logs.
+func DecodeTokenTransfers(logs []*types.Log) []*entity.TokenTransfer {
+	transfers := []*entity.TokenTransfer{}
+	for _, log := range logs {
+		if transfer, ok := DecodeTokenTransfer(log); ok {
+			transfers = append(transfers, transfer)
+		}
+	}
+	return transfers
+}
+
+func DecodeLogs(logs []*types.Log) []entity.DecodedLog {
+	decoded := make([]entity.DecodedLog, 0, len(logs))
+	for _, log := range logs {
+		decoded = append(decoded, DecodeLog(log))
+	}
+	return decoded
+}
+
+func topicAddress(topic common.Hash) common.Address {
+	return common.BytesToAddress(topic.Bytes())
+}
@@ -0,0 +1,88 @@
+package parser
+
+import (
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/vuquang23/trustme/internal/pkg/entity"
+)
+
+// failedBlocks keeps the last blocks that failed to be processed, oldest first, for the operators to inspect.
+type failedBlocks struct {
+	mu     sync.Mutex
+	size   int
+	blocks []*entity.FailedBlock
+}
+
+func newFailedBlocks(size int) *failedBlocks {
+	return &failedBlocks{size: size}
+}
+
+// add records a failure, counting it as another attempt when the block already failed.
+func (f *failedBlocks) add(hash common.Hash, number uint64, err error) {
+	if f.size <= 0 {
+		return
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	now := time.Now().UTC()
+	block := &entity.FailedBlock{Hash: hash, Number: number, FirstFailedAt: now}
+	for i, b := range f.blocks {
+		if b.Hash == hash && b.Number == number {
+			block = b
+			f.blocks = append(f.blocks[:i], f.blocks[i+1:]...)
+			break
+		}
+	}
+	block.Error = err.Error()
+	block.Attempts++
+	block.LastFailedAt = now
+
+	f.blocks = append(f.blocks, block)
+	if len(f.blocks) > f.size {
+		f.blocks = f.blocks[len(f.blocks)-f.size:]
+	}
+}
+
+// resolve removes the failures of a block once it is processed, matched by hash or by number.
+func (f *failedBlocks) resolve(hash common.Hash, number uint64) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	blocks := f.blocks[:0]
+	for _, b := range f.blocks {
+		if b.Hash != hash && b.Number != number {
+			blocks = append(blocks, b)
+		}
+	}
+	f.blocks = blocks
+}
+
+func (f *failedBlocks) list() []entity.FailedBlock {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	blocks := make([]entity.FailedBlock, 0, len(f.blocks))
+	for _, b := range f.blocks {
+		blocks = append(blocks, *b)
+	}
+	return blocks
+}
+
+func (f *failedBlocks) len() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return len(f.blocks)
+}
+
+func (f *failedBlocks) clear() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.blocks = nil
+}
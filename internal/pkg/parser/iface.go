@@ -1,15 +1,41 @@
 package parser
 
 import (
-	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/vuquang23/trustme/internal/pkg/entity"
 )
 
 type ISubscriberRepository interface {
-	Create(address string) error
+	Create(subscription *entity.Subscription) error
+	Delete(address string) error
+	// Get returns nil when the address is not subscribed.
+	Get(address string) (*entity.Subscription, error)
 	IsSubscriber(address string) bool
+	// List returns subscriptions ordered by address, starting after cursor.
+	List(cursor string, limit int) (*entity.SubscriptionPage, error)
 }
 
 type ITxRepository interface {
-	SaveTx(address string, tx *types.Transaction) error
-	GetTxs(address string) ([]*types.Transaction, error)
+	// SaveTx is idempotent on (record.Address, tx hash): saving a transaction again replaces the stored entry.
+	SaveTx(record *entity.TxRecord) error
+	GetTxs(address string) ([]*entity.TxRecord, error)
+	QueryTxs(address string, query entity.TxQuery) (*entity.TxPage, error)
+	// GetTxsByHash returns the records of a transaction under every address it is indexed for.
+	GetTxsByHash(hash common.Hash) ([]*entity.TxRecord, error)
+	// GetBlockTxs returns every entry indexed at the given block.
+	GetBlockTxs(blockNumber uint64) ([]*entity.TxRecord, error)
+	// DeleteBlockTxs removes and returns every entry indexed at the given block, used when the block is reorged out.
+	DeleteBlockTxs(blockNumber uint64) ([]*entity.TxRecord, error)
+}
+
+type ICheckpointRepository interface {
+	GetCheckpoint() (uint64, error)
+	SaveCheckpoint(blockNumber uint64) error
+}
+
+type IEventPublisher interface {
+	// Publish fails when the event could not be handed over, the block is then processed again
+	// before its checkpoint is saved.
+	Publish(event entity.Event) error
 }
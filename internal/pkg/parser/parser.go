@@ -2,41 +2,98 @@ package parser
 
 import (
 	"context"
-	"strings"
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
 	"sync/atomic"
 	"time"
 
+	"github.com/ethereum/go-ethereum"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/rpc"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 	"golang.org/x/sync/errgroup"
 
+	"github.com/vuquang23/trustme/internal/pkg/entity"
+	"github.com/vuquang23/trustme/internal/pkg/metrics"
+	"github.com/vuquang23/trustme/internal/pkg/tracing"
 	"github.com/vuquang23/trustme/pkg/logger"
 )
 
 type Parser struct {
+	cfg Config
+
 	rpcClient *ethclient.Client
 	wsClient  *ethclient.Client
 
 	currentBlock atomic.Int64
+	sync         syncState
+	control      controlState
 
 	subscriberRepo ISubscriberRepository
 	txRepo         ITxRepository
+	checkpointRepo ICheckpointRepository
+
+	publisher     IEventPublisher
+	confirmations *confirmationTracker
+	blockHashes   *blockHashes
+	failures      *failedBlocks
 
-	blockHashChan chan common.Hash
+	headerChan chan *types.Header
+	reconnect  chan struct{}
+	// blockMu serializes the writes of the live processing and of the reprocess.
+	blockMu sync.Mutex
 }
 
-func New(rpcClient, wsClient *ethclient.Client, subscriberRepo ISubscriberRepository, txRepo ITxRepository) *Parser {
+func New(
+	cfg Config,
+	rpcClient, wsClient *ethclient.Client,
+	subscriberRepo ISubscriberRepository,
+	txRepo ITxRepository,
+	checkpointRepo ICheckpointRepository,
+	publisher IEventPublisher,
+) *Parser {
 	return &Parser{
+		cfg:            cfg,
 		rpcClient:      rpcClient,
 		wsClient:       wsClient,
 		subscriberRepo: subscriberRepo,
 		txRepo:         txRepo,
-		blockHashChan:  make(chan common.Hash, 10),
+		checkpointRepo: checkpointRepo,
+		publisher:      publisher,
+		confirmations:  newConfirmationTracker(),
+		blockHashes:    newBlockHashes(),
+		failures:       newFailedBlocks(cfg.FailedBlocks),
+		control:        newControlState(),
+		headerChan:     make(chan *types.Header, 10),
+		reconnect:      make(chan struct{}, 1),
 	}
 }
 
 func (p *Parser) Run(ctx context.Context) error {
+	checkpoint, err := p.checkpointRepo.GetCheckpoint()
+	if err != nil {
+		return err
+	}
+	p.currentBlock.Store(int64(checkpoint))
+	p.updateLagMetrics()
+
+	p.control.mu.Lock()
+	p.control.runCtx = ctx
+	p.control.mu.Unlock()
+
+	// the blocks produced while the parser was stopped are processed before following the new heads
+	if err := p.catchUpHead(ctx); err != nil {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		logger.WithFields(ctx, logger.Fields{"errorMsg": err.Error()}).Warn("failed to catch up with the chain head")
+	}
+
 	var errgroup errgroup.Group
 
 	errgroup.Go(func() error { return p.listenBlocks(ctx) })
@@ -47,11 +104,19 @@ func (p *Parser) Run(ctx context.Context) error {
 
 func (p *Parser) listenBlocks(ctx context.Context) error {
 	f := func() error {
+		// a new subscription is made anyway, drop the reconnect asked in between
+		select {
+		case <-p.reconnect:
+		default:
+		}
+
 		headers := make(chan *types.Header)
 		sub, err := p.wsClient.SubscribeNewHead(ctx, headers)
 		if err != nil {
 			return err
 		}
+		defer sub.Unsubscribe()
+		p.sync.wsConnected()
 
 		for {
 			select {
@@ -60,11 +125,19 @@ func (p *Parser) listenBlocks(ctx context.Context) error {
 				return ctx.Err()
 
 			case err := <-sub.Err():
+				if err == nil {
+					err = errors.New("subscription closed")
+				}
 				return err
 
+			case <-p.reconnect:
+				return errReconnectRequested
+
 			case header := <-headers:
-				p.currentBlock.Store(header.Number.Int64())
-				p.blockHashChan <- header.Hash()
+				p.sync.observeHead(header.Number.Uint64(), true)
+				p.updateLagMetrics()
+				p.headerChan <- header
+				metrics.BlockQueueDepth.Set(float64(len(p.headerChan)))
 			}
 		}
 	}
@@ -75,6 +148,12 @@ func (p *Parser) listenBlocks(ctx context.Context) error {
 			if err == ctx.Err() {
 				return ctx.Err()
 			}
+			p.sync.wsDisconnected(err)
+			metrics.WSReconnects.Inc()
+			if err == errReconnectRequested {
+				continue
+			}
+			logger.WithFields(ctx, logger.Fields{"errorMsg": err.Error()}).Warn("new heads subscription failed")
 		}
 
 		time.Sleep(3 * time.Second)
@@ -88,75 +167,689 @@ func (p *Parser) handleBlocks(ctx context.Context) error {
 			logger.Infof(ctx, "stop handling blocks")
 			return ctx.Err()
 
-		case h := <-p.blockHashChan:
+		case header := <-p.headerChan:
+			metrics.BlockQueueDepth.Set(float64(len(p.headerChan)))
+			h, number := header.Hash(), header.Number.Uint64()
+			if p.Paused() {
+				logger.WithFields(ctx, logger.Fields{"hash": h.Hex()}).Info("skip block while paused")
+				continue
+			}
 			logger.WithFields(ctx, logger.Fields{"hash": h.Hex()}).Info("new block")
-			if err := p.handleBlock(ctx, h); err != nil {
+
+			// the checkpoint never moves past a block that was not processed, the missed ones go first
+			if err := p.catchUp(ctx, number-1); err != nil {
 				logger.WithFields(ctx, logger.Fields{
 					"hash":     h.Hex(),
 					"errorMsg": err.Error(),
-				}).Warn("failed to handle block")
+				}).Warn("failed to process the missed blocks, skip block")
+				continue
 			}
+
+			_ = p.processBlock(ctx, h, number)
 		}
 	}
 }
 
-func (p *Parser) handleBlock(ctx context.Context, hash common.Hash) error {
-	block, err := p.rpcClient.BlockByHash(ctx, hash)
+// catchUpHead processes the blocks after the checkpoint up to the chain head.
+func (p *Parser) catchUpHead(ctx context.Context) error {
+	head, err := p.ChainHead(ctx)
+	if err != nil {
+		return err
+	}
+	return p.catchUp(ctx, head)
+}
+
+// catchUp processes the blocks after the checkpoint up to to: the ones missed while the parser was stopped,
+// disconnected or paused, and the ones that failed. It stops at the first failing block, tried again on the
+// next call. Without a checkpoint the parser starts from the first new head and there is nothing to catch up.
+func (p *Parser) catchUp(ctx context.Context, to uint64) error {
+	checkpoint := uint64(p.currentBlock.Load())
+	if checkpoint == 0 || checkpoint >= to {
+		return nil
+	}
+
+	logger.WithFields(ctx, logger.Fields{"from": checkpoint + 1, "to": to}).Info("catch up blocks")
+	for n := checkpoint + 1; n <= to; n++ {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		header, err := p.rpcClient.HeaderByNumber(ctx, new(big.Int).SetUint64(n))
+		if err != nil {
+			return fmt.Errorf("block %d: %w", n, err)
+		}
+		if err := p.processBlock(ctx, header.Hash(), n); err != nil {
+			return fmt.Errorf("block %d: %w", n, err)
+		}
+	}
+	return nil
+}
+
+// processBlock handles a block of the live processing, keeping it for inspection when it fails.
+func (p *Parser) processBlock(ctx context.Context, hash common.Hash, number uint64) error {
+	startTime := time.Now()
+	p.blockMu.Lock()
+	err := p.handleBlock(ctx, hash)
+	p.blockMu.Unlock()
+	metrics.BlockDuration.Observe(time.Since(startTime).Seconds())
+	p.recordResult(hash, number, err)
+	if err != nil {
+		metrics.BlockFailures.Inc()
+		logger.WithFields(ctx, logger.Fields{
+			"hash":     hash.Hex(),
+			"errorMsg": err.Error(),
+		}).Warn("failed to handle block")
+	}
+	return err
+}
+
+func (p *Parser) handleBlock(ctx context.Context, hash common.Hash) (err error) {
+	ctx, span := tracing.Tracer().Start(ctx, "parser.handleBlock", trace.WithAttributes(attribute.String("block.hash", hash.Hex())))
+	defer func() { tracing.End(span, err) }()
+
+	block, receipts, err := p.fetchBlock(ctx, hash)
+	if err != nil {
+		return err
+	}
+	span.SetAttributes(attribute.Int64("block.number", block.Number().Int64()), attribute.Int("block.txs", len(block.Transactions())))
+
+	processed, err := p.isProcessed(block)
 	if err != nil {
 		return err
 	}
+	if processed {
+		// the same block delivered again, after a reconnect or a reorg back to it
+		logger.WithFields(ctx, logger.Fields{"hash": hash.Hex()}).Info("skip block already processed")
+		return nil
+	}
+
+	senders, err := p.recoverSenders(ctx, block)
+	if err != nil {
+		return err
+	}
+
+	subscribers, matchedTxs := p.matchBlock(ctx, block, senders, p.subscriberRepo.IsSubscriber)
+
+	records, checkpoint, err := p.saveBlock(ctx, block, receipts, senders, subscribers)
+	if err != nil {
+		return err
+	}
+	p.currentBlock.Store(int64(checkpoint))
+	p.sync.processed(block.Time())
+
+	metrics.BlocksProcessed.Inc()
+	metrics.TxsScanned.Add(float64(len(block.Transactions())))
+	metrics.TxsMatched.Add(float64(matchedTxs))
+	p.updateLagMetrics()
+
+	p.confirmations.track(block.NumberU64(), records)
+	if err := p.publishConfirmations(block.NumberU64()); err != nil {
+		// the block is saved, the confirmations left are published along with the next one
+		logger.WithFields(ctx, logger.Fields{"errorMsg": err.Error()}).Warn("failed to publish confirmations")
+	}
+
+	return nil
+}
+
+// fetchBlock gets a block and the receipts of its transactions.
+func (p *Parser) fetchBlock(ctx context.Context, hash common.Hash) (_ *types.Block, _ types.Receipts, err error) {
+	ctx, span := tracing.Tracer().Start(ctx, "parser.fetchBlock")
+	defer func() { tracing.End(span, err) }()
+
+	block, err := p.rpcClient.BlockByHash(ctx, hash)
+	if err != nil {
+		return nil, nil, err
+	}
 
 	logger.WithFields(ctx, logger.Fields{
 		"hash": hash.Hex(),
 	}).Info("get block successfully")
 
-	for _, tx := range block.Transactions() {
-		from, err := types.Sender(types.LatestSignerForChainID(tx.ChainId()), tx)
+	receipts, err := p.rpcClient.BlockReceipts(ctx, rpc.BlockNumberOrHashWithHash(hash, false))
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(receipts) != len(block.Transactions()) {
+		return nil, nil, fmt.Errorf("got %d receipts for %d transactions", len(receipts), len(block.Transactions()))
+	}
+
+	return block, receipts, nil
+}
+
+func (p *Parser) recoverSenders(ctx context.Context, block *types.Block) (_ []common.Address, err error) {
+	_, span := tracing.Tracer().Start(ctx, "parser.recoverSenders")
+	defer func() { tracing.End(span, err) }()
+
+	senders := make([]common.Address, len(block.Transactions()))
+	for i, tx := range block.Transactions() {
+		if senders[i], err = types.Sender(types.LatestSignerForChainID(tx.ChainId()), tx); err != nil {
+			return nil, err
+		}
+	}
+	return senders, nil
+}
+
+// matchBlock returns the matched addresses of each transaction, and the number of transactions with any.
+func (p *Parser) matchBlock(
+	ctx context.Context,
+	block *types.Block,
+	senders []common.Address,
+	match func(address string) bool,
+) ([][]string, int) {
+	_, span := tracing.Tracer().Start(ctx, "parser.matchBlock")
+	defer span.End()
+
+	subscribers := make([][]string, len(block.Transactions()))
+	matchedTxs := 0
+	for i, tx := range block.Transactions() {
+		subscribers[i] = matchParties(senders[i], tx.To(), match)
+		if len(subscribers[i]) > 0 {
+			matchedTxs++
+		}
+	}
+	span.SetAttributes(attribute.Int("block.matched_txs", matchedTxs))
+	return subscribers, matchedTxs
+}
+
+// isProcessed tells whether the block was already processed with the same hash.
+func (p *Parser) isProcessed(block *types.Block) (bool, error) {
+	if block.NumberU64() > uint64(p.currentBlock.Load()) {
+		return false, nil
+	}
+
+	hash, ok, err := p.storedBlockHash(block.NumberU64())
+	if err != nil {
+		return false, err
+	}
+	return ok && hash == block.Hash(), nil
+}
+
+// storedBlockHash returns the hash of the block processed at blockNumber. It is unknown when the block was not
+// processed, or long ago without any matched transaction.
+func (p *Parser) storedBlockHash(blockNumber uint64) (common.Hash, bool, error) {
+	if hash, ok := p.blockHashes.get(blockNumber); ok {
+		return hash, true, nil
+	}
+
+	records, err := p.txRepo.GetBlockTxs(blockNumber)
+	if err != nil {
+		return common.Hash{}, false, err
+	}
+	if len(records) == 0 {
+		return common.Hash{}, false, nil
+	}
+	return records[0].BlockHash, true, nil
+}
+
+// saveBlock drops the entries of reorged blocks, saves the matched transactions and moves the checkpoint,
+// publishing the events along. It returns the new checkpoint.
+func (p *Parser) saveBlock(
+	ctx context.Context,
+	block *types.Block,
+	receipts types.Receipts,
+	senders []common.Address,
+	subscribers [][]string,
+) (_ []*entity.TxRecord, _ uint64, err error) {
+	ctx, span := tracing.Tracer().Start(ctx, "parser.saveBlock")
+	defer func() { tracing.End(span, err) }()
+
+	checkpoint, err := p.dropReorgedBlocks(ctx, block)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	records, err := p.saveTxs(block, receipts, senders, subscribers, true)
+	if err != nil {
+		return nil, 0, err
+	}
+	p.blockHashes.add(block.NumberU64(), block.Hash())
+
+	if err := p.checkpointRepo.SaveCheckpoint(checkpoint); err != nil {
+		return nil, 0, err
+	}
+	return records, checkpoint, nil
+}
+
+// replaceBlock swaps the entries of a processed block for the ones of its canonical version, leaving the
+// checkpoint where it is. The events are only published along with publish.
+func (p *Parser) replaceBlock(
+	ctx context.Context,
+	block *types.Block,
+	receipts types.Receipts,
+	senders []common.Address,
+	subscribers [][]string,
+	publish bool,
+) (_ []*entity.TxRecord, err error) {
+	_, span := tracing.Tracer().Start(ctx, "parser.replaceBlock")
+	defer func() { tracing.End(span, err) }()
+
+	if publish {
+		replaced, err := p.txRepo.GetBlockTxs(block.NumberU64())
+		if err != nil {
+			return nil, err
+		}
+		if err := p.publishReorgs(replaced, block.Hash()); err != nil {
+			return nil, err
+		}
+	}
+
+	if _, err := p.txRepo.DeleteBlockTxs(block.NumberU64()); err != nil {
+		return nil, err
+	}
+	p.confirmations.drop(block.NumberU64())
+	p.blockHashes.add(block.NumberU64(), block.Hash())
+
+	return p.saveTxs(block, receipts, senders, subscribers, publish)
+}
+
+// publishReorgs publishes a reorg event for the records that are not part of the canonical block anymore.
+// They are published before the records are deleted, so a failure leaves them to be published again.
+func (p *Parser) publishReorgs(records []*entity.TxRecord, canonical common.Hash) error {
+	for _, record := range records {
+		if record.BlockHash == canonical {
+			continue
+		}
+		err := p.publisher.Publish(entity.Event{
+			Type:    entity.EventTypeReorg,
+			Address: record.Address,
+			Record:  record,
+		})
 		if err != nil {
 			return err
 		}
+	}
+	return nil
+}
 
-		var (
-			subscriber string
-			fromStr    = strings.ToLower(from.Hex())
-		)
+// saveTxs saves the matched transactions of a block, publishing their events and the block one along with publish.
+func (p *Parser) saveTxs(
+	block *types.Block,
+	receipts types.Receipts,
+	senders []common.Address,
+	subscribers [][]string,
+	publish bool,
+) ([]*entity.TxRecord, error) {
+	var records []*entity.TxRecord
+	for i, tx := range block.Transactions() {
+		for _, subscriber := range subscribers[i] {
+			record := newTxRecord(subscriber, block, uint(i), tx, senders[i], receipts[i])
+			if err := p.txRepo.SaveTx(record); err != nil {
+				return nil, err
+			}
+			records = append(records, record)
+
+			if publish {
+				err := p.publisher.Publish(entity.Event{
+					Type:    entity.EventTypeTx,
+					Address: subscriber,
+					Record:  record,
+				})
+				if err != nil {
+					return nil, err
+				}
+			}
+		}
 
-		if p.subscriberRepo.IsSubscriber(fromStr) {
-			subscriber = fromStr
-		} else if tx.To() != nil {
-			toStr := strings.ToLower(tx.To().Hex())
-			if p.subscriberRepo.IsSubscriber(toStr) {
-				subscriber = toStr
+		if publish {
+			if err := p.publishTokenTransfers(receipts[i]); err != nil {
+				return nil, err
 			}
 		}
+	}
+
+	if !publish {
+		return records, nil
+	}
+
+	err := p.publisher.Publish(entity.Event{
+		Type: entity.EventTypeBlock,
+		Block: &entity.BlockSummary{
+			Number:     block.NumberU64(),
+			Hash:       block.Hash(),
+			ParentHash: block.ParentHash(),
+			Timestamp:  block.Time(),
+			TxCount:    len(block.Transactions()),
+			MatchedTxs: len(records),
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return records, nil
+}
 
-		if subscriber == "" {
+// publishTokenTransfers publishes the token Transfer logs of a receipt involving subscribed addresses.
+func (p *Parser) publishTokenTransfers(receipt *types.Receipt) error {
+	for _, log := range receipt.Logs {
+		transfer, ok := DecodeTokenTransfer(log)
+		if !ok {
 			continue
 		}
 
-		if err := p.txRepo.SaveTx(subscriber, tx); err != nil {
-			return err
+		for _, subscriber := range p.matchSubscribers(transfer.From, &transfer.To) {
+			err := p.publisher.Publish(entity.Event{
+				Type:     entity.EventTypeTokenTransfer,
+				Address:  subscriber,
+				Transfer: transfer,
+			})
+			if err != nil {
+				return err
+			}
 		}
 	}
+	return nil
+}
+
+// publishConfirmations publishes a confirmation event for the records that reached the configured confirmations.
+// On failure the records left are tracked again.
+func (p *Parser) publishConfirmations(head uint64) error {
+	if head+1 < p.cfg.Confirmations {
+		return nil
+	}
 
+	records := p.confirmations.release(head + 1 - p.cfg.Confirmations)
+	for i, record := range records {
+		err := p.publisher.Publish(entity.Event{
+			Type:          entity.EventTypeConfirmation,
+			Address:       record.Address,
+			Record:        record,
+			Confirmations: head - record.BlockNumber + 1,
+		})
+		if err != nil {
+			for _, left := range records[i:] {
+				p.confirmations.track(left.BlockNumber, []*entity.TxRecord{left})
+			}
+			return err
+		}
+	}
 	return nil
 }
 
+// matchSubscribers returns the subscribed parties of a transaction.
+func (p *Parser) matchSubscribers(from common.Address, to *common.Address) []string {
+	return matchParties(from, to, p.subscriberRepo.IsSubscriber)
+}
+
+// matchParties returns the parties of a transaction that match.
+func matchParties(from common.Address, to *common.Address, match func(address string) bool) []string {
+	var parties []string
+
+	fromStr := entity.AddressKey(from)
+	if match(fromStr) {
+		parties = append(parties, fromStr)
+	}
+
+	if to != nil {
+		toStr := entity.AddressKey(*to)
+		if toStr != fromStr && match(toStr) {
+			parties = append(parties, toStr)
+		}
+	}
+
+	return parties
+}
+
+func newTxRecord(
+	subscriber string,
+	block *types.Block,
+	txIndex uint,
+	tx *types.Transaction,
+	from common.Address,
+	receipt *types.Receipt,
+) *entity.TxRecord {
+	return &entity.TxRecord{
+		Address:           subscriber,
+		Direction:         entity.DirectionOf(subscriber, from, tx.To()),
+		Tx:                tx,
+		From:              from,
+		BlockNumber:       block.NumberU64(),
+		BlockHash:         block.Hash(),
+		Timestamp:         block.Time(),
+		TxIndex:           txIndex,
+		Status:            entity.ReceiptStatus(receipt),
+		GasUsed:           receipt.GasUsed,
+		EffectiveGasPrice: receipt.EffectiveGasPrice,
+	}
+}
+
+// dropReorgedBlocks removes the entries of the blocks from the one of block up to the checkpoint that are no
+// longer canonical, and returns the checkpoint to save along with block. Blocks stored from the canonical chain
+// are kept, so the checkpoint only goes back when a block above the one of block is dropped.
+func (p *Parser) dropReorgedBlocks(ctx context.Context, block *types.Block) (uint64, error) {
+	checkpoint, err := p.checkpointRepo.GetCheckpoint()
+	if err != nil {
+		return 0, err
+	}
+
+	number := block.NumberU64()
+	if number > checkpoint {
+		return number, nil
+	}
+
+	keep := checkpoint
+	for n := number; n <= checkpoint; n++ {
+		canonical, err := p.canonicalHash(ctx, block, n)
+		if err != nil {
+			return 0, err
+		}
+
+		stored, ok, err := p.storedBlockHash(n)
+		if err != nil {
+			return 0, err
+		}
+		if !ok || stored == canonical {
+			// nothing stored for the block, or stored from the canonical one
+			continue
+		}
+
+		logger.WithFields(ctx, logger.Fields{"blockNumber": n}).Info("drop reorged block")
+		records, err := p.txRepo.GetBlockTxs(n)
+		if err != nil {
+			return 0, err
+		}
+		if err := p.publishReorgs(records, canonical); err != nil {
+			return 0, err
+		}
+		if _, err := p.txRepo.DeleteBlockTxs(n); err != nil {
+			return 0, err
+		}
+		if n > number && keep == checkpoint {
+			keep = n - 1
+		}
+
+		p.confirmations.drop(n)
+		p.blockHashes.forget(n)
+	}
+
+	return keep, nil
+}
+
+// canonicalHash returns the hash of the canonical block at blockNumber, the zero hash when the chain does not
+// reach it anymore.
+func (p *Parser) canonicalHash(ctx context.Context, block *types.Block, blockNumber uint64) (common.Hash, error) {
+	if blockNumber == block.NumberU64() {
+		return block.Hash(), nil
+	}
+
+	header, err := p.rpcClient.HeaderByNumber(ctx, new(big.Int).SetUint64(blockNumber))
+	if errors.Is(err, ethereum.NotFound) {
+		return common.Hash{}, nil
+	}
+	if err != nil {
+		return common.Hash{}, err
+	}
+	return header.Hash(), nil
+}
+
 func (p *Parser) GetCurrentBlock() int {
 	return int(p.currentBlock.Load())
 }
 
 func (p *Parser) Subscribe(address string) bool {
-	if p.subscriberRepo.IsSubscriber(address) {
-		return false
+	return p.AddSubscription(&entity.Subscription{Address: address}) == nil
+}
+
+// AddSubscription starts watching subscription.Address.
+func (p *Parser) AddSubscription(subscription *entity.Subscription) error {
+	if p.subscriberRepo.IsSubscriber(subscription.Address) {
+		return entity.ErrAlreadySubscribed
 	}
 
-	p.subscriberRepo.Create(address)
+	if subscription.CreatedAt.IsZero() {
+		subscription.CreatedAt = time.Now().UTC()
+	}
 
-	return true
+	if err := p.subscriberRepo.Create(subscription); err != nil {
+		return entity.BackendError(err)
+	}
+
+	return nil
+}
+
+// Unsubscribe stops watching an address. Transactions already recorded for the address are kept.
+func (p *Parser) Unsubscribe(address string) error {
+	if !p.subscriberRepo.IsSubscriber(address) {
+		return entity.ErrNotSubscribed
+	}
+
+	if err := p.subscriberRepo.Delete(address); err != nil {
+		return entity.BackendError(err)
+	}
+
+	return nil
+}
+
+// GetSubscription returns the subscription of an address, ErrNotSubscribed when it is not watched.
+func (p *Parser) GetSubscription(address string) (*entity.Subscription, error) {
+	subscription, err := p.subscriberRepo.Get(address)
+	if err != nil {
+		return nil, entity.BackendError(err)
+	}
+	if subscription == nil {
+		return nil, entity.ErrNotSubscribed
+	}
+	return subscription, nil
+}
+
+func (p *Parser) GetSubscriptions(cursor string, limit int) (*entity.SubscriptionPage, error) {
+	page, err := p.subscriberRepo.List(cursor, limit)
+	if err != nil {
+		return nil, entity.BackendError(err)
+	}
+	return page, nil
 }
 
 func (p *Parser) GetTransactions(address string) []*types.Transaction {
-	txs, _ := p.txRepo.GetTxs(address)
+	records, _ := p.txRepo.GetTxs(address)
+
+	txs := make([]*types.Transaction, 0, len(records))
+	for _, record := range records {
+		txs = append(txs, record.Tx)
+	}
 	return txs
 }
+
+func (p *Parser) QueryTxRecords(address string, query entity.TxQuery) (*entity.TxPage, error) {
+	page, err := p.txRepo.QueryTxs(address, query)
+	if err != nil {
+		return nil, entity.BackendError(err)
+	}
+	return page, nil
+}
+
+// GetTxDetail returns a transaction from the repository with its receipt and decoded logs.
+// Transactions that aren't indexed are looked up on the RPC and returned with Indexed unset.
+func (p *Parser) GetTxDetail(ctx context.Context, hash common.Hash) (*entity.TxDetail, error) {
+	records, err := p.txRepo.GetTxsByHash(hash)
+	if err != nil {
+		return nil, entity.BackendError(err)
+	}
+
+	if len(records) > 0 {
+		record := records[0]
+		detail := &entity.TxDetail{
+			Indexed:     true,
+			Tx:          record.Tx,
+			From:        record.From,
+			BlockNumber: record.BlockNumber,
+			BlockHash:   record.BlockHash,
+			Timestamp:   record.Timestamp,
+			TxIndex:     record.TxIndex,
+			Records:     records,
+		}
+
+		receipt, err := p.rpcClient.TransactionReceipt(ctx, hash)
+		if err != nil {
+			logger.WithFields(ctx, logger.Fields{
+				"hash":     hash.Hex(),
+				"errorMsg": err.Error(),
+			}).Warn("failed to get receipt of indexed tx")
+			return detail, nil
+		}
+		detail.Receipt = receipt
+		detail.Logs = DecodeLogs(receipt.Logs)
+		detail.Transfers = DecodeTokenTransfers(receipt.Logs)
+
+		return detail, nil
+	}
+
+	return p.getTxDetailFromRPC(ctx, hash)
+}
+
+// GetTokenTransfers returns the token Transfer logs of a mined transaction, read from its receipt.
+func (p *Parser) GetTokenTransfers(ctx context.Context, hash common.Hash) ([]*entity.TokenTransfer, error) {
+	receipt, err := p.rpcClient.TransactionReceipt(ctx, hash)
+	if errors.Is(err, ethereum.NotFound) {
+		return nil, entity.ErrTxNotFound
+	}
+	if err != nil {
+		return nil, entity.BackendError(err)
+	}
+
+	return DecodeTokenTransfers(receipt.Logs), nil
+}
+
+func (p *Parser) getTxDetailFromRPC(ctx context.Context, hash common.Hash) (*entity.TxDetail, error) {
+	tx, pending, err := p.rpcClient.TransactionByHash(ctx, hash)
+	if errors.Is(err, ethereum.NotFound) {
+		return nil, entity.ErrTxNotFound
+	}
+	if err != nil {
+		return nil, entity.BackendError(err)
+	}
+
+	from, err := types.Sender(types.LatestSignerForChainID(tx.ChainId()), tx)
+	if err != nil {
+		return nil, err
+	}
+
+	detail := &entity.TxDetail{
+		Pending: pending,
+		Tx:      tx,
+		From:    from,
+		Records: []*entity.TxRecord{},
+	}
+	if pending {
+		return detail, nil
+	}
+
+	receipt, err := p.rpcClient.TransactionReceipt(ctx, hash)
+	if err != nil {
+		return nil, entity.BackendError(err)
+	}
+
+	header, err := p.rpcClient.HeaderByHash(ctx, receipt.BlockHash)
+	if err != nil {
+		return nil, entity.BackendError(err)
+	}
+
+	detail.BlockNumber = receipt.BlockNumber.Uint64()
+	detail.BlockHash = receipt.BlockHash
+	detail.Timestamp = header.Time
+	detail.TxIndex = receipt.TransactionIndex
+	detail.Receipt = receipt
+	detail.Logs = DecodeLogs(receipt.Logs)
+	detail.Transfers = DecodeTokenTransfers(receipt.Logs)
+
+	return detail, nil
+}
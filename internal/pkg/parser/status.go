@@ -0,0 +1,112 @@
+package parser
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/vuquang23/trustme/internal/pkg/entity"
+	"github.com/vuquang23/trustme/internal/pkg/metrics"
+)
+
+// syncState tracks the chain head, the processed blocks and the new heads subscription for the status endpoints.
+type syncState struct {
+	mu              sync.RWMutex
+	chainHead       uint64
+	lastBlockTime   time.Time
+	lastProcessedAt time.Time
+	ws              entity.WSStatus
+	backfill        *entity.BackfillProgress
+}
+
+func (s *syncState) observeHead(number uint64, fromWS bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if number > s.chainHead {
+		s.chainHead = number
+	}
+	if fromWS {
+		s.ws.LastHeadAt = time.Now().UTC()
+	}
+}
+
+func (s *syncState) processed(blockTime uint64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.lastBlockTime = time.Unix(int64(blockTime), 0).UTC()
+	s.lastProcessedAt = time.Now().UTC()
+}
+
+func (s *syncState) wsConnected() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.ws.Connected = true
+	s.ws.ConnectedAt = time.Now().UTC()
+}
+
+func (s *syncState) wsDisconnected(err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.ws.Connected {
+		s.ws.Reconnects++
+	}
+	s.ws.Connected = false
+	s.ws.LastError = err.Error()
+}
+
+func (s *syncState) setBackfill(backfill *entity.BackfillProgress) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.backfill = backfill
+}
+
+func (s *syncState) backfillAt(number uint64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.backfill != nil {
+		s.backfill.Current = number
+	}
+}
+
+// Status returns the progress of the parser against the chain.
+func (p *Parser) Status() entity.SyncStatus {
+	p.sync.mu.RLock()
+	defer p.sync.mu.RUnlock()
+
+	status := entity.SyncStatus{
+		CurrentBlock:    uint64(p.currentBlock.Load()),
+		ChainHead:       p.sync.chainHead,
+		LastBlockTime:   p.sync.lastBlockTime,
+		LastProcessedAt: p.sync.lastProcessedAt,
+		WS:              p.sync.ws,
+	}
+	if p.sync.backfill != nil {
+		backfill := *p.sync.backfill
+		status.Backfill = &backfill
+	}
+	return status
+}
+
+// ChainHead asks the RPC node for the latest block number, which also checks that the node is reachable.
+func (p *Parser) ChainHead(ctx context.Context) (uint64, error) {
+	number, err := p.rpcClient.BlockNumber(ctx)
+	if err != nil {
+		return 0, err
+	}
+	p.sync.observeHead(number, false)
+	p.updateLagMetrics()
+	return number, nil
+}
+
+func (p *Parser) updateLagMetrics() {
+	status := p.Status()
+	metrics.CurrentBlock.Set(float64(status.CurrentBlock))
+	metrics.ChainHead.Set(float64(status.ChainHead))
+	metrics.HeadLag.Set(float64(status.Lag()))
+}
@@ -0,0 +1,39 @@
+package ratelimit
+
+import "time"
+
+type Config struct {
+	// Enabled limits the request rate of every client, identified by its API key or else its IP.
+	Enabled bool
+	// Default limits the routes outside of the Groups.
+	Default Limit
+	// Groups limit the routes under a path prefix, the longest matching prefix applies.
+	// Clients have a token bucket per group.
+	Groups []GroupLimit
+	// SubscriptionsPerDay caps the subscriptions a tenant creates per UTC day, 0 for no quota.
+	SubscriptionsPerDay int
+	// IdleTimeout is how long the buckets of an idle client are kept.
+	IdleTimeout time.Duration `default:"10m"`
+}
+
+type Limit struct {
+	// Rate is the sustained number of requests per second, 0 for no limit.
+	Rate float64 `default:"20"`
+	// Burst is the number of requests allowed at once.
+	Burst int `default:"40"`
+}
+
+type GroupLimit struct {
+	// Prefix is a path prefix, or a gRPC method prefix such as /trustme.v1.TrustmeService/.
+	Prefix string
+	Rate   float64
+	Burst  int
+}
+
+// Limits are the limits adjustable at runtime.
+type Limits struct {
+	Enabled             bool
+	Default             Limit
+	Groups              []GroupLimit
+	SubscriptionsPerDay int
+}
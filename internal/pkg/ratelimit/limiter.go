@@ -0,0 +1,235 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+
+	"github.com/vuquang23/trustme/internal/pkg/entity"
+)
+
+// Limiter keeps a token bucket per client and route group, and counts the subscriptions of each tenant per day.
+type Limiter struct {
+	idleTimeout time.Duration
+
+	mu      sync.Mutex
+	limits  Limits
+	buckets map[bucketKey]*bucket
+	// quotaDay is the UTC day subscriptions are counted for.
+	quotaDay      string
+	subscriptions map[string]int
+}
+
+type bucketKey struct {
+	group  string
+	client string
+}
+
+type bucket struct {
+	limiter  *rate.Limiter
+	lastSeen time.Time
+}
+
+func New(cfg Config) (*Limiter, error) {
+	l := &Limiter{
+		idleTimeout:   cfg.IdleTimeout,
+		buckets:       make(map[bucketKey]*bucket),
+		subscriptions: make(map[string]int),
+	}
+
+	err := l.SetLimits(Limits{
+		Enabled:             cfg.Enabled,
+		Default:             cfg.Default,
+		Groups:              cfg.Groups,
+		SubscriptionsPerDay: cfg.SubscriptionsPerDay,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return l, nil
+}
+
+// Allow takes a token from the bucket of client for the group of path. When the bucket is empty, it returns
+// false with the time until the next token.
+func (l *Limiter) Allow(client, path string) (bool, time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if !l.limits.Enabled {
+		return true, 0
+	}
+
+	group, limit := l.group(path)
+	if limit.Rate <= 0 {
+		return true, 0
+	}
+
+	now := time.Now()
+	key := bucketKey{group: group, client: client}
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &bucket{limiter: rate.NewLimiter(rate.Limit(limit.Rate), limit.Burst)}
+		l.buckets[key] = b
+	}
+	b.lastSeen = now
+
+	reservation := b.limiter.ReserveN(now, 1)
+	if delay := reservation.DelayFrom(now); delay > 0 {
+		reservation.CancelAt(now)
+		return false, delay
+	}
+	return true, 0
+}
+
+// group returns the group of a path, the longest matching prefix or "" for the default limit.
+func (l *Limiter) group(path string) (string, Limit) {
+	var (
+		group string
+		limit = l.limits.Default
+	)
+	for _, g := range l.limits.Groups {
+		if len(g.Prefix) > len(group) && strings.HasPrefix(path, g.Prefix) {
+			group, limit = g.Prefix, Limit{Rate: g.Rate, Burst: g.Burst}
+		}
+	}
+	return group, limit
+}
+
+func (l *Limiter) Limits() Limits {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	limits := l.limits
+	limits.Groups = slices.Clone(l.limits.Groups)
+	return limits
+}
+
+// SetLimits replaces the limits. Existing buckets keep their tokens and move to the new rate and burst,
+// they are dropped when the rate limiting is switched on or off.
+func (l *Limiter) SetLimits(limits Limits) error {
+	if err := validateLimit("default", limits.Default.Rate, limits.Default.Burst); err != nil {
+		return err
+	}
+	seen := make(map[string]struct{}, len(limits.Groups))
+	for _, g := range limits.Groups {
+		if !strings.HasPrefix(g.Prefix, "/") {
+			return fmt.Errorf("%w: rate limit group prefix %q must start with /", entity.ErrInvalidParams, g.Prefix)
+		}
+		if _, ok := seen[g.Prefix]; ok {
+			return fmt.Errorf("%w: duplicated rate limit group %s", entity.ErrInvalidParams, g.Prefix)
+		}
+		seen[g.Prefix] = struct{}{}
+		if err := validateLimit(g.Prefix, g.Rate, g.Burst); err != nil {
+			return err
+		}
+	}
+	if limits.SubscriptionsPerDay < 0 {
+		return fmt.Errorf("%w: negative subscriptions per day", entity.ErrInvalidParams)
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if limits.Enabled != l.limits.Enabled {
+		clear(l.buckets)
+	}
+	l.limits = Limits{
+		Enabled:             limits.Enabled,
+		Default:             limits.Default,
+		Groups:              slices.Clone(limits.Groups),
+		SubscriptionsPerDay: limits.SubscriptionsPerDay,
+	}
+
+	now := time.Now()
+	for key, b := range l.buckets {
+		group, limit := l.group(key.group)
+		if group != key.group || limit.Rate <= 0 {
+			// The group is gone or unlimited, the client starts over in its new group.
+			delete(l.buckets, key)
+			continue
+		}
+		b.limiter.SetLimitAt(now, rate.Limit(limit.Rate))
+		b.limiter.SetBurstAt(now, limit.Burst)
+	}
+	return nil
+}
+
+func validateLimit(group string, r float64, burst int) error {
+	if r < 0 {
+		return fmt.Errorf("%w: negative rate for rate limit group %s", entity.ErrInvalidParams, group)
+	}
+	if r > 0 && burst < 1 {
+		return fmt.Errorf("%w: rate limit group %s needs a burst of at least 1", entity.ErrInvalidParams, group)
+	}
+	return nil
+}
+
+// ConsumeSubscription counts a new subscription of tenant, ErrQuotaExceeded once it made SubscriptionsPerDay
+// of them today.
+func (l *Limiter) ConsumeSubscription(tenant string) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.limits.SubscriptionsPerDay <= 0 {
+		return nil
+	}
+
+	l.resetQuotas()
+	if l.subscriptions[tenant] >= l.limits.SubscriptionsPerDay {
+		return fmt.Errorf("%w: %d subscriptions per day, resets at 00:00 UTC", entity.ErrQuotaExceeded, l.limits.SubscriptionsPerDay)
+	}
+	l.subscriptions[tenant]++
+	return nil
+}
+
+// RefundSubscription gives back a subscription counted for a subscribe that failed.
+func (l *Limiter) RefundSubscription(tenant string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.resetQuotas()
+	if l.subscriptions[tenant] > 0 {
+		l.subscriptions[tenant]--
+	}
+}
+
+// resetQuotas clears the counts of the previous days.
+func (l *Limiter) resetQuotas() {
+	day := time.Now().UTC().Format(time.DateOnly)
+	if day != l.quotaDay {
+		l.quotaDay = day
+		clear(l.subscriptions)
+	}
+}
+
+// Run drops the buckets of the clients idle for IdleTimeout.
+func (l *Limiter) Run(ctx context.Context) error {
+	ticker := time.NewTicker(l.idleTimeout)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+			l.sweep()
+		}
+	}
+}
+
+func (l *Limiter) sweep() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	idleSince := time.Now().Add(-l.idleTimeout)
+	for key, b := range l.buckets {
+		if b.lastSeen.Before(idleSince) {
+			delete(l.buckets, key)
+		}
+	}
+}
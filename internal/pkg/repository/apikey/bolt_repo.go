@@ -0,0 +1,133 @@
+package apikey
+
+import (
+	"time"
+
+	"github.com/ethereum/go-ethereum/rlp"
+	bolt "go.etcd.io/bbolt"
+
+	"github.com/vuquang23/trustme/internal/pkg/entity"
+	"github.com/vuquang23/trustme/internal/pkg/repository/boltdb"
+)
+
+// storedKey is the RLP layout of an API key, keyed by its ID.
+type storedKey struct {
+	Name      string
+	Tenant    string
+	Admin     bool
+	Hash      string
+	CreatedAt uint64
+}
+
+// BoltRepository stores API keys by ID, with an index of the IDs by key hash in apiKeyHashes.
+type BoltRepository struct {
+	db *bolt.DB
+}
+
+func NewBoltRepository(db *bolt.DB) *BoltRepository {
+	return &BoltRepository{
+		db: db,
+	}
+}
+
+func (k *BoltRepository) CreateKey(key *entity.APIKey) error {
+	value, err := rlp.EncodeToBytes(storedKey{
+		Name:      key.Name,
+		Tenant:    key.Tenant,
+		Admin:     key.Admin,
+		Hash:      key.Hash,
+		CreatedAt: uint64(key.CreatedAt.Unix()),
+	})
+	if err != nil {
+		return err
+	}
+
+	return k.db.Update(func(tx *bolt.Tx) error {
+		if err := tx.Bucket(boltdb.BucketAPIKeys).Put([]byte(key.ID), value); err != nil {
+			return err
+		}
+		return tx.Bucket(boltdb.BucketAPIKeyHashes).Put([]byte(key.Hash), []byte(key.ID))
+	})
+}
+
+func (k *BoltRepository) GetKey(id string) (*entity.APIKey, error) {
+	var key *entity.APIKey
+	err := k.db.View(func(tx *bolt.Tx) error {
+		var err error
+		key, err = getKey(tx, []byte(id))
+		return err
+	})
+	return key, err
+}
+
+func (k *BoltRepository) GetKeyByHash(hash string) (*entity.APIKey, error) {
+	var key *entity.APIKey
+	err := k.db.View(func(tx *bolt.Tx) error {
+		id := tx.Bucket(boltdb.BucketAPIKeyHashes).Get([]byte(hash))
+		if id == nil {
+			return nil
+		}
+
+		var err error
+		key, err = getKey(tx, id)
+		return err
+	})
+	return key, err
+}
+
+func (k *BoltRepository) ListKeys() ([]*entity.APIKey, error) {
+	keys := []*entity.APIKey{}
+	err := k.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(boltdb.BucketAPIKeys).ForEach(func(id, v []byte) error {
+			key, err := decodeKey(id, v)
+			if err != nil {
+				return err
+			}
+			keys = append(keys, key)
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return keys, nil
+}
+
+func (k *BoltRepository) DeleteKey(id string) error {
+	return k.db.Update(func(tx *bolt.Tx) error {
+		key, err := getKey(tx, []byte(id))
+		if err != nil || key == nil {
+			return err
+		}
+
+		if err := tx.Bucket(boltdb.BucketAPIKeyHashes).Delete([]byte(key.Hash)); err != nil {
+			return err
+		}
+		return tx.Bucket(boltdb.BucketAPIKeys).Delete([]byte(id))
+	})
+}
+
+func getKey(tx *bolt.Tx, id []byte) (*entity.APIKey, error) {
+	v := tx.Bucket(boltdb.BucketAPIKeys).Get(id)
+	if v == nil {
+		return nil, nil
+	}
+	return decodeKey(id, v)
+}
+
+func decodeKey(id, value []byte) (*entity.APIKey, error) {
+	var stored storedKey
+	if err := rlp.DecodeBytes(value, &stored); err != nil {
+		return nil, err
+	}
+
+	return &entity.APIKey{
+		ID:        string(id),
+		Name:      stored.Name,
+		Tenant:    stored.Tenant,
+		Admin:     stored.Admin,
+		Hash:      stored.Hash,
+		CreatedAt: time.Unix(int64(stored.CreatedAt), 0).UTC(),
+	}, nil
+}
@@ -0,0 +1,72 @@
+package apikey
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/vuquang23/trustme/internal/pkg/entity"
+)
+
+type MemRepository struct {
+	mu   sync.RWMutex
+	keys map[string]entity.APIKey
+}
+
+func NewMemRepository() *MemRepository {
+	return &MemRepository{
+		keys: make(map[string]entity.APIKey),
+	}
+}
+
+func (k *MemRepository) CreateKey(key *entity.APIKey) error {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	k.keys[key.ID] = *key
+	return nil
+}
+
+func (k *MemRepository) GetKey(id string) (*entity.APIKey, error) {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+
+	key, ok := k.keys[id]
+	if !ok {
+		return nil, nil
+	}
+	return &key, nil
+}
+
+func (k *MemRepository) GetKeyByHash(hash string) (*entity.APIKey, error) {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+
+	for _, key := range k.keys {
+		if key.Hash == hash {
+			key := key
+			return &key, nil
+		}
+	}
+	return nil, nil
+}
+
+func (k *MemRepository) ListKeys() ([]*entity.APIKey, error) {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+
+	keys := make([]*entity.APIKey, 0, len(k.keys))
+	for _, key := range k.keys {
+		key := key
+		keys = append(keys, &key)
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i].ID < keys[j].ID })
+	return keys, nil
+}
+
+func (k *MemRepository) DeleteKey(id string) error {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	delete(k.keys, id)
+	return nil
+}
@@ -0,0 +1,66 @@
+package boltdb
+
+import (
+	"fmt"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+var (
+	BucketSubscribers = []byte("subscribers")
+	BucketTxs         = []byte("txs")
+	BucketTxHashes    = []byte("txHashes")
+	BucketBlockTxs    = []byte("blockTxs")
+	BucketCheckpoint  = []byte("checkpoint")
+
+	BucketWebhooks          = []byte("webhooks")
+	BucketWebhookAddresses  = []byte("webhookAddresses")
+	BucketWebhookDeliveries = []byte("webhookDeliveries")
+	BucketWebhookOutbox     = []byte("webhookOutbox")
+
+	BucketOutbox = []byte("outbox")
+
+	BucketAPIKeys             = []byte("apiKeys")
+	BucketAPIKeyHashes        = []byte("apiKeyHashes")
+	BucketTenantSubscriptions = []byte("tenantSubscriptions")
+	BucketSubscriptionTenants = []byte("subscriptionTenants")
+)
+
+// Open opens (or creates) the database file at path and checks its schema is at the latest version, migrating it
+// up with autoMigrate.
+func Open(path string, opts *bolt.Options, autoMigrate bool) (*bolt.DB, error) {
+	db, err := OpenFile(path, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := checkSchema(db, autoMigrate); err != nil {
+		_ = db.Close()
+		return nil, err
+	}
+
+	return db, nil
+}
+
+// OpenFile opens (or creates) the database file at path as it is, for the migrations.
+func OpenFile(path string, opts *bolt.Options) (*bolt.DB, error) {
+	return bolt.Open(path, 0600, opts)
+}
+
+func checkSchema(db *bolt.DB, autoMigrate bool) error {
+	version, err := SchemaVersion(db)
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case version > LatestVersion():
+		return fmt.Errorf("database schema version %d is newer than the supported %d", version, LatestVersion())
+	case version < LatestVersion() && autoMigrate:
+		_, err := MigrateUp(db, LatestVersion())
+		return err
+	case version < LatestVersion():
+		return fmt.Errorf("database schema version %d is behind %d, run the migrate up command", version, LatestVersion())
+	}
+	return nil
+}
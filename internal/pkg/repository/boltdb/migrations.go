@@ -0,0 +1,146 @@
+package boltdb
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+var (
+	bucketMeta       = []byte("meta")
+	keySchemaVersion = []byte("schemaVersion")
+)
+
+// Migration moves the schema of the database one version up, or back down.
+type Migration struct {
+	Version     int
+	Description string
+	Up          func(tx *bolt.Tx) error
+	Down        func(tx *bolt.Tx) error
+}
+
+// Migrations are the schema versions in order. Databases created before the schema was versioned are at version 0
+// with the buckets of the first versions already there, so migrations must be idempotent.
+var Migrations = []Migration{
+	bucketsMigration(1, "subscriber, tx and checkpoint buckets",
+		BucketSubscribers, BucketTxs, BucketTxHashes, BucketBlockTxs, BucketCheckpoint),
+	bucketsMigration(2, "webhook buckets",
+		BucketWebhooks, BucketWebhookAddresses, BucketWebhookDeliveries, BucketWebhookOutbox),
+	bucketsMigration(3, "broker outbox bucket",
+		BucketOutbox),
+	bucketsMigration(4, "api key and tenant buckets",
+		BucketAPIKeys, BucketAPIKeyHashes, BucketTenantSubscriptions, BucketSubscriptionTenants),
+}
+
+// bucketsMigration creates top level buckets, going down deletes them along with their data.
+func bucketsMigration(version int, description string, buckets ...[]byte) Migration {
+	return Migration{
+		Version:     version,
+		Description: description,
+		Up: func(tx *bolt.Tx) error {
+			for _, bucket := range buckets {
+				if _, err := tx.CreateBucketIfNotExists(bucket); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+		Down: func(tx *bolt.Tx) error {
+			for _, bucket := range buckets {
+				if err := tx.DeleteBucket(bucket); err != nil && !errors.Is(err, bolt.ErrBucketNotFound) {
+					return err
+				}
+			}
+			return nil
+		},
+	}
+}
+
+func LatestVersion() int {
+	return Migrations[len(Migrations)-1].Version
+}
+
+// SchemaVersion returns the version the database is migrated to, 0 for a new or unversioned one.
+func SchemaVersion(db *bolt.DB) (int, error) {
+	var version int
+	err := db.View(func(tx *bolt.Tx) error {
+		version = schemaVersion(tx)
+		return nil
+	})
+	return version, err
+}
+
+// MigrateUp applies the migrations up to version, returning the applied ones. Each runs in its own transaction.
+func MigrateUp(db *bolt.DB, version int) ([]Migration, error) {
+	current, err := SchemaVersion(db)
+	if err != nil {
+		return nil, err
+	}
+	if version > LatestVersion() {
+		return nil, fmt.Errorf("unknown schema version %d, the latest is %d", version, LatestVersion())
+	}
+
+	var applied []Migration
+	for _, m := range Migrations {
+		if m.Version <= current || m.Version > version {
+			continue
+		}
+		if err := migrate(db, m.Up, m.Version); err != nil {
+			return applied, fmt.Errorf("migration %d up: %w", m.Version, err)
+		}
+		applied = append(applied, m)
+	}
+	return applied, nil
+}
+
+// MigrateDown reverts the migrations above version, latest first, returning the reverted ones.
+func MigrateDown(db *bolt.DB, version int) ([]Migration, error) {
+	current, err := SchemaVersion(db)
+	if err != nil {
+		return nil, err
+	}
+	if version < 0 {
+		return nil, fmt.Errorf("unknown schema version %d", version)
+	}
+
+	var reverted []Migration
+	for i := len(Migrations) - 1; i >= 0; i-- {
+		m := Migrations[i]
+		if m.Version > current || m.Version <= version {
+			continue
+		}
+		if err := migrate(db, m.Down, m.Version-1); err != nil {
+			return reverted, fmt.Errorf("migration %d down: %w", m.Version, err)
+		}
+		reverted = append(reverted, m)
+	}
+	return reverted, nil
+}
+
+func migrate(db *bolt.DB, fn func(tx *bolt.Tx) error, version int) error {
+	return db.Update(func(tx *bolt.Tx) error {
+		if err := fn(tx); err != nil {
+			return err
+		}
+
+		meta, err := tx.CreateBucketIfNotExists(bucketMeta)
+		if err != nil {
+			return err
+		}
+		return meta.Put(keySchemaVersion, binary.BigEndian.AppendUint64(nil, uint64(version)))
+	})
+}
+
+func schemaVersion(tx *bolt.Tx) int {
+	meta := tx.Bucket(bucketMeta)
+	if meta == nil {
+		return 0
+	}
+	v := meta.Get(keySchemaVersion)
+	if len(v) != 8 {
+		return 0
+	}
+	return int(binary.BigEndian.Uint64(v))
+}
@@ -0,0 +1,128 @@
+package boltdb_test
+
+import (
+	"path/filepath"
+	"testing"
+
+	bolt "go.etcd.io/bbolt"
+
+	"github.com/vuquang23/trustme/internal/pkg/repository/boltdb"
+)
+
+func openTestDB(t *testing.T, path string) *bolt.DB {
+	t.Helper()
+
+	db, err := boltdb.OpenFile(path, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = db.Close() })
+	return db
+}
+
+func hasBucket(t *testing.T, db *bolt.DB, name []byte) bool {
+	t.Helper()
+
+	var found bool
+	if err := db.View(func(tx *bolt.Tx) error {
+		found = tx.Bucket(name) != nil
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	return found
+}
+
+func checkVersion(t *testing.T, db *bolt.DB, want int) {
+	t.Helper()
+
+	version, err := boltdb.SchemaVersion(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if version != want {
+		t.Fatalf("got schema version %d, want %d", version, want)
+	}
+}
+
+func TestMigrateUpAndDown(t *testing.T) {
+	db := openTestDB(t, filepath.Join(t.TempDir(), "trustme.db"))
+	checkVersion(t, db, 0)
+
+	applied, err := boltdb.MigrateUp(db, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(applied) != 2 {
+		t.Fatalf("got %d applied migrations, want 2", len(applied))
+	}
+	checkVersion(t, db, 2)
+	if !hasBucket(t, db, boltdb.BucketWebhooks) || hasBucket(t, db, boltdb.BucketOutbox) {
+		t.Fatal("got the buckets of another version than 2")
+	}
+
+	if err := db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(boltdb.BucketSubscribers).Put([]byte("0xabc"), []byte("{}"))
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := boltdb.MigrateUp(db, boltdb.LatestVersion()+1); err == nil {
+		t.Fatal("got no error migrating to an unknown version")
+	}
+	applied, err = boltdb.MigrateUp(db, boltdb.LatestVersion())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(applied) != boltdb.LatestVersion()-2 {
+		t.Fatalf("got %d applied migrations, want %d", len(applied), boltdb.LatestVersion()-2)
+	}
+	checkVersion(t, db, boltdb.LatestVersion())
+
+	reverted, err := boltdb.MigrateDown(db, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(reverted) != boltdb.LatestVersion()-1 || reverted[0].Version != boltdb.LatestVersion() {
+		t.Fatalf("got %d reverted migrations, want %d latest first", len(reverted), boltdb.LatestVersion()-1)
+	}
+	checkVersion(t, db, 1)
+	for _, bucket := range [][]byte{boltdb.BucketWebhooks, boltdb.BucketOutbox, boltdb.BucketAPIKeys} {
+		if hasBucket(t, db, bucket) {
+			t.Fatalf("bucket %s is still there at version 1", bucket)
+		}
+	}
+
+	// the buckets of the versions kept keep their data
+	if err := db.View(func(tx *bolt.Tx) error {
+		if tx.Bucket(boltdb.BucketSubscribers).Get([]byte("0xabc")) == nil {
+			t.Fatal("got the subscriber dropped at version 1")
+		}
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestOpenChecksSchemaVersion(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "trustme.db")
+
+	db := openTestDB(t, path)
+	if _, err := boltdb.MigrateUp(db, 1); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := boltdb.Open(path, nil, false); err == nil {
+		t.Fatal("got no error opening a database behind the latest version without migrating it")
+	}
+
+	db, err := boltdb.Open(path, nil, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = db.Close() })
+	checkVersion(t, db, boltdb.LatestVersion())
+}
@@ -0,0 +1,41 @@
+package checkpoint
+
+import (
+	"encoding/binary"
+
+	bolt "go.etcd.io/bbolt"
+
+	"github.com/vuquang23/trustme/internal/pkg/repository/boltdb"
+)
+
+var keyLastBlock = []byte("lastBlock")
+
+type BoltRepository struct {
+	db *bolt.DB
+}
+
+func NewBoltRepository(db *bolt.DB) *BoltRepository {
+	return &BoltRepository{
+		db: db,
+	}
+}
+
+func (c *BoltRepository) GetCheckpoint() (uint64, error) {
+	var blockNumber uint64
+	err := c.db.View(func(tx *bolt.Tx) error {
+		v := tx.Bucket(boltdb.BucketCheckpoint).Get(keyLastBlock)
+		if len(v) == 8 {
+			blockNumber = binary.BigEndian.Uint64(v)
+		}
+		return nil
+	})
+	return blockNumber, err
+}
+
+func (c *BoltRepository) SaveCheckpoint(blockNumber uint64) error {
+	return c.db.Update(func(tx *bolt.Tx) error {
+		v := make([]byte, 8)
+		binary.BigEndian.PutUint64(v, blockNumber)
+		return tx.Bucket(boltdb.BucketCheckpoint).Put(keyLastBlock, v)
+	})
+}
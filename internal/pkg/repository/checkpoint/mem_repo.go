@@ -0,0 +1,20 @@
+package checkpoint
+
+import "sync/atomic"
+
+type MemRepository struct {
+	blockNumber atomic.Uint64
+}
+
+func NewMemRepository() *MemRepository {
+	return &MemRepository{}
+}
+
+func (c *MemRepository) GetCheckpoint() (uint64, error) {
+	return c.blockNumber.Load(), nil
+}
+
+func (c *MemRepository) SaveCheckpoint(blockNumber uint64) error {
+	c.blockNumber.Store(blockNumber)
+	return nil
+}
@@ -0,0 +1,26 @@
+package repository
+
+import "time"
+
+const (
+	BackendMemory = "memory"
+	BackendBolt   = "bolt"
+)
+
+type Config struct {
+	Backend string `default:"memory"`
+	Memory  MemoryConfig
+	Bolt    BoltConfig
+}
+
+type MemoryConfig struct {
+	// MaxTxsPerAddress caps the history kept per address, 0 means unbounded.
+	MaxTxsPerAddress int `default:"10000"`
+}
+
+type BoltConfig struct {
+	Path    string        `default:"trustme.db"`
+	Timeout time.Duration `default:"1s"`
+	// AutoMigrate migrates the schema up when opening the database, otherwise the migrate command has to.
+	AutoMigrate bool `default:"true"`
+}
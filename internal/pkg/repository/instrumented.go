@@ -0,0 +1,167 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/vuquang23/trustme/internal/pkg/entity"
+	"github.com/vuquang23/trustme/internal/pkg/metrics"
+	"github.com/vuquang23/trustme/internal/pkg/parser"
+	"github.com/vuquang23/trustme/internal/pkg/sink"
+	"github.com/vuquang23/trustme/internal/pkg/webhook"
+)
+
+// instrument times the operations of the repositories on the ingestion and delivery paths.
+func (r *Repositories) instrument() *Repositories {
+	r.Subscriber = &subscriberRepository{r.Subscriber}
+	r.Tx = &txRepository{r.Tx}
+	r.Checkpoint = &checkpointRepository{r.Checkpoint}
+	r.Webhook = &webhookRepository{r.Webhook}
+	r.Outbox = &outboxRepository{r.Outbox}
+	return r
+}
+
+func observe(repository, operation string, startTime time.Time) {
+	metrics.RepositoryDuration.WithLabelValues(repository, operation).Observe(time.Since(startTime).Seconds())
+}
+
+type subscriberRepository struct {
+	parser.ISubscriberRepository
+}
+
+func (r *subscriberRepository) Create(subscription *entity.Subscription) error {
+	defer observe("subscriber", "create", time.Now())
+	return r.ISubscriberRepository.Create(subscription)
+}
+
+func (r *subscriberRepository) Delete(address string) error {
+	defer observe("subscriber", "delete", time.Now())
+	return r.ISubscriberRepository.Delete(address)
+}
+
+func (r *subscriberRepository) Get(address string) (*entity.Subscription, error) {
+	defer observe("subscriber", "get", time.Now())
+	return r.ISubscriberRepository.Get(address)
+}
+
+func (r *subscriberRepository) IsSubscriber(address string) bool {
+	defer observe("subscriber", "is_subscriber", time.Now())
+	return r.ISubscriberRepository.IsSubscriber(address)
+}
+
+func (r *subscriberRepository) List(cursor string, limit int) (*entity.SubscriptionPage, error) {
+	defer observe("subscriber", "list", time.Now())
+	return r.ISubscriberRepository.List(cursor, limit)
+}
+
+type txRepository struct {
+	parser.ITxRepository
+}
+
+func (r *txRepository) SaveTx(record *entity.TxRecord) error {
+	defer observe("tx", "save_tx", time.Now())
+	return r.ITxRepository.SaveTx(record)
+}
+
+func (r *txRepository) GetTxs(address string) ([]*entity.TxRecord, error) {
+	defer observe("tx", "get_txs", time.Now())
+	return r.ITxRepository.GetTxs(address)
+}
+
+func (r *txRepository) QueryTxs(address string, query entity.TxQuery) (*entity.TxPage, error) {
+	defer observe("tx", "query_txs", time.Now())
+	return r.ITxRepository.QueryTxs(address, query)
+}
+
+func (r *txRepository) GetTxsByHash(hash common.Hash) ([]*entity.TxRecord, error) {
+	defer observe("tx", "get_txs_by_hash", time.Now())
+	return r.ITxRepository.GetTxsByHash(hash)
+}
+
+func (r *txRepository) GetBlockTxs(blockNumber uint64) ([]*entity.TxRecord, error) {
+	defer observe("tx", "get_block_txs", time.Now())
+	return r.ITxRepository.GetBlockTxs(blockNumber)
+}
+
+func (r *txRepository) DeleteBlockTxs(blockNumber uint64) ([]*entity.TxRecord, error) {
+	defer observe("tx", "delete_block_txs", time.Now())
+	return r.ITxRepository.DeleteBlockTxs(blockNumber)
+}
+
+type checkpointRepository struct {
+	parser.ICheckpointRepository
+}
+
+func (r *checkpointRepository) GetCheckpoint() (uint64, error) {
+	defer observe("checkpoint", "get_checkpoint", time.Now())
+	return r.ICheckpointRepository.GetCheckpoint()
+}
+
+func (r *checkpointRepository) SaveCheckpoint(blockNumber uint64) error {
+	defer observe("checkpoint", "save_checkpoint", time.Now())
+	return r.ICheckpointRepository.SaveCheckpoint(blockNumber)
+}
+
+type webhookRepository struct {
+	webhook.IRepository
+}
+
+func (r *webhookRepository) CreateWebhook(webhook *entity.Webhook) error {
+	defer observe("webhook", "create_webhook", time.Now())
+	return r.IRepository.CreateWebhook(webhook)
+}
+
+func (r *webhookRepository) GetWebhook(id string) (*entity.Webhook, error) {
+	defer observe("webhook", "get_webhook", time.Now())
+	return r.IRepository.GetWebhook(id)
+}
+
+func (r *webhookRepository) DeleteWebhook(id string) error {
+	defer observe("webhook", "delete_webhook", time.Now())
+	return r.IRepository.DeleteWebhook(id)
+}
+
+func (r *webhookRepository) ListWebhooks(address string) ([]*entity.Webhook, error) {
+	defer observe("webhook", "list_webhooks", time.Now())
+	return r.IRepository.ListWebhooks(address)
+}
+
+func (r *webhookRepository) SaveDelivery(delivery *entity.WebhookDelivery) error {
+	defer observe("webhook", "save_delivery", time.Now())
+	return r.IRepository.SaveDelivery(delivery)
+}
+
+func (r *webhookRepository) GetDelivery(id string) (*entity.WebhookDelivery, error) {
+	defer observe("webhook", "get_delivery", time.Now())
+	return r.IRepository.GetDelivery(id)
+}
+
+func (r *webhookRepository) ListDeliveries(query entity.DeliveryQuery) (*entity.DeliveryPage, error) {
+	defer observe("webhook", "list_deliveries", time.Now())
+	return r.IRepository.ListDeliveries(query)
+}
+
+func (r *webhookRepository) DueDeliveries(now time.Time, limit int) ([]*entity.WebhookDelivery, error) {
+	defer observe("webhook", "due_deliveries", time.Now())
+	return r.IRepository.DueDeliveries(now, limit)
+}
+
+type outboxRepository struct {
+	sink.IOutboxRepository
+}
+
+func (r *outboxRepository) Append(message *entity.OutboxMessage) error {
+	defer observe("outbox", "append", time.Now())
+	return r.IOutboxRepository.Append(message)
+}
+
+func (r *outboxRepository) Pending(limit int) ([]*entity.OutboxMessage, error) {
+	defer observe("outbox", "pending", time.Now())
+	return r.IOutboxRepository.Pending(limit)
+}
+
+func (r *outboxRepository) Delete(messages []*entity.OutboxMessage) error {
+	defer observe("outbox", "delete", time.Now())
+	return r.IOutboxRepository.Delete(messages)
+}
@@ -0,0 +1,108 @@
+package outbox
+
+import (
+	"encoding/binary"
+	"time"
+
+	"github.com/ethereum/go-ethereum/rlp"
+	bolt "go.etcd.io/bbolt"
+
+	"github.com/vuquang23/trustme/internal/pkg/entity"
+	"github.com/vuquang23/trustme/internal/pkg/repository/boltdb"
+)
+
+// storedMessage is the RLP layout of an outbox message, keyed by its big-endian Seq.
+type storedMessage struct {
+	ID        string
+	Event     string
+	Key       string
+	Payload   []byte
+	CreatedAt uint64
+}
+
+type BoltRepository struct {
+	db *bolt.DB
+}
+
+func NewBoltRepository(db *bolt.DB) *BoltRepository {
+	return &BoltRepository{
+		db: db,
+	}
+}
+
+func (o *BoltRepository) Append(message *entity.OutboxMessage) error {
+	value, err := rlp.EncodeToBytes(storedMessage{
+		ID:        message.ID,
+		Event:     string(message.Event),
+		Key:       message.Key,
+		Payload:   message.Payload,
+		CreatedAt: uint64(message.CreatedAt.UnixNano()),
+	})
+	if err != nil {
+		return err
+	}
+
+	return o.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(boltdb.BucketOutbox)
+
+		seq, err := bucket.NextSequence()
+		if err != nil {
+			return err
+		}
+		if err := bucket.Put(seqKey(seq), value); err != nil {
+			return err
+		}
+
+		message.Seq = seq
+		return nil
+	})
+}
+
+func (o *BoltRepository) Pending(limit int) ([]*entity.OutboxMessage, error) {
+	messages := []*entity.OutboxMessage{}
+	err := o.db.View(func(tx *bolt.Tx) error {
+		c := tx.Bucket(boltdb.BucketOutbox).Cursor()
+		for k, v := c.First(); k != nil; k, v = c.Next() {
+			if limit > 0 && len(messages) == limit {
+				break
+			}
+
+			var stored storedMessage
+			if err := rlp.DecodeBytes(v, &stored); err != nil {
+				return err
+			}
+			messages = append(messages, &entity.OutboxMessage{
+				Seq:       binary.BigEndian.Uint64(k),
+				ID:        stored.ID,
+				Event:     entity.EventType(stored.Event),
+				Key:       stored.Key,
+				Payload:   stored.Payload,
+				CreatedAt: time.Unix(0, int64(stored.CreatedAt)).UTC(),
+			})
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return messages, nil
+}
+
+func (o *BoltRepository) Delete(messages []*entity.OutboxMessage) error {
+	return o.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(boltdb.BucketOutbox)
+		for _, message := range messages {
+			if err := bucket.Delete(seqKey(message.Seq)); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func seqKey(seq uint64) []byte {
+	key := make([]byte, 8)
+	binary.BigEndian.PutUint64(key, seq)
+	return key
+}
@@ -0,0 +1,68 @@
+package outbox
+
+import (
+	"sync"
+
+	"github.com/vuquang23/trustme/internal/pkg/entity"
+)
+
+type MemRepository struct {
+	mu       sync.Mutex
+	seq      uint64
+	messages []*entity.OutboxMessage
+}
+
+func NewMemRepository() *MemRepository {
+	return &MemRepository{}
+}
+
+func (o *MemRepository) Append(message *entity.OutboxMessage) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	o.seq++
+	message.Seq = o.seq
+
+	stored := *message
+	o.messages = append(o.messages, &stored)
+	return nil
+}
+
+func (o *MemRepository) Pending(limit int) ([]*entity.OutboxMessage, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	n := len(o.messages)
+	if limit > 0 && n > limit {
+		n = limit
+	}
+
+	messages := make([]*entity.OutboxMessage, 0, n)
+	for _, stored := range o.messages[:n] {
+		message := *stored
+		messages = append(messages, &message)
+	}
+	return messages, nil
+}
+
+func (o *MemRepository) Delete(messages []*entity.OutboxMessage) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	deleted := make(map[uint64]struct{}, len(messages))
+	for _, message := range messages {
+		deleted[message.Seq] = struct{}{}
+	}
+
+	kept := o.messages[:0]
+	for _, message := range o.messages {
+		if _, ok := deleted[message.Seq]; !ok {
+			kept = append(kept, message)
+		}
+	}
+	for i := len(kept); i < len(o.messages); i++ {
+		o.messages[i] = nil
+	}
+	o.messages = kept
+	return nil
+}
@@ -0,0 +1,86 @@
+package repository
+
+import (
+	"fmt"
+
+	bolt "go.etcd.io/bbolt"
+
+	"github.com/vuquang23/trustme/internal/pkg/auth"
+	"github.com/vuquang23/trustme/internal/pkg/parser"
+	"github.com/vuquang23/trustme/internal/pkg/repository/apikey"
+	"github.com/vuquang23/trustme/internal/pkg/repository/boltdb"
+	"github.com/vuquang23/trustme/internal/pkg/repository/checkpoint"
+	"github.com/vuquang23/trustme/internal/pkg/repository/outbox"
+	"github.com/vuquang23/trustme/internal/pkg/repository/subscriber"
+	tenantrepo "github.com/vuquang23/trustme/internal/pkg/repository/tenant"
+	"github.com/vuquang23/trustme/internal/pkg/repository/tx"
+	webhookrepo "github.com/vuquang23/trustme/internal/pkg/repository/webhook"
+	"github.com/vuquang23/trustme/internal/pkg/sink"
+	"github.com/vuquang23/trustme/internal/pkg/tenant"
+	"github.com/vuquang23/trustme/internal/pkg/webhook"
+)
+
+type Repositories struct {
+	Subscriber parser.ISubscriberRepository
+	Tx         parser.ITxRepository
+	Checkpoint parser.ICheckpointRepository
+	Webhook    webhook.IRepository
+	Outbox     sink.IOutboxRepository
+	APIKey     auth.IKeyRepository
+	Tenant     tenant.IRepository
+
+	ping  func() error
+	close func() error
+}
+
+// New builds the repositories of the configured backend.
+func New(cfg Config) (*Repositories, error) {
+	switch cfg.Backend {
+	case BackendMemory, "":
+		repos := &Repositories{
+			Subscriber: subscriber.NewMemRepository(),
+			Tx:         tx.NewMemRepository(cfg.Memory.MaxTxsPerAddress),
+			Checkpoint: checkpoint.NewMemRepository(),
+			Webhook:    webhookrepo.NewMemRepository(),
+			Outbox:     outbox.NewMemRepository(),
+			APIKey:     apikey.NewMemRepository(),
+			Tenant:     tenantrepo.NewMemRepository(),
+			ping:       func() error { return nil },
+			close:      func() error { return nil },
+		}
+		return repos.instrument(), nil
+
+	case BackendBolt:
+		db, err := boltdb.Open(cfg.Bolt.Path, &bolt.Options{Timeout: cfg.Bolt.Timeout}, cfg.Bolt.AutoMigrate)
+		if err != nil {
+			return nil, err
+		}
+
+		repos := &Repositories{
+			Subscriber: subscriber.NewBoltRepository(db),
+			Tx:         tx.NewBoltRepository(db),
+			Checkpoint: checkpoint.NewBoltRepository(db),
+			Webhook:    webhookrepo.NewBoltRepository(db),
+			Outbox:     outbox.NewBoltRepository(db),
+			APIKey:     apikey.NewBoltRepository(db),
+			Tenant:     tenantrepo.NewBoltRepository(db),
+			ping: func() error {
+				return db.View(func(*bolt.Tx) error { return nil })
+			},
+			close: db.Close,
+		}
+		return repos.instrument(), nil
+
+	default:
+		return nil, fmt.Errorf("unsupported repository backend: %s", cfg.Backend)
+	}
+}
+
+// Ping checks the storage backend can be read.
+func (r *Repositories) Ping() error {
+	return r.ping()
+}
+
+func (r *Repositories) Close() error {
+	return r.close()
+}
@@ -0,0 +1,127 @@
+package subscriber
+
+import (
+	"bytes"
+	"time"
+
+	"github.com/ethereum/go-ethereum/rlp"
+	bolt "go.etcd.io/bbolt"
+
+	"github.com/vuquang23/trustme/internal/pkg/entity"
+	"github.com/vuquang23/trustme/internal/pkg/repository/boltdb"
+)
+
+// storedSubscription is the RLP layout of a subscription, keyed by its address.
+type storedSubscription struct {
+	Label     string
+	Tags      []string
+	Owner     string
+	Notes     string
+	CreatedAt uint64
+}
+
+type BoltRepository struct {
+	db *bolt.DB
+}
+
+func NewBoltRepository(db *bolt.DB) *BoltRepository {
+	return &BoltRepository{
+		db: db,
+	}
+}
+
+func (s *BoltRepository) Create(subscription *entity.Subscription) error {
+	value, err := rlp.EncodeToBytes(storedSubscription{
+		Label:     subscription.Label,
+		Tags:      subscription.Tags,
+		Owner:     subscription.Owner,
+		Notes:     subscription.Notes,
+		CreatedAt: uint64(subscription.CreatedAt.Unix()),
+	})
+	if err != nil {
+		return err
+	}
+
+	return s.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(boltdb.BucketSubscribers).Put([]byte(subscription.Address), value)
+	})
+}
+
+func (s *BoltRepository) Delete(address string) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(boltdb.BucketSubscribers).Delete([]byte(address))
+	})
+}
+
+func (s *BoltRepository) Get(address string) (*entity.Subscription, error) {
+	var subscription *entity.Subscription
+	err := s.db.View(func(tx *bolt.Tx) error {
+		v := tx.Bucket(boltdb.BucketSubscribers).Get([]byte(address))
+		if v == nil {
+			return nil
+		}
+
+		var err error
+		subscription, err = decodeSubscription([]byte(address), v)
+		return err
+	})
+	return subscription, err
+}
+
+func (s *BoltRepository) IsSubscriber(address string) bool {
+	var ok bool
+	_ = s.db.View(func(tx *bolt.Tx) error {
+		ok = tx.Bucket(boltdb.BucketSubscribers).Get([]byte(address)) != nil
+		return nil
+	})
+	return ok
+}
+
+func (s *BoltRepository) List(cursor string, limit int) (*entity.SubscriptionPage, error) {
+	page := &entity.SubscriptionPage{Subscriptions: []*entity.Subscription{}}
+	err := s.db.View(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(boltdb.BucketSubscribers)
+		page.Total = bucket.Stats().KeyN
+
+		c := bucket.Cursor()
+		k, v := c.Seek([]byte(cursor))
+		if k != nil && cursor != "" && bytes.Equal(k, []byte(cursor)) {
+			k, v = c.Next()
+		}
+
+		for ; k != nil; k, v = c.Next() {
+			if limit > 0 && len(page.Subscriptions) == limit {
+				page.NextCursor = page.Subscriptions[len(page.Subscriptions)-1].Address
+				break
+			}
+
+			subscription, err := decodeSubscription(k, v)
+			if err != nil {
+				return err
+			}
+			page.Subscriptions = append(page.Subscriptions, subscription)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return page, nil
+}
+
+func decodeSubscription(key, value []byte) (*entity.Subscription, error) {
+	var stored storedSubscription
+	if err := rlp.DecodeBytes(value, &stored); err != nil {
+		return nil, err
+	}
+
+	return &entity.Subscription{
+		Address:   string(key),
+		Label:     stored.Label,
+		Tags:      stored.Tags,
+		Owner:     stored.Owner,
+		Notes:     stored.Notes,
+		CreatedAt: time.Unix(int64(stored.CreatedAt), 0).UTC(),
+	}, nil
+}
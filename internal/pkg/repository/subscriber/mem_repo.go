@@ -1,23 +1,85 @@
 package subscriber
 
-import "sync"
+import (
+	"sort"
+	"sync"
+
+	"github.com/vuquang23/trustme/internal/pkg/entity"
+)
 
 type MemRepository struct {
-	data sync.Map
+	mu   sync.RWMutex
+	data map[string]entity.Subscription
 }
 
 func NewMemRepository() *MemRepository {
 	return &MemRepository{
-		data: sync.Map{},
+		data: make(map[string]entity.Subscription),
 	}
 }
 
-func (s *MemRepository) Create(address string) error {
-	s.data.Store(address, struct{}{})
+func (s *MemRepository) Create(subscription *entity.Subscription) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.data[subscription.Address] = *subscription
+	return nil
+}
+
+func (s *MemRepository) Delete(address string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.data, address)
 	return nil
 }
 
+func (s *MemRepository) Get(address string) (*entity.Subscription, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	subscription, ok := s.data[address]
+	if !ok {
+		return nil, nil
+	}
+	return &subscription, nil
+}
+
 func (s *MemRepository) IsSubscriber(address string) bool {
-	_, ok := s.data.Load(address)
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	_, ok := s.data[address]
 	return ok
 }
+
+func (s *MemRepository) List(cursor string, limit int) (*entity.SubscriptionPage, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	addresses := make([]string, 0, len(s.data))
+	for address := range s.data {
+		addresses = append(addresses, address)
+	}
+	sort.Strings(addresses)
+
+	page := &entity.SubscriptionPage{
+		Subscriptions: []*entity.Subscription{},
+		Total:         len(addresses),
+	}
+
+	i := sort.SearchStrings(addresses, cursor)
+	if i < len(addresses) && addresses[i] == cursor {
+		i++
+	}
+	for ; i < len(addresses); i++ {
+		if limit > 0 && len(page.Subscriptions) == limit {
+			page.NextCursor = page.Subscriptions[len(page.Subscriptions)-1].Address
+			break
+		}
+		subscription := s.data[addresses[i]]
+		page.Subscriptions = append(page.Subscriptions, &subscription)
+	}
+
+	return page, nil
+}
@@ -0,0 +1,157 @@
+package tenant
+
+import (
+	"bytes"
+	"time"
+
+	"github.com/ethereum/go-ethereum/rlp"
+	bolt "go.etcd.io/bbolt"
+
+	"github.com/vuquang23/trustme/internal/pkg/entity"
+	"github.com/vuquang23/trustme/internal/pkg/repository/boltdb"
+)
+
+// keySeparator separates the two parts of the index keys, it can't be part of a tenant or an address.
+const keySeparator = 0
+
+// storedSubscription is the RLP layout of a tenant subscription.
+type storedSubscription struct {
+	Label     string
+	Tags      []string
+	Owner     string
+	Notes     string
+	CreatedAt uint64
+}
+
+// BoltRepository stores the subscriptions of the tenants in two buckets:
+//   - tenantSubscriptions: tenant + address, holding the subscription.
+//   - subscriptionTenants: address + tenant, to find the tenants of an address.
+type BoltRepository struct {
+	db *bolt.DB
+}
+
+func NewBoltRepository(db *bolt.DB) *BoltRepository {
+	return &BoltRepository{
+		db: db,
+	}
+}
+
+func (t *BoltRepository) Create(tenant string, subscription *entity.Subscription) error {
+	value, err := rlp.EncodeToBytes(storedSubscription{
+		Label:     subscription.Label,
+		Tags:      subscription.Tags,
+		Owner:     subscription.Owner,
+		Notes:     subscription.Notes,
+		CreatedAt: uint64(subscription.CreatedAt.Unix()),
+	})
+	if err != nil {
+		return err
+	}
+
+	return t.db.Update(func(tx *bolt.Tx) error {
+		if err := tx.Bucket(boltdb.BucketTenantSubscriptions).Put(indexKey(tenant, subscription.Address), value); err != nil {
+			return err
+		}
+		return tx.Bucket(boltdb.BucketSubscriptionTenants).Put(indexKey(subscription.Address, tenant), nil)
+	})
+}
+
+func (t *BoltRepository) Delete(tenant, address string) error {
+	return t.db.Update(func(tx *bolt.Tx) error {
+		if err := tx.Bucket(boltdb.BucketTenantSubscriptions).Delete(indexKey(tenant, address)); err != nil {
+			return err
+		}
+		return tx.Bucket(boltdb.BucketSubscriptionTenants).Delete(indexKey(address, tenant))
+	})
+}
+
+func (t *BoltRepository) Get(tenant, address string) (*entity.Subscription, error) {
+	var subscription *entity.Subscription
+	err := t.db.View(func(tx *bolt.Tx) error {
+		v := tx.Bucket(boltdb.BucketTenantSubscriptions).Get(indexKey(tenant, address))
+		if v == nil {
+			return nil
+		}
+
+		var err error
+		subscription, err = decodeSubscription(address, v)
+		return err
+	})
+	return subscription, err
+}
+
+func (t *BoltRepository) List(tenant, cursor string, limit int) (*entity.SubscriptionPage, error) {
+	page := &entity.SubscriptionPage{Subscriptions: []*entity.Subscription{}}
+	err := t.db.View(func(tx *bolt.Tx) error {
+		prefix := indexKey(tenant, "")
+		c := tx.Bucket(boltdb.BucketTenantSubscriptions).Cursor()
+
+		for k, _ := c.Seek(prefix); k != nil && bytes.HasPrefix(k, prefix); k, _ = c.Next() {
+			page.Total++
+		}
+
+		k, v := c.Seek(indexKey(tenant, cursor))
+		if k != nil && cursor != "" && bytes.Equal(k, indexKey(tenant, cursor)) {
+			k, v = c.Next()
+		}
+
+		for ; k != nil && bytes.HasPrefix(k, prefix); k, v = c.Next() {
+			if limit > 0 && len(page.Subscriptions) == limit {
+				page.NextCursor = page.Subscriptions[len(page.Subscriptions)-1].Address
+				break
+			}
+
+			subscription, err := decodeSubscription(string(k[len(prefix):]), v)
+			if err != nil {
+				return err
+			}
+			page.Subscriptions = append(page.Subscriptions, subscription)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return page, nil
+}
+
+func (t *BoltRepository) Tenants(address string) ([]string, error) {
+	tenants := []string{}
+	err := t.db.View(func(tx *bolt.Tx) error {
+		prefix := indexKey(address, "")
+		c := tx.Bucket(boltdb.BucketSubscriptionTenants).Cursor()
+		for k, _ := c.Seek(prefix); k != nil && bytes.HasPrefix(k, prefix); k, _ = c.Next() {
+			tenants = append(tenants, string(k[len(prefix):]))
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return tenants, nil
+}
+
+func indexKey(first, second string) []byte {
+	key := make([]byte, 0, len(first)+1+len(second))
+	key = append(key, first...)
+	key = append(key, keySeparator)
+	return append(key, second...)
+}
+
+func decodeSubscription(address string, value []byte) (*entity.Subscription, error) {
+	var stored storedSubscription
+	if err := rlp.DecodeBytes(value, &stored); err != nil {
+		return nil, err
+	}
+
+	return &entity.Subscription{
+		Address:   address,
+		Label:     stored.Label,
+		Tags:      stored.Tags,
+		Owner:     stored.Owner,
+		Notes:     stored.Notes,
+		CreatedAt: time.Unix(int64(stored.CreatedAt), 0).UTC(),
+	}, nil
+}
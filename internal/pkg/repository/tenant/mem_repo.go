@@ -0,0 +1,101 @@
+package tenant
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/vuquang23/trustme/internal/pkg/entity"
+)
+
+type MemRepository struct {
+	mu sync.RWMutex
+	// data holds the subscriptions of each tenant by address.
+	data map[string]map[string]entity.Subscription
+}
+
+func NewMemRepository() *MemRepository {
+	return &MemRepository{
+		data: make(map[string]map[string]entity.Subscription),
+	}
+}
+
+func (t *MemRepository) Create(tenant string, subscription *entity.Subscription) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	subscriptions, ok := t.data[tenant]
+	if !ok {
+		subscriptions = make(map[string]entity.Subscription)
+		t.data[tenant] = subscriptions
+	}
+	subscriptions[subscription.Address] = *subscription
+	return nil
+}
+
+func (t *MemRepository) Delete(tenant, address string) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	delete(t.data[tenant], address)
+	if len(t.data[tenant]) == 0 {
+		delete(t.data, tenant)
+	}
+	return nil
+}
+
+func (t *MemRepository) Get(tenant, address string) (*entity.Subscription, error) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	subscription, ok := t.data[tenant][address]
+	if !ok {
+		return nil, nil
+	}
+	return &subscription, nil
+}
+
+func (t *MemRepository) List(tenant, cursor string, limit int) (*entity.SubscriptionPage, error) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	subscriptions := t.data[tenant]
+	addresses := make([]string, 0, len(subscriptions))
+	for address := range subscriptions {
+		addresses = append(addresses, address)
+	}
+	sort.Strings(addresses)
+
+	page := &entity.SubscriptionPage{
+		Subscriptions: []*entity.Subscription{},
+		Total:         len(addresses),
+	}
+
+	i := sort.SearchStrings(addresses, cursor)
+	if i < len(addresses) && addresses[i] == cursor {
+		i++
+	}
+	for ; i < len(addresses); i++ {
+		if limit > 0 && len(page.Subscriptions) == limit {
+			page.NextCursor = page.Subscriptions[len(page.Subscriptions)-1].Address
+			break
+		}
+		subscription := subscriptions[addresses[i]]
+		page.Subscriptions = append(page.Subscriptions, &subscription)
+	}
+
+	return page, nil
+}
+
+func (t *MemRepository) Tenants(address string) ([]string, error) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	tenants := []string{}
+	for tenant, subscriptions := range t.data {
+		if _, ok := subscriptions[address]; ok {
+			tenants = append(tenants, tenant)
+		}
+	}
+	sort.Strings(tenants)
+	return tenants, nil
+}
@@ -0,0 +1,317 @@
+package tx
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+
+	"github.com/ethereum/go-ethereum/common"
+	bolt "go.etcd.io/bbolt"
+
+	"github.com/vuquang23/trustme/internal/pkg/entity"
+	"github.com/vuquang23/trustme/internal/pkg/repository/boltdb"
+)
+
+// BoltRepository keeps one nested bucket per address inside the txs bucket.
+// Keys are the big-endian (block number, tx index) pair so a cursor walks the history in chain order,
+// values are the RLP encoding of the record (see storedTxRecord).
+//
+// Two indexes are maintained next to it:
+//   - txHashes: tx hash + address -> position, to make saves idempotent and find a transaction by hash.
+//   - blockTxs: position + address -> tx hash, to drop a whole block on reorg.
+type BoltRepository struct {
+	db *bolt.DB
+}
+
+func NewBoltRepository(db *bolt.DB) *BoltRepository {
+	return &BoltRepository{
+		db: db,
+	}
+}
+
+func (t *BoltRepository) SaveTx(record *entity.TxRecord) error {
+	value, err := encodeRecord(record)
+	if err != nil {
+		return err
+	}
+
+	address := record.Address
+
+	return t.db.Update(func(btx *bolt.Tx) error {
+		bucket, err := btx.Bucket(boltdb.BucketTxs).CreateBucketIfNotExists([]byte(address))
+		if err != nil {
+			return err
+		}
+
+		hashes := btx.Bucket(boltdb.BucketTxHashes)
+		blocks := btx.Bucket(boltdb.BucketBlockTxs)
+
+		hash := record.Hash()
+		hashKey := append(hash.Bytes(), address...)
+		if oldPos := hashes.Get(hashKey); oldPos != nil {
+			oldPos = bytes.Clone(oldPos)
+			if err := bucket.Delete(oldPos); err != nil {
+				return err
+			}
+			if err := blocks.Delete(append(oldPos, address...)); err != nil {
+				return err
+			}
+		}
+
+		// A position holds one transaction per address. The one there before, from a block since replaced, is
+		// overwritten and its hash entry dropped.
+		pos := positionKey(record.BlockNumber, record.TxIndex)
+		blockKey := append(bytes.Clone(pos), address...)
+		if occupant := blocks.Get(blockKey); occupant != nil {
+			if err := hashes.Delete(append(bytes.Clone(occupant), address...)); err != nil {
+				return err
+			}
+		}
+
+		if err := bucket.Put(pos, value); err != nil {
+			return err
+		}
+		if err := hashes.Put(hashKey, pos); err != nil {
+			return err
+		}
+		return blocks.Put(blockKey, hash.Bytes())
+	})
+}
+
+func (t *BoltRepository) GetTxs(address string) ([]*entity.TxRecord, error) {
+	records := []*entity.TxRecord{}
+	err := t.db.View(func(btx *bolt.Tx) error {
+		bucket := btx.Bucket(boltdb.BucketTxs).Bucket([]byte(address))
+		if bucket == nil {
+			return nil
+		}
+
+		return bucket.ForEach(func(k, v []byte) error {
+			record, err := decodeRecord(address, k, v)
+			if err != nil {
+				return err
+			}
+			records = append(records, record)
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return records, nil
+}
+
+func (t *BoltRepository) GetBlockTxs(blockNumber uint64) ([]*entity.TxRecord, error) {
+	prefix := make([]byte, 8)
+	binary.BigEndian.PutUint64(prefix, blockNumber)
+
+	var records []*entity.TxRecord
+	err := t.db.View(func(btx *bolt.Tx) error {
+		txs := btx.Bucket(boltdb.BucketTxs)
+
+		c := btx.Bucket(boltdb.BucketBlockTxs).Cursor()
+		for k, _ := c.Seek(prefix); k != nil && bytes.HasPrefix(k, prefix); k, _ = c.Next() {
+			pos, address := k[:12], k[12:]
+			bucket := txs.Bucket(address)
+			if bucket == nil {
+				continue
+			}
+			value := bucket.Get(pos)
+			if value == nil {
+				continue
+			}
+
+			record, err := decodeRecord(string(address), pos, value)
+			if err != nil {
+				return err
+			}
+			records = append(records, record)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return records, nil
+}
+
+func (t *BoltRepository) DeleteBlockTxs(blockNumber uint64) ([]*entity.TxRecord, error) {
+	prefix := make([]byte, 8)
+	binary.BigEndian.PutUint64(prefix, blockNumber)
+
+	var records []*entity.TxRecord
+	err := t.db.Update(func(btx *bolt.Tx) error {
+		txs := btx.Bucket(boltdb.BucketTxs)
+		hashes := btx.Bucket(boltdb.BucketTxHashes)
+		blocks := btx.Bucket(boltdb.BucketBlockTxs)
+
+		var keys [][]byte
+		c := blocks.Cursor()
+		for k, v := c.Seek(prefix); k != nil && bytes.HasPrefix(k, prefix); k, v = c.Next() {
+			pos, address := k[:12], k[12:]
+			if bucket := txs.Bucket(address); bucket != nil {
+				if value := bucket.Get(pos); value != nil {
+					record, err := decodeRecord(string(address), pos, value)
+					if err != nil {
+						return err
+					}
+					records = append(records, record)
+				}
+				if err := bucket.Delete(pos); err != nil {
+					return err
+				}
+			}
+			hashKey := append(common.BytesToHash(v).Bytes(), address...)
+			if err := hashes.Delete(hashKey); err != nil {
+				return err
+			}
+			keys = append(keys, bytes.Clone(k))
+		}
+
+		for _, k := range keys {
+			if err := blocks.Delete(k); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return records, nil
+}
+
+// QueryTxs walks the address bucket in key order, so the cursor and the block range are resolved with seeks
+// while the remaining filters are applied on the decoded records. The total is left unset, counting it would walk
+// the whole address bucket on every page.
+func (t *BoltRepository) QueryTxs(address string, query entity.TxQuery) (*entity.TxPage, error) {
+	desc := query.Order == entity.SortOrderDesc
+
+	var cursorKey []byte
+	if query.Cursor != "" {
+		blockNumber, txIndex, err := entity.DecodeTxCursor(query.Cursor)
+		if err != nil {
+			return nil, err
+		}
+		cursorKey = positionKey(blockNumber, txIndex)
+	}
+
+	page := &entity.TxPage{Records: []*entity.TxRecord{}}
+	err := t.db.View(func(btx *bolt.Tx) error {
+		bucket := btx.Bucket(boltdb.BucketTxs).Bucket([]byte(address))
+		if bucket == nil {
+			total := 0
+			page.Total = &total
+			return nil
+		}
+
+		c := bucket.Cursor()
+		k, v := t.seek(c, query, cursorKey)
+		for ; k != nil; k, v = next(c, desc) {
+			blockNumber, _ := splitPositionKey(k)
+			if (!desc && query.ToBlock != nil && blockNumber > *query.ToBlock) ||
+				(desc && query.FromBlock != nil && blockNumber < *query.FromBlock) {
+				break
+			}
+
+			record, err := decodeRecord(address, k, v)
+			if err != nil {
+				return err
+			}
+			if !query.Match(record) {
+				continue
+			}
+
+			if query.Limit > 0 && len(page.Records) == query.Limit {
+				last := page.Records[len(page.Records)-1]
+				page.NextCursor = entity.EncodeTxCursor(last.BlockNumber, last.TxIndex)
+				break
+			}
+			page.Records = append(page.Records, record)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return page, nil
+}
+
+// seek positions the cursor on the first key to visit.
+func (t *BoltRepository) seek(c *bolt.Cursor, query entity.TxQuery, cursorKey []byte) ([]byte, []byte) {
+	if query.Order == entity.SortOrderDesc {
+		var upper []byte
+		switch {
+		case cursorKey != nil:
+			upper = cursorKey
+		case query.ToBlock != nil && *query.ToBlock < math.MaxUint64:
+			upper = positionKey(*query.ToBlock+1, 0)
+		default:
+			return c.Last()
+		}
+
+		// Seek lands on the first key >= upper, the page starts right before it.
+		if k, _ := c.Seek(upper); k == nil {
+			return c.Last()
+		}
+		return c.Prev()
+	}
+
+	switch {
+	case cursorKey != nil:
+		k, v := c.Seek(cursorKey)
+		if k != nil && bytes.Equal(k, cursorKey) {
+			return c.Next()
+		}
+		return k, v
+	case query.FromBlock != nil:
+		return c.Seek(positionKey(*query.FromBlock, 0))
+	default:
+		return c.First()
+	}
+}
+
+func next(c *bolt.Cursor, desc bool) ([]byte, []byte) {
+	if desc {
+		return c.Prev()
+	}
+	return c.Next()
+}
+
+func (t *BoltRepository) GetTxsByHash(hash common.Hash) ([]*entity.TxRecord, error) {
+	records := []*entity.TxRecord{}
+	err := t.db.View(func(btx *bolt.Tx) error {
+		txs := btx.Bucket(boltdb.BucketTxs)
+
+		prefix := hash.Bytes()
+		c := btx.Bucket(boltdb.BucketTxHashes).Cursor()
+		for k, pos := c.Seek(prefix); k != nil && bytes.HasPrefix(k, prefix); k, pos = c.Next() {
+			address := string(k[len(prefix):])
+
+			bucket := txs.Bucket([]byte(address))
+			if bucket == nil {
+				continue
+			}
+			v := bucket.Get(pos)
+			if v == nil {
+				continue
+			}
+
+			record, err := decodeRecord(address, pos, v)
+			if err != nil {
+				return err
+			}
+			records = append(records, record)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return records, nil
+}
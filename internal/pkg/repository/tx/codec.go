@@ -0,0 +1,87 @@
+package tx
+
+import (
+	"encoding/binary"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rlp"
+
+	"github.com/vuquang23/trustme/internal/pkg/entity"
+)
+
+// storedTxRecord is the RLP layout of a record on disk.
+// Address, block number and tx index are part of the key, so they are not repeated here.
+type storedTxRecord struct {
+	Tx                []byte
+	From              common.Address
+	Direction         string
+	BlockHash         common.Hash
+	Timestamp         uint64
+	Status            string
+	GasUsed           uint64
+	EffectiveGasPrice *big.Int
+}
+
+func encodeRecord(record *entity.TxRecord) ([]byte, error) {
+	txBytes, err := record.Tx.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+
+	effectiveGasPrice := record.EffectiveGasPrice
+	if effectiveGasPrice == nil {
+		effectiveGasPrice = new(big.Int)
+	}
+
+	return rlp.EncodeToBytes(storedTxRecord{
+		Tx:                txBytes,
+		From:              record.From,
+		Direction:         string(record.Direction),
+		BlockHash:         record.BlockHash,
+		Timestamp:         record.Timestamp,
+		Status:            string(record.Status),
+		GasUsed:           record.GasUsed,
+		EffectiveGasPrice: effectiveGasPrice,
+	})
+}
+
+func decodeRecord(address string, key, value []byte) (*entity.TxRecord, error) {
+	var stored storedTxRecord
+	if err := rlp.DecodeBytes(value, &stored); err != nil {
+		return nil, err
+	}
+
+	var tx types.Transaction
+	if err := tx.UnmarshalBinary(stored.Tx); err != nil {
+		return nil, err
+	}
+
+	blockNumber, txIndex := splitPositionKey(key)
+
+	return &entity.TxRecord{
+		Address:           address,
+		Direction:         entity.TxDirection(stored.Direction),
+		Tx:                &tx,
+		From:              stored.From,
+		BlockNumber:       blockNumber,
+		BlockHash:         stored.BlockHash,
+		Timestamp:         stored.Timestamp,
+		TxIndex:           txIndex,
+		Status:            entity.TxStatus(stored.Status),
+		GasUsed:           stored.GasUsed,
+		EffectiveGasPrice: stored.EffectiveGasPrice,
+	}, nil
+}
+
+func positionKey(blockNumber uint64, txIndex uint) []byte {
+	key := make([]byte, 12)
+	binary.BigEndian.PutUint64(key[:8], blockNumber)
+	binary.BigEndian.PutUint32(key[8:], uint32(txIndex))
+	return key
+}
+
+func splitPositionKey(key []byte) (uint64, uint) {
+	return binary.BigEndian.Uint64(key[:8]), uint(binary.BigEndian.Uint32(key[8:12]))
+}
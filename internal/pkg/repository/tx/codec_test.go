@@ -0,0 +1,53 @@
+package tx
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+
+	"github.com/vuquang23/trustme/internal/pkg/entity"
+)
+
+func TestRecordCodec(t *testing.T) {
+	to := common.HexToAddress("0x1f9090aae28b8a3dceadf281b0f12828e676c326")
+	tx := types.NewTx(&types.DynamicFeeTx{ChainID: big.NewInt(1), Nonce: 7, To: &to, Value: big.NewInt(5), Gas: 21000})
+	record := &entity.TxRecord{
+		Address:           to.Hex(),
+		Direction:         entity.TxDirectionIn,
+		Tx:                tx,
+		From:              common.HexToAddress("0x2"),
+		BlockNumber:       19000000,
+		BlockHash:         common.HexToHash("0x3"),
+		Timestamp:         1700000000,
+		TxIndex:           4,
+		Status:            entity.TxStatusSuccess,
+		GasUsed:           21000,
+		EffectiveGasPrice: big.NewInt(9),
+	}
+
+	value, err := encodeRecord(record)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := decodeRecord(record.Address, positionKey(record.BlockNumber, record.TxIndex), value)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Hash() != tx.Hash() || got.Address != record.Address || got.Direction != record.Direction ||
+		got.From != record.From || got.BlockNumber != record.BlockNumber || got.BlockHash != record.BlockHash ||
+		got.Timestamp != record.Timestamp || got.TxIndex != record.TxIndex || got.Status != record.Status ||
+		got.GasUsed != record.GasUsed || got.EffectiveGasPrice.Cmp(record.EffectiveGasPrice) != 0 {
+		t.Fatalf("got %+v, want %+v", got, record)
+	}
+
+	// only records are stored, a bare binary transaction is not one
+	txBytes, err := tx.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := decodeRecord(record.Address, positionKey(1, 0), txBytes); err == nil {
+		t.Fatal("got no error decoding a binary transaction")
+	}
+}
@@ -1,32 +1,251 @@
 package tx
 
 import (
+	"hash/fnv"
+	"sort"
 	"sync"
 
-	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/vuquang23/trustme/internal/pkg/entity"
 )
 
+const shardCount = 32
+
+func before(record *entity.TxRecord, blockNumber uint64, txIndex uint) bool {
+	if record.BlockNumber != blockNumber {
+		return record.BlockNumber < blockNumber
+	}
+	return record.TxIndex < txIndex
+}
+
+// addressTxs is the history of one address, sorted by (block number, tx index).
+type addressTxs struct {
+	entries []*entity.TxRecord
+	hashes  map[common.Hash]*entity.TxRecord
+}
+
+func (a *addressTxs) search(blockNumber uint64, txIndex uint) int {
+	return sort.Search(len(a.entries), func(i int) bool {
+		return !before(a.entries[i], blockNumber, txIndex)
+	})
+}
+
+// remove deletes a stored record.
+func (a *addressTxs) remove(record *entity.TxRecord) {
+	for i := a.search(record.BlockNumber, record.TxIndex); i < len(a.entries); i++ {
+		entry := a.entries[i]
+		if entry.BlockNumber != record.BlockNumber || entry.TxIndex != record.TxIndex {
+			break
+		}
+		if entry.Hash() == record.Hash() {
+			a.entries = append(a.entries[:i], a.entries[i+1:]...)
+			break
+		}
+	}
+	delete(a.hashes, record.Hash())
+}
+
+type shard struct {
+	mu   sync.RWMutex
+	data map[string]*addressTxs
+}
+
+// MemRepository shards addresses over a fixed number of locked maps, so saves for the same address are
+// serialized while different addresses rarely contend. Readers always get a copy of the history.
 type MemRepository struct {
-	data sync.Map
+	shards           [shardCount]shard
+	maxTxsPerAddress int
 }
 
-func NewMemRepository() *MemRepository {
-	return &MemRepository{
-		data: sync.Map{},
+// NewMemRepository creates the repository. When maxTxsPerAddress is positive, the oldest entries of an
+// address are evicted once its history grows past it.
+func NewMemRepository(maxTxsPerAddress int) *MemRepository {
+	t := &MemRepository{
+		maxTxsPerAddress: maxTxsPerAddress,
+	}
+	for i := range t.shards {
+		t.shards[i].data = make(map[string]*addressTxs)
 	}
+	return t
 }
 
-func (t *MemRepository) SaveTx(address string, tx *types.Transaction) error {
-	txs, _ := t.GetTxs(address)
-	txs = append(txs, tx)
-	t.data.Store(address, txs)
+func (t *MemRepository) SaveTx(record *entity.TxRecord) error {
+	s := t.shard(record.Address)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	history, ok := s.data[record.Address]
+	if !ok {
+		history = &addressTxs{hashes: make(map[common.Hash]*entity.TxRecord)}
+		s.data[record.Address] = history
+	}
+
+	if old, ok := history.hashes[record.Hash()]; ok {
+		history.remove(old)
+	}
+
+	// a position holds one transaction per address, the one there before is from a block since replaced
+	i := history.search(record.BlockNumber, record.TxIndex)
+	for i < len(history.entries) && history.entries[i].BlockNumber == record.BlockNumber && history.entries[i].TxIndex == record.TxIndex {
+		history.remove(history.entries[i])
+	}
+
+	stored := *record
+	history.entries = append(history.entries, nil)
+	copy(history.entries[i+1:], history.entries[i:])
+	history.entries[i] = &stored
+	history.hashes[stored.Hash()] = &stored
+
+	if t.maxTxsPerAddress > 0 {
+		for len(history.entries) > t.maxTxsPerAddress {
+			history.remove(history.entries[0])
+		}
+	}
+
 	return nil
 }
 
-func (t *MemRepository) GetTxs(address string) ([]*types.Transaction, error) {
-	txs, ok := t.data.Load(address)
+func (t *MemRepository) GetTxs(address string) ([]*entity.TxRecord, error) {
+	s := t.shard(address)
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	history, ok := s.data[address]
 	if !ok {
-		txs = []*types.Transaction{}
+		return []*entity.TxRecord{}, nil
 	}
-	return txs.([]*types.Transaction), nil
+
+	records := make([]*entity.TxRecord, 0, len(history.entries))
+	for _, entry := range history.entries {
+		record := *entry
+		records = append(records, &record)
+	}
+	return records, nil
+}
+
+func (t *MemRepository) GetBlockTxs(blockNumber uint64) ([]*entity.TxRecord, error) {
+	var records []*entity.TxRecord
+	for i := range t.shards {
+		s := &t.shards[i]
+		s.mu.RLock()
+		for _, history := range s.data {
+			for j := history.search(blockNumber, 0); j < len(history.entries) && history.entries[j].BlockNumber == blockNumber; j++ {
+				record := *history.entries[j]
+				records = append(records, &record)
+			}
+		}
+		s.mu.RUnlock()
+	}
+	return records, nil
+}
+
+func (t *MemRepository) DeleteBlockTxs(blockNumber uint64) ([]*entity.TxRecord, error) {
+	var records []*entity.TxRecord
+	for i := range t.shards {
+		s := &t.shards[i]
+		s.mu.Lock()
+		for _, history := range s.data {
+			from := history.search(blockNumber, 0)
+			to := from
+			for to < len(history.entries) && history.entries[to].BlockNumber == blockNumber {
+				records = append(records, history.entries[to])
+				delete(history.hashes, history.entries[to].Hash())
+				to++
+			}
+			history.entries = append(history.entries[:from], history.entries[to:]...)
+		}
+		s.mu.Unlock()
+	}
+	return records, nil
+}
+
+func (t *MemRepository) shard(address string) *shard {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(address))
+	return &t.shards[h.Sum32()%shardCount]
+}
+
+func (t *MemRepository) QueryTxs(address string, query entity.TxQuery) (*entity.TxPage, error) {
+	var (
+		cursorBlock uint64
+		cursorIndex uint
+		err         error
+	)
+	if query.Cursor != "" {
+		cursorBlock, cursorIndex, err = entity.DecodeTxCursor(query.Cursor)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	s := t.shard(address)
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	page := &entity.TxPage{Records: []*entity.TxRecord{}}
+
+	history, ok := s.data[address]
+	if !ok {
+		total := 0
+		page.Total = &total
+		return page, nil
+	}
+	entries := history.entries
+
+	total := 0
+	for _, entry := range entries {
+		if query.Match(entry) {
+			total++
+		}
+	}
+	page.Total = &total
+
+	i, step := 0, 1
+	if query.Order == entity.SortOrderDesc {
+		i, step = len(entries)-1, -1
+	}
+	if query.Cursor != "" {
+		i = history.search(cursorBlock, cursorIndex)
+		if query.Order == entity.SortOrderDesc {
+			i--
+		} else if i < len(entries) && entries[i].BlockNumber == cursorBlock && entries[i].TxIndex == cursorIndex {
+			i++
+		}
+	}
+
+	for ; i >= 0 && i < len(entries); i += step {
+		if !query.Match(entries[i]) {
+			continue
+		}
+
+		if query.Limit > 0 && len(page.Records) == query.Limit {
+			last := page.Records[len(page.Records)-1]
+			page.NextCursor = entity.EncodeTxCursor(last.BlockNumber, last.TxIndex)
+			break
+		}
+
+		record := *entries[i]
+		page.Records = append(page.Records, &record)
+	}
+
+	return page, nil
+}
+
+func (t *MemRepository) GetTxsByHash(hash common.Hash) ([]*entity.TxRecord, error) {
+	records := []*entity.TxRecord{}
+	for i := range t.shards {
+		s := &t.shards[i]
+		s.mu.RLock()
+		for _, history := range s.data {
+			if entry, ok := history.hashes[hash]; ok {
+				record := *entry
+				records = append(records, &record)
+			}
+		}
+		s.mu.RUnlock()
+	}
+
+	sort.Slice(records, func(i, j int) bool { return records[i].Address < records[j].Address })
+	return records, nil
 }
@@ -0,0 +1,190 @@
+package tx
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/core/types"
+
+	"github.com/vuquang23/trustme/internal/pkg/entity"
+)
+
+func newRecord(address string, nonce, blockNumber uint64, txIndex uint) *entity.TxRecord {
+	return &entity.TxRecord{
+		Address:     address,
+		Tx:          types.NewTx(&types.LegacyTx{Nonce: nonce}),
+		BlockNumber: blockNumber,
+		TxIndex:     txIndex,
+	}
+}
+
+func testAddress(i int) string {
+	return fmt.Sprintf("0x%040x", i)
+}
+
+func TestMemRepositoryConcurrentSaves(t *testing.T) {
+	const (
+		addresses = 64
+		txs       = 50
+	)
+	repo := NewMemRepository(0)
+
+	var wg sync.WaitGroup
+	for a := 0; a < addresses; a++ {
+		// two writers per address, saving the same records
+		for w := 0; w < 2; w++ {
+			wg.Add(1)
+			go func(address string) {
+				defer wg.Done()
+				for i := 0; i < txs; i++ {
+					if err := repo.SaveTx(newRecord(address, uint64(i), uint64(i), 0)); err != nil {
+						t.Error(err)
+						return
+					}
+				}
+			}(testAddress(a))
+		}
+	}
+	wg.Wait()
+
+	for a := 0; a < addresses; a++ {
+		records, err := repo.GetTxs(testAddress(a))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(records) != txs {
+			t.Fatalf("address %d: got %d records, want %d", a, len(records), txs)
+		}
+		for i, record := range records {
+			if record.BlockNumber != uint64(i) {
+				t.Fatalf("address %d: got block %d at %d", a, record.BlockNumber, i)
+			}
+		}
+	}
+}
+
+func TestMemRepositoryConcurrentReadsAndWrites(t *testing.T) {
+	const (
+		addresses = 16
+		blocks    = 100
+	)
+	repo := NewMemRepository(0)
+
+	var (
+		wg   sync.WaitGroup
+		done = make(chan struct{})
+	)
+	for a := 0; a < addresses; a++ {
+		wg.Add(1)
+		go func(address string) {
+			defer wg.Done()
+			for n := uint64(0); n < blocks; n++ {
+				if err := repo.SaveTx(newRecord(address, n, n, 0)); err != nil {
+					t.Error(err)
+					return
+				}
+			}
+		}(testAddress(a))
+	}
+
+	var readers sync.WaitGroup
+	readers.Add(1)
+	go func() {
+		defer readers.Done()
+		for {
+			select {
+			case <-done:
+				return
+			default:
+			}
+
+			address := testAddress(0)
+			records, err := repo.GetTxs(address)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			// readers own their copies
+			for _, record := range records {
+				record.BlockNumber = 0
+			}
+			if _, err := repo.QueryTxs(address, entity.TxQuery{Order: entity.SortOrderDesc, Limit: 10}); err != nil {
+				t.Error(err)
+				return
+			}
+			if _, err := repo.GetBlockTxs(blocks / 2); err != nil {
+				t.Error(err)
+				return
+			}
+		}
+	}()
+
+	readers.Add(1)
+	go func() {
+		defer readers.Done()
+		for {
+			select {
+			case <-done:
+				return
+			default:
+			}
+
+			if _, err := repo.DeleteBlockTxs(blocks - 1); err != nil {
+				t.Error(err)
+				return
+			}
+		}
+	}()
+
+	wg.Wait()
+	close(done)
+	readers.Wait()
+
+	// the last block may have been saved after the last delete
+	if _, err := repo.DeleteBlockTxs(blocks - 1); err != nil {
+		t.Fatal(err)
+	}
+	for a := 0; a < addresses; a++ {
+		records, err := repo.GetTxs(testAddress(a))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(records) != blocks-1 {
+			t.Fatalf("address %d: got %d records, want %d", a, len(records), blocks-1)
+		}
+		for i, record := range records {
+			if record.BlockNumber != uint64(i) {
+				t.Fatalf("address %d: got block %d at %d", a, record.BlockNumber, i)
+			}
+		}
+	}
+}
+
+func TestMemRepositoryEvictsOldestRecords(t *testing.T) {
+	repo := NewMemRepository(3)
+	address := testAddress(1)
+
+	for n := uint64(0); n < 5; n++ {
+		if err := repo.SaveTx(newRecord(address, n, n, 0)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	records, err := repo.GetTxs(address)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 3 || records[0].BlockNumber != 2 {
+		t.Fatalf("got %d records from block %d, want 3 from block 2", len(records), records[0].BlockNumber)
+	}
+
+	evicted := newRecord(address, 0, 0, 0)
+	byHash, err := repo.GetTxsByHash(evicted.Hash())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(byHash) != 0 {
+		t.Fatalf("got %d records of an evicted transaction", len(byHash))
+	}
+}
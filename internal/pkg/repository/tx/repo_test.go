@@ -0,0 +1,128 @@
+package tx
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/vuquang23/trustme/internal/pkg/entity"
+	"github.com/vuquang23/trustme/internal/pkg/repository/boltdb"
+)
+
+type repository interface {
+	SaveTx(record *entity.TxRecord) error
+	GetTxs(address string) ([]*entity.TxRecord, error)
+	GetTxsByHash(hash common.Hash) ([]*entity.TxRecord, error)
+	DeleteBlockTxs(blockNumber uint64) ([]*entity.TxRecord, error)
+}
+
+func backends(t *testing.T) map[string]repository {
+	t.Helper()
+
+	db, err := boltdb.Open(filepath.Join(t.TempDir(), "trustme.db"), nil, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = db.Close() })
+
+	return map[string]repository{
+		"memory": NewMemRepository(0),
+		"bolt":   NewBoltRepository(db),
+	}
+}
+
+func hashesOf(records []*entity.TxRecord) []common.Hash {
+	hashes := make([]common.Hash, 0, len(records))
+	for _, record := range records {
+		hashes = append(hashes, record.Hash())
+	}
+	return hashes
+}
+
+func TestRepositoriesSaveTx(t *testing.T) {
+	address := testAddress(1)
+	first := newRecord(address, 1, 10, 0)
+	second := newRecord(address, 2, 10, 0)
+	moved := newRecord(address, 1, 11, 3)
+
+	for _, tc := range []struct {
+		name    string
+		saves   []*entity.TxRecord
+		want    []common.Hash
+		removed []common.Hash
+	}{
+		{
+			name:  "saving again is idempotent",
+			saves: []*entity.TxRecord{first, first},
+			want:  []common.Hash{first.Hash()},
+		},
+		{
+			name:    "another transaction at the same position replaces it",
+			saves:   []*entity.TxRecord{first, second},
+			want:    []common.Hash{second.Hash()},
+			removed: []common.Hash{first.Hash()},
+		},
+		{
+			name:  "a replaced transaction can come back",
+			saves: []*entity.TxRecord{first, second, first},
+			want:  []common.Hash{first.Hash()},
+		},
+		{
+			name:  "a transaction saved at another position moves",
+			saves: []*entity.TxRecord{first, moved},
+			want:  []common.Hash{moved.Hash()},
+		},
+	} {
+		for backend, repo := range backends(t) {
+			t.Run(tc.name+"/"+backend, func(t *testing.T) {
+				for _, record := range tc.saves {
+					if err := repo.SaveTx(record); err != nil {
+						t.Fatal(err)
+					}
+				}
+
+				records, err := repo.GetTxs(address)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if got := hashesOf(records); len(got) != len(tc.want) || got[0] != tc.want[0] {
+					t.Fatalf("got %v, want %v", got, tc.want)
+				}
+
+				for _, hash := range tc.want {
+					byHash, err := repo.GetTxsByHash(hash)
+					if err != nil {
+						t.Fatal(err)
+					}
+					if got := hashesOf(byHash); len(got) != 1 || got[0] != hash {
+						t.Fatalf("got %v by hash %s", got, hash)
+					}
+				}
+				for _, hash := range tc.removed {
+					byHash, err := repo.GetTxsByHash(hash)
+					if err != nil {
+						t.Fatal(err)
+					}
+					if len(byHash) != 0 {
+						t.Fatalf("got %v by the replaced hash %s", hashesOf(byHash), hash)
+					}
+				}
+
+				last := tc.saves[len(tc.saves)-1]
+				deleted, err := repo.DeleteBlockTxs(last.BlockNumber)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if got := hashesOf(deleted); len(got) != 1 || got[0] != last.Hash() {
+					t.Fatalf("got %v deleted, want %s", got, last.Hash())
+				}
+				for _, hash := range append(tc.want, tc.removed...) {
+					if byHash, err := repo.GetTxsByHash(hash); err != nil || len(byHash) != 0 {
+						t.Fatalf("got %v by hash %s after the block was deleted, %v", hashesOf(byHash), hash, err)
+					}
+				}
+			})
+		}
+	}
+}
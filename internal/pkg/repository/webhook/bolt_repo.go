@@ -0,0 +1,361 @@
+package webhook
+
+import (
+	"bytes"
+	"encoding/binary"
+	"time"
+
+	"github.com/ethereum/go-ethereum/rlp"
+	bolt "go.etcd.io/bbolt"
+
+	"github.com/vuquang23/trustme/internal/pkg/entity"
+	"github.com/vuquang23/trustme/internal/pkg/repository/boltdb"
+)
+
+// storedWebhook is the RLP layout of a webhook, keyed by its ID.
+type storedWebhook struct {
+	Address   string
+	URL       string
+	Secret    string
+	Events    []string
+	CreatedAt uint64
+	Tenant    string `rlp:"optional"`
+}
+
+// storedDelivery is the RLP layout of a delivery, keyed by its ID. Times are unix nanoseconds.
+type storedDelivery struct {
+	WebhookID     string
+	Event         string
+	Address       string
+	Payload       []byte
+	Status        string
+	Attempts      []storedAttempt
+	Failures      uint64
+	NextAttemptAt uint64
+	CreatedAt     uint64
+	Tenant        string `rlp:"optional"`
+}
+
+type storedAttempt struct {
+	At         uint64
+	StatusCode uint64
+	Error      string
+	Duration   uint64
+}
+
+// BoltRepository stores webhooks and deliveries by ID, with two indexes:
+//   - webhookAddresses: address + webhook ID, to find the webhooks of an address.
+//   - webhookOutbox: next attempt time + delivery ID of the pending deliveries, to find the due ones.
+type BoltRepository struct {
+	db *bolt.DB
+}
+
+func NewBoltRepository(db *bolt.DB) *BoltRepository {
+	return &BoltRepository{
+		db: db,
+	}
+}
+
+func (w *BoltRepository) CreateWebhook(webhook *entity.Webhook) error {
+	events := make([]string, 0, len(webhook.Events))
+	for _, event := range webhook.Events {
+		events = append(events, string(event))
+	}
+
+	value, err := rlp.EncodeToBytes(storedWebhook{
+		Address:   webhook.Address,
+		URL:       webhook.URL,
+		Secret:    webhook.Secret,
+		Events:    events,
+		CreatedAt: uint64(webhook.CreatedAt.Unix()),
+		Tenant:    webhook.Tenant,
+	})
+	if err != nil {
+		return err
+	}
+
+	return w.db.Update(func(tx *bolt.Tx) error {
+		if err := tx.Bucket(boltdb.BucketWebhooks).Put([]byte(webhook.ID), value); err != nil {
+			return err
+		}
+		return tx.Bucket(boltdb.BucketWebhookAddresses).Put([]byte(webhook.Address+webhook.ID), nil)
+	})
+}
+
+func (w *BoltRepository) GetWebhook(id string) (*entity.Webhook, error) {
+	var webhook *entity.Webhook
+	err := w.db.View(func(tx *bolt.Tx) error {
+		v := tx.Bucket(boltdb.BucketWebhooks).Get([]byte(id))
+		if v == nil {
+			return nil
+		}
+
+		var err error
+		webhook, err = decodeWebhook([]byte(id), v)
+		return err
+	})
+	return webhook, err
+}
+
+func (w *BoltRepository) DeleteWebhook(id string) error {
+	return w.db.Update(func(tx *bolt.Tx) error {
+		webhooks := tx.Bucket(boltdb.BucketWebhooks)
+		v := webhooks.Get([]byte(id))
+		if v == nil {
+			return nil
+		}
+
+		webhook, err := decodeWebhook([]byte(id), v)
+		if err != nil {
+			return err
+		}
+		if err := tx.Bucket(boltdb.BucketWebhookAddresses).Delete([]byte(webhook.Address + id)); err != nil {
+			return err
+		}
+		return webhooks.Delete([]byte(id))
+	})
+}
+
+func (w *BoltRepository) ListWebhooks(address string) ([]*entity.Webhook, error) {
+	webhooks := []*entity.Webhook{}
+	err := w.db.View(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(boltdb.BucketWebhooks)
+
+		if address == "" {
+			return bucket.ForEach(func(k, v []byte) error {
+				webhook, err := decodeWebhook(k, v)
+				if err != nil {
+					return err
+				}
+				webhooks = append(webhooks, webhook)
+				return nil
+			})
+		}
+
+		prefix := []byte(address)
+		c := tx.Bucket(boltdb.BucketWebhookAddresses).Cursor()
+		for k, _ := c.Seek(prefix); k != nil && bytes.HasPrefix(k, prefix); k, _ = c.Next() {
+			id := k[len(prefix):]
+			v := bucket.Get(id)
+			if v == nil {
+				continue
+			}
+
+			webhook, err := decodeWebhook(id, v)
+			if err != nil {
+				return err
+			}
+			webhooks = append(webhooks, webhook)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return webhooks, nil
+}
+
+func (w *BoltRepository) SaveDelivery(delivery *entity.WebhookDelivery) error {
+	attempts := make([]storedAttempt, 0, len(delivery.Attempts))
+	for _, attempt := range delivery.Attempts {
+		attempts = append(attempts, storedAttempt{
+			At:         uint64(attempt.At.UnixNano()),
+			StatusCode: uint64(attempt.StatusCode),
+			Error:      attempt.Error,
+			Duration:   uint64(attempt.Duration),
+		})
+	}
+
+	value, err := rlp.EncodeToBytes(storedDelivery{
+		WebhookID:     delivery.WebhookID,
+		Event:         string(delivery.Event),
+		Address:       delivery.Address,
+		Payload:       delivery.Payload,
+		Status:        string(delivery.Status),
+		Attempts:      attempts,
+		Failures:      uint64(delivery.Failures),
+		NextAttemptAt: uint64(delivery.NextAttemptAt.UnixNano()),
+		CreatedAt:     uint64(delivery.CreatedAt.UnixNano()),
+		Tenant:        delivery.Tenant,
+	})
+	if err != nil {
+		return err
+	}
+
+	id := []byte(delivery.ID)
+
+	return w.db.Update(func(tx *bolt.Tx) error {
+		deliveries := tx.Bucket(boltdb.BucketWebhookDeliveries)
+		outbox := tx.Bucket(boltdb.BucketWebhookOutbox)
+
+		if v := deliveries.Get(id); v != nil {
+			old, err := decodeDelivery(id, v)
+			if err != nil {
+				return err
+			}
+			if old.Status == entity.DeliveryStatusPending {
+				if err := outbox.Delete(outboxKey(old)); err != nil {
+					return err
+				}
+			}
+		}
+
+		if err := deliveries.Put(id, value); err != nil {
+			return err
+		}
+		if delivery.Status == entity.DeliveryStatusPending {
+			return outbox.Put(outboxKey(delivery), nil)
+		}
+		return nil
+	})
+}
+
+func (w *BoltRepository) GetDelivery(id string) (*entity.WebhookDelivery, error) {
+	var delivery *entity.WebhookDelivery
+	err := w.db.View(func(tx *bolt.Tx) error {
+		v := tx.Bucket(boltdb.BucketWebhookDeliveries).Get([]byte(id))
+		if v == nil {
+			return nil
+		}
+
+		var err error
+		delivery, err = decodeDelivery([]byte(id), v)
+		return err
+	})
+	return delivery, err
+}
+
+// ListDeliveries walks the deliveries backwards, IDs being ordered by creation time.
+func (w *BoltRepository) ListDeliveries(query entity.DeliveryQuery) (*entity.DeliveryPage, error) {
+	page := &entity.DeliveryPage{Deliveries: []*entity.WebhookDelivery{}}
+	err := w.db.View(func(tx *bolt.Tx) error {
+		c := tx.Bucket(boltdb.BucketWebhookDeliveries).Cursor()
+
+		var k, v []byte
+		if query.Cursor == "" {
+			k, v = c.Last()
+		} else if k, _ = c.Seek([]byte(query.Cursor)); k == nil {
+			k, v = c.Last()
+		} else {
+			k, v = c.Prev()
+		}
+
+		for ; k != nil; k, v = c.Prev() {
+			delivery, err := decodeDelivery(k, v)
+			if err != nil {
+				return err
+			}
+			if !query.Match(delivery) {
+				continue
+			}
+
+			if query.Limit > 0 && len(page.Deliveries) == query.Limit {
+				page.NextCursor = page.Deliveries[len(page.Deliveries)-1].ID
+				break
+			}
+			page.Deliveries = append(page.Deliveries, delivery)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return page, nil
+}
+
+func (w *BoltRepository) DueDeliveries(now time.Time, limit int) ([]*entity.WebhookDelivery, error) {
+	deliveries := []*entity.WebhookDelivery{}
+	err := w.db.View(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(boltdb.BucketWebhookDeliveries)
+
+		c := tx.Bucket(boltdb.BucketWebhookOutbox).Cursor()
+		for k, _ := c.First(); k != nil; k, _ = c.Next() {
+			if binary.BigEndian.Uint64(k[:8]) > uint64(now.UnixNano()) {
+				break
+			}
+			if limit > 0 && len(deliveries) == limit {
+				break
+			}
+
+			id := k[8:]
+			v := bucket.Get(id)
+			if v == nil {
+				continue
+			}
+
+			delivery, err := decodeDelivery(id, v)
+			if err != nil {
+				return err
+			}
+			deliveries = append(deliveries, delivery)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return deliveries, nil
+}
+
+// outboxKey orders the pending deliveries by next attempt time.
+func outboxKey(delivery *entity.WebhookDelivery) []byte {
+	key := make([]byte, 8, 8+len(delivery.ID))
+	binary.BigEndian.PutUint64(key, uint64(delivery.NextAttemptAt.UnixNano()))
+	return append(key, delivery.ID...)
+}
+
+func decodeWebhook(key, value []byte) (*entity.Webhook, error) {
+	var stored storedWebhook
+	if err := rlp.DecodeBytes(value, &stored); err != nil {
+		return nil, err
+	}
+
+	var events []entity.EventType
+	for _, event := range stored.Events {
+		events = append(events, entity.EventType(event))
+	}
+
+	return &entity.Webhook{
+		ID:        string(key),
+		Address:   stored.Address,
+		URL:       stored.URL,
+		Secret:    stored.Secret,
+		Events:    events,
+		Tenant:    stored.Tenant,
+		CreatedAt: time.Unix(int64(stored.CreatedAt), 0).UTC(),
+	}, nil
+}
+
+func decodeDelivery(key, value []byte) (*entity.WebhookDelivery, error) {
+	var stored storedDelivery
+	if err := rlp.DecodeBytes(value, &stored); err != nil {
+		return nil, err
+	}
+
+	attempts := make([]entity.DeliveryAttempt, 0, len(stored.Attempts))
+	for _, attempt := range stored.Attempts {
+		attempts = append(attempts, entity.DeliveryAttempt{
+			At:         time.Unix(0, int64(attempt.At)).UTC(),
+			StatusCode: int(attempt.StatusCode),
+			Error:      attempt.Error,
+			Duration:   time.Duration(attempt.Duration),
+		})
+	}
+
+	return &entity.WebhookDelivery{
+		ID:            string(key),
+		WebhookID:     stored.WebhookID,
+		Event:         entity.EventType(stored.Event),
+		Address:       stored.Address,
+		Payload:       stored.Payload,
+		Status:        entity.DeliveryStatus(stored.Status),
+		Attempts:      attempts,
+		Failures:      int(stored.Failures),
+		NextAttemptAt: time.Unix(0, int64(stored.NextAttemptAt)).UTC(),
+		Tenant:        stored.Tenant,
+		CreatedAt:     time.Unix(0, int64(stored.CreatedAt)).UTC(),
+	}, nil
+}
@@ -0,0 +1,145 @@
+package webhook
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/vuquang23/trustme/internal/pkg/entity"
+)
+
+type MemRepository struct {
+	mu         sync.RWMutex
+	webhooks   map[string]*entity.Webhook
+	deliveries map[string]*entity.WebhookDelivery
+}
+
+func NewMemRepository() *MemRepository {
+	return &MemRepository{
+		webhooks:   make(map[string]*entity.Webhook),
+		deliveries: make(map[string]*entity.WebhookDelivery),
+	}
+}
+
+func (w *MemRepository) CreateWebhook(webhook *entity.Webhook) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	stored := *webhook
+	w.webhooks[webhook.ID] = &stored
+	return nil
+}
+
+func (w *MemRepository) GetWebhook(id string) (*entity.Webhook, error) {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+
+	stored, ok := w.webhooks[id]
+	if !ok {
+		return nil, nil
+	}
+	webhook := *stored
+	return &webhook, nil
+}
+
+func (w *MemRepository) DeleteWebhook(id string) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	delete(w.webhooks, id)
+	return nil
+}
+
+func (w *MemRepository) ListWebhooks(address string) ([]*entity.Webhook, error) {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+
+	webhooks := []*entity.Webhook{}
+	for _, stored := range w.webhooks {
+		if address != "" && stored.Address != address {
+			continue
+		}
+		webhook := *stored
+		webhooks = append(webhooks, &webhook)
+	}
+
+	sort.Slice(webhooks, func(i, j int) bool { return webhooks[i].ID < webhooks[j].ID })
+	return webhooks, nil
+}
+
+func (w *MemRepository) SaveDelivery(delivery *entity.WebhookDelivery) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.deliveries[delivery.ID] = copyDelivery(delivery)
+	return nil
+}
+
+func (w *MemRepository) GetDelivery(id string) (*entity.WebhookDelivery, error) {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+
+	stored, ok := w.deliveries[id]
+	if !ok {
+		return nil, nil
+	}
+	return copyDelivery(stored), nil
+}
+
+func (w *MemRepository) ListDeliveries(query entity.DeliveryQuery) (*entity.DeliveryPage, error) {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+
+	var deliveries []*entity.WebhookDelivery
+	for _, stored := range w.deliveries {
+		if query.Cursor != "" && stored.ID >= query.Cursor {
+			continue
+		}
+		if query.Match(stored) {
+			deliveries = append(deliveries, stored)
+		}
+	}
+	sort.Slice(deliveries, func(i, j int) bool { return deliveries[i].ID > deliveries[j].ID })
+
+	page := &entity.DeliveryPage{Deliveries: []*entity.WebhookDelivery{}}
+	for _, delivery := range deliveries {
+		if query.Limit > 0 && len(page.Deliveries) == query.Limit {
+			page.NextCursor = page.Deliveries[len(page.Deliveries)-1].ID
+			break
+		}
+		page.Deliveries = append(page.Deliveries, copyDelivery(delivery))
+	}
+	return page, nil
+}
+
+func (w *MemRepository) DueDeliveries(now time.Time, limit int) ([]*entity.WebhookDelivery, error) {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+
+	due := []*entity.WebhookDelivery{}
+	for _, stored := range w.deliveries {
+		if stored.Status == entity.DeliveryStatusPending && !stored.NextAttemptAt.After(now) {
+			due = append(due, stored)
+		}
+	}
+	sort.Slice(due, func(i, j int) bool {
+		if !due[i].NextAttemptAt.Equal(due[j].NextAttemptAt) {
+			return due[i].NextAttemptAt.Before(due[j].NextAttemptAt)
+		}
+		return due[i].ID < due[j].ID
+	})
+
+	if limit > 0 && len(due) > limit {
+		due = due[:limit]
+	}
+	for i, delivery := range due {
+		due[i] = copyDelivery(delivery)
+	}
+	return due, nil
+}
+
+func copyDelivery(delivery *entity.WebhookDelivery) *entity.WebhookDelivery {
+	c := *delivery
+	c.Attempts = append([]entity.DeliveryAttempt(nil), delivery.Attempts...)
+	return &c
+}
@@ -3,4 +3,10 @@ package server
 type Config struct {
 	BindAddress string
 	Mode        string
+	// AllowOrigins are the origins allowed by CORS, * allowing all of them. Without any, browsers are only served
+	// same-origin requests.
+	AllowOrigins []string
+	// TrustedProxies are the IPs or CIDRs of the reverse proxies whose X-Forwarded-For header gives the client
+	// IP. Without any, the client IP is the remote address of the connection.
+	TrustedProxies []string
 }
@@ -3,10 +3,14 @@ package server
 import (
 	"context"
 	"net/http"
+	"slices"
 
 	"github.com/gin-contrib/cors"
 	"github.com/gin-contrib/requestid"
 	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 
 	"github.com/vuquang23/trustme/internal/pkg/server/middleware"
 	requestidpkg "github.com/vuquang23/trustme/internal/pkg/util/requestid"
@@ -29,31 +33,61 @@ func Run(ctx context.Context, address string, engine *gin.Engine) error {
 	return srv.Shutdown(ctx)
 }
 
-func GinEngine(config Config, logCfg logger.Config, logBackend logger.LoggerBackend) *gin.Engine {
+func GinEngine(config Config, logCfg logger.Config, logBackend logger.LoggerBackend) (*gin.Engine, error) {
 	gin.SetMode(config.Mode)
 
 	middlewares := []gin.HandlerFunc{
+		// a span per request, continuing the trace of the traceparent header
+		otelgin.Middleware(tracerServerName, otelgin.WithFilter(traced)),
 		requestid.New(
 			requestid.WithCustomHeaderStrKey(requestidpkg.HeaderKeyRequestID),
 			requestid.WithHandler(func(c *gin.Context, requestID string) {
+				trace.SpanFromContext(c.Request.Context()).SetAttributes(attribute.String("request.id", requestID))
 				c.Request = c.Request.WithContext(requestidpkg.SetRequestIDToContext(c.Request.Context(), requestID))
 			}),
 		),
 		middleware.NewLoggerMiddleware(logCfg, logBackend),
+		middleware.NewMetricsMiddleware(),
 		gin.Recovery(),
 	}
 
 	engine := gin.New()
+	// Let the gin context resolve the values of the request context, like its span, for the logger.
+	engine.ContextWithFallback = true
+	// The client IP keys the rate limits, only the configured proxies may set it.
+	if err := engine.SetTrustedProxies(config.TrustedProxies); err != nil {
+		return nil, err
+	}
 	engine.Use(middlewares...)
 
-	setCORS(engine)
+	setCORS(engine, config.AllowOrigins)
+
+	return engine, nil
+}
+
+const tracerServerName = "trustme"
 
-	return engine
+// traced leaves the probes and the metrics scrapes out of the traces.
+func traced(r *http.Request) bool {
+	switch r.URL.Path {
+	case "/healthz", "/readyz", "/metrics":
+		return false
+	}
+	return true
 }
 
-func setCORS(engine *gin.Engine) {
+func setCORS(engine *gin.Engine, allowOrigins []string) {
 	corsConfig := cors.DefaultConfig()
 	corsConfig.AddAllowMethods(http.MethodOptions)
-	corsConfig.AllowAllOrigins = true
+	corsConfig.AddAllowHeaders("Authorization", "X-API-Key")
+	switch {
+	case slices.Contains(allowOrigins, "*"):
+		corsConfig.AllowAllOrigins = true
+	case len(allowOrigins) == 0:
+		// Only the same origin, the cross-origin requests and WebSocket upgrades are refused.
+		corsConfig.AllowOriginFunc = func(string) bool { return false }
+	default:
+		corsConfig.AllowOrigins = allowOrigins
+	}
 	engine.Use(cors.New(corsConfig))
 }
@@ -0,0 +1,76 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/vuquang23/trustme/internal/pkg/tracing"
+	"github.com/vuquang23/trustme/pkg/logger"
+)
+
+func TestGinEngineTracesRequests(t *testing.T) {
+	if _, err := tracing.Init(context.Background(), tracing.Config{}); err != nil {
+		t.Fatal(err)
+	}
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	otel.SetTracerProvider(provider)
+	defer func() { _ = provider.Shutdown(context.Background()) }()
+
+	engine, err := GinEngine(Config{Mode: gin.TestMode}, logger.Config{}, logger.LoggerBackendZap)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var handled trace.SpanContext
+	engine.GET("/api/txs", func(c *gin.Context) {
+		// the gin context falls back to the request one, the logger finds the span there
+		handled = trace.SpanContextFromContext(c)
+		c.Status(http.StatusNoContent)
+	})
+	engine.GET("/healthz", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	const (
+		traceID = "4bf92f3577b34da6a3ce929d0e0e4736"
+		spanID  = "00f067aa0ba902b7"
+	)
+	req := httptest.NewRequest(http.MethodGet, "/api/txs", nil)
+	req.Header.Set("traceparent", "00-"+traceID+"-"+spanID+"-01")
+	req.Header.Set("X-Request-ID", "req-1")
+	engine.ServeHTTP(httptest.NewRecorder(), req)
+	engine.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/healthz", nil))
+
+	spans := recorder.Ended()
+	if len(spans) != 1 {
+		t.Fatalf("got %d spans, want the one of /api/txs", len(spans))
+	}
+	span := spans[0]
+	if span.Name() != "/api/txs" || span.SpanKind() != trace.SpanKindServer {
+		t.Fatalf("got span %s of kind %s", span.Name(), span.SpanKind())
+	}
+	if span.SpanContext().TraceID().String() != traceID || span.Parent().SpanID().String() != spanID {
+		t.Fatalf("got trace %s and parent %s, want the ones of the traceparent", span.SpanContext().TraceID(), span.Parent().SpanID())
+	}
+	if handled.SpanID() != span.SpanContext().SpanID() {
+		t.Fatalf("got span %s in the handler, want %s", handled.SpanID(), span.SpanContext().SpanID())
+	}
+
+	var requestID attribute.Value
+	for _, attr := range span.Attributes() {
+		if attr.Key == "request.id" {
+			requestID = attr.Value
+		}
+	}
+	if requestID.AsString() != "req-1" {
+		t.Fatalf("got request id %q, want req-1", requestID.AsString())
+	}
+}
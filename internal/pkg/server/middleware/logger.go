@@ -2,7 +2,9 @@ package middleware
 
 import (
 	"bytes"
+	"fmt"
 	"io"
+	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
@@ -11,6 +13,20 @@ import (
 	"github.com/vuquang23/trustme/pkg/logger"
 )
 
+// redactedBody replaces the bodies of the routes carrying credentials.
+const redactedBody = "[redacted]"
+
+// maxLoggedBody is the number of bytes of a body that are logged, the rest is cut off. Bulk routes, like the
+// batch subscribe, take up to megabytes of addresses.
+const maxLoggedBody = 4 << 10
+
+// credentialRoutes carry credentials in their request or response bodies, which are never logged:
+// the generated API keys and the webhook secrets.
+var credentialRoutes = map[string]bool{
+	"POST /admin/keys":   true,
+	"POST /api/webhooks": true,
+}
+
 func NewLoggerMiddleware(logCfg logger.Config, logBackend logger.LoggerBackend) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		startTime := time.Now()
@@ -25,45 +41,79 @@ func NewLoggerMiddleware(logCfg logger.Config, logBackend logger.LoggerBackend)
 			"request.id": requestID,
 		}
 
+		redact := credentialRoutes[c.Request.Method+" "+c.FullPath()]
+		if redact {
+			reqBody = []byte(redactedBody)
+		}
+
 		reqLogger := logger.WithFieldsNonContext(commonFields)
 		c.Set(string(logger.CtxLoggerKey), reqLogger)
 
-		reqLogger.WithFields(logger.Fields{
+		spanLogger := logger.WithSpan(c.Request.Context(), reqLogger)
+		spanLogger.WithFields(logger.Fields{
 			"request.method":     c.Request.Method,
 			"request.uri":        c.Request.URL.RequestURI(),
-			"request.body":       string(reqBody),
+			"request.body":       truncateBody(reqBody, len(reqBody)),
 			"request.client_ip":  c.ClientIP(),
 			"request.user_agent": c.Request.UserAgent(),
 		}).Info("inbound request")
 
-		blw := &bodyLogWriter{body: bytes.NewBufferString(""), ResponseWriter: c.Writer}
+		blw := &bodyLogWriter{body: bytes.NewBufferString(""), size: new(int), ResponseWriter: c.Writer}
 		c.Writer = blw
 
 		c.Next()
 
-		resp, _ := io.ReadAll(blw.body)
+		resp, size := blw.body.Bytes(), *blw.size
+		if redact {
+			resp, size = []byte(redactedBody), len(redactedBody)
+		}
 
-		reqLogger.WithFields(
+		spanLogger.WithFields(
 			logger.Fields{
 				"response.status":      blw.Status(),
-				"response.body":        string(resp),
+				"response.body":        truncateBody(resp, size),
 				"response.duration_ms": time.Since(startTime).Milliseconds(),
 			}).
 			Info("inbound response")
 	}
 }
 
+// truncateBody returns the first maxLoggedBody bytes of a body of size bytes.
+func truncateBody(body []byte, size int) string {
+	if size <= maxLoggedBody {
+		return string(body)
+	}
+	return fmt.Sprintf("%s...(%d bytes)", body[:maxLoggedBody], size)
+}
+
+// bodyLogWriter keeps the first maxLoggedBody bytes of the response and counts all of them.
 type bodyLogWriter struct {
 	gin.ResponseWriter
 	body *bytes.Buffer
+	size *int
 }
 
 func (w bodyLogWriter) Write(b []byte) (int, error) {
-	w.body.Write(b)
+	w.keep(b)
 	return w.ResponseWriter.Write(b)
 }
 
 func (w bodyLogWriter) WriteString(s string) (int, error) {
-	w.body.WriteString(s)
+	w.keep([]byte(s))
 	return w.ResponseWriter.WriteString(s)
 }
+
+func (w bodyLogWriter) keep(b []byte) {
+	if w.isStream() {
+		return
+	}
+	*w.size += len(b)
+	if n := maxLoggedBody - w.body.Len(); n > 0 {
+		w.body.Write(b[:min(n, len(b))])
+	}
+}
+
+// isStream tells whether the response is a long-lived event stream, whose body is not kept for logging.
+func (w bodyLogWriter) isStream() bool {
+	return strings.HasPrefix(w.Header().Get("Content-Type"), "text/event-stream")
+}
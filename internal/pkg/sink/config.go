@@ -0,0 +1,39 @@
+package sink
+
+import "time"
+
+const (
+	DriverNATS  = "nats"
+	DriverKafka = "kafka"
+)
+
+type Config struct {
+	// Driver is the message broker events are published to, empty disables publishing.
+	Driver string
+	// BatchSize is the number of outbox messages published at once.
+	BatchSize int `default:"100"`
+	// PollInterval is how often the outbox is checked for messages.
+	PollInterval time.Duration `default:"1s"`
+	// RetryBackoff is the delay before publishing again after a failure.
+	RetryBackoff time.Duration `default:"5s"`
+
+	NATS  NATSConfig
+	Kafka KafkaConfig
+}
+
+type NATSConfig struct {
+	URL string `default:"nats://127.0.0.1:4222"`
+	// SubjectPrefix is prepended to the event type to build the subject, e.g. trustme.tx.
+	SubjectPrefix string `default:"trustme"`
+	// Stream is the JetStream stream created for the subjects when it does not exist. Empty expects an
+	// existing stream to cover them.
+	Stream  string        `default:"TRUSTME"`
+	Timeout time.Duration `default:"5s"`
+}
+
+type KafkaConfig struct {
+	Brokers []string `default:"[127.0.0.1:9092]"`
+	// TopicPrefix is prepended to the event type to build the topic, e.g. trustme.tx.
+	TopicPrefix string        `default:"trustme"`
+	Timeout     time.Duration `default:"10s"`
+}
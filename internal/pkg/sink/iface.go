@@ -0,0 +1,22 @@
+package sink
+
+import (
+	"context"
+
+	"github.com/vuquang23/trustme/internal/pkg/entity"
+)
+
+// ISink publishes outbox messages to a message broker.
+type ISink interface {
+	// publish the messages in order, returning once the broker has accepted all of them
+	Send(ctx context.Context, messages []*entity.OutboxMessage) error
+	Close() error
+}
+
+type IOutboxRepository interface {
+	// appends a message, assigning its Seq
+	Append(message *entity.OutboxMessage) error
+	// oldest messages first
+	Pending(limit int) ([]*entity.OutboxMessage, error)
+	Delete(messages []*entity.OutboxMessage) error
+}
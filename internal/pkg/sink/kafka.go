@@ -0,0 +1,57 @@
+package sink
+
+import (
+	"context"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+
+	"github.com/vuquang23/trustme/internal/pkg/entity"
+)
+
+// KafkaSink publishes each event type on its own topic, keyed so that the messages of an address
+// land on the same partition and keep their order.
+type KafkaSink struct {
+	cfg    KafkaConfig
+	writer *kafka.Writer
+}
+
+func NewKafkaSink(cfg KafkaConfig) *KafkaSink {
+	return &KafkaSink{
+		cfg: cfg,
+		writer: &kafka.Writer{
+			Addr:                   kafka.TCP(cfg.Brokers...),
+			Balancer:               &kafka.Hash{},
+			RequiredAcks:           kafka.RequireAll,
+			AllowAutoTopicCreation: true,
+			WriteTimeout:           cfg.Timeout,
+			// Send waits for its messages to be written, do not hold them waiting for a fuller batch.
+			BatchTimeout: 10 * time.Millisecond,
+			// A batch is sent at once by Send, keep one in flight to preserve the order on retries.
+			MaxAttempts: 1,
+		},
+	}
+}
+
+func (s *KafkaSink) Send(ctx context.Context, messages []*entity.OutboxMessage) error {
+	msgs := make([]kafka.Message, 0, len(messages))
+	for _, message := range messages {
+		msgs = append(msgs, kafka.Message{
+			Topic: s.cfg.TopicPrefix + "." + string(message.Event),
+			Key:   []byte(message.Key),
+			Value: message.Payload,
+			Headers: []kafka.Header{
+				{Key: HeaderEvent, Value: []byte(message.Event)},
+				{Key: HeaderID, Value: []byte(message.ID)},
+			},
+		})
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, s.cfg.Timeout)
+	defer cancel()
+	return s.writer.WriteMessages(ctx, msgs...)
+}
+
+func (s *KafkaSink) Close() error {
+	return s.writer.Close()
+}
@@ -0,0 +1,72 @@
+package sink
+
+import (
+	"context"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+)
+
+// kafkaBrokers returns the brokers of the local Kafka the tests run against, the tests are skipped without one:
+//
+//	TRUSTME_KAFKA_BROKERS=127.0.0.1:9092 go test ./internal/pkg/sink/
+func kafkaBrokers(t *testing.T) []string {
+	t.Helper()
+
+	brokers := os.Getenv("TRUSTME_KAFKA_BROKERS")
+	if brokers == "" {
+		t.Skip("TRUSTME_KAFKA_BROKERS is not set")
+	}
+	return strings.Split(brokers, ",")
+}
+
+func TestKafkaSinkSend(t *testing.T) {
+	brokers := kafkaBrokers(t)
+	prefix := "trustme-test-" + strconv.FormatInt(time.Now().UnixNano(), 10)
+
+	s := NewKafkaSink(KafkaConfig{Brokers: brokers, TopicPrefix: prefix, Timeout: 10 * time.Second})
+	t.Cleanup(func() { _ = s.Close() })
+
+	messages := outboxMessages()[:1]
+	// the topic is created by the first write, which may fail while its leader is elected
+	var err error
+	for i := 0; i < 10; i++ {
+		if err = s.Send(context.Background(), messages); err == nil {
+			break
+		}
+		time.Sleep(time.Second)
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	reader := kafka.NewReader(kafka.ReaderConfig{
+		Brokers:     brokers,
+		Topic:       prefix + "." + string(messages[0].Event),
+		GroupID:     prefix,
+		StartOffset: kafka.FirstOffset,
+	})
+	t.Cleanup(func() { _ = reader.Close() })
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	msg, err := reader.ReadMessage(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(msg.Key) != messages[0].Key || string(msg.Value) != string(messages[0].Payload) {
+		t.Fatalf("got key %s value %s", msg.Key, msg.Value)
+	}
+	headers := make(map[string]string)
+	for _, header := range msg.Headers {
+		headers[header.Key] = string(header.Value)
+	}
+	if headers[HeaderID] != messages[0].ID || headers[HeaderEvent] != string(messages[0].Event) {
+		t.Fatalf("unexpected headers %v", headers)
+	}
+}
@@ -0,0 +1,103 @@
+package sink
+
+import (
+	"context"
+	"errors"
+
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+
+	"github.com/vuquang23/trustme/internal/pkg/entity"
+)
+
+const (
+	HeaderID    = "Trustme-Id"
+	HeaderKey   = "Trustme-Key"
+	HeaderEvent = "Trustme-Event"
+)
+
+// NATSSink publishes each event type on its own subject of a JetStream stream. The message ID is set as
+// Nats-Msg-Id so the stream drops the duplicates of a redelivery.
+type NATSSink struct {
+	cfg  NATSConfig
+	conn *nats.Conn
+	js   jetstream.JetStream
+}
+
+func NewNATSSink(cfg NATSConfig) (*NATSSink, error) {
+	conn, err := nats.Connect(cfg.URL, nats.Name("trustme"), nats.Timeout(cfg.Timeout), nats.MaxReconnects(-1))
+	if err != nil {
+		return nil, err
+	}
+
+	js, err := jetstream.New(conn)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	s := &NATSSink{
+		cfg:  cfg,
+		conn: conn,
+		js:   js,
+	}
+	if err := s.ensureStream(); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return s, nil
+}
+
+// ensureStream creates the stream of the subjects when it does not exist, an existing one is left as is.
+func (s *NATSSink) ensureStream() error {
+	if s.cfg.Stream == "" {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), s.cfg.Timeout)
+	defer cancel()
+
+	_, err := s.js.Stream(ctx, s.cfg.Stream)
+	if !errors.Is(err, jetstream.ErrStreamNotFound) {
+		return err
+	}
+	_, err = s.js.CreateStream(ctx, jetstream.StreamConfig{
+		Name:     s.cfg.Stream,
+		Subjects: []string{s.cfg.SubjectPrefix + ".>"},
+	})
+	return err
+}
+
+// Send publishes the messages asynchronously, in order, and returns once the stream acknowledged all of them.
+func (s *NATSSink) Send(ctx context.Context, messages []*entity.OutboxMessage) error {
+	acks := make([]jetstream.PubAckFuture, 0, len(messages))
+	for _, message := range messages {
+		msg := nats.NewMsg(s.cfg.SubjectPrefix + "." + string(message.Event))
+		msg.Data = message.Payload
+		msg.Header.Set(HeaderKey, message.Key)
+		msg.Header.Set(HeaderEvent, string(message.Event))
+
+		ack, err := s.js.PublishMsgAsync(msg, jetstream.WithMsgID(message.ID))
+		if err != nil {
+			return err
+		}
+		acks = append(acks, ack)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, s.cfg.Timeout)
+	defer cancel()
+	for _, ack := range acks {
+		select {
+		case <-ack.Ok():
+		case err := <-ack.Err():
+			return err
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	return nil
+}
+
+func (s *NATSSink) Close() error {
+	return s.conn.Drain()
+}
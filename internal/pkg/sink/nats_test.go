@@ -0,0 +1,149 @@
+package sink
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/nats-io/nats-server/v2/server"
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+
+	"github.com/vuquang23/trustme/internal/pkg/entity"
+)
+
+// runNATSServer starts an embedded NATS server with JetStream enabled.
+func runNATSServer(t *testing.T) *server.Server {
+	t.Helper()
+
+	srv, err := server.NewServer(&server.Options{
+		Host:      "127.0.0.1",
+		Port:      server.RANDOM_PORT,
+		JetStream: true,
+		StoreDir:  t.TempDir(),
+		NoLog:     true,
+		NoSigs:    true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	go srv.Start()
+	if !srv.ReadyForConnections(5 * time.Second) {
+		t.Fatal("nats server not ready")
+	}
+	t.Cleanup(srv.Shutdown)
+	return srv
+}
+
+func newTestNATSSink(t *testing.T, srv *server.Server, stream string) *NATSSink {
+	t.Helper()
+
+	s, err := NewNATSSink(NATSConfig{
+		URL:           srv.ClientURL(),
+		SubjectPrefix: "trustme",
+		Stream:        stream,
+		Timeout:       5 * time.Second,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = s.Close() })
+	return s
+}
+
+func outboxMessages() []*entity.OutboxMessage {
+	return []*entity.OutboxMessage{
+		{ID: "m1", Event: entity.EventTypeTx, Key: "0xa", Payload: []byte(`{"id":"m1"}`)},
+		{ID: "m2", Event: entity.EventTypeBlock, Key: "7", Payload: []byte(`{"id":"m2"}`)},
+		{ID: "m3", Event: entity.EventTypeReorg, Key: "0xa", Payload: []byte(`{"id":"m3"}`)},
+	}
+}
+
+// fetchStream reads every message of the stream, in order.
+func fetchStream(t *testing.T, s *NATSSink, stream string, n int) []jetstream.Msg {
+	t.Helper()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	consumer, err := s.js.OrderedConsumer(ctx, stream, jetstream.OrderedConsumerConfig{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	batch, err := consumer.Fetch(n, jetstream.FetchMaxWait(2*time.Second))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var msgs []jetstream.Msg
+	for msg := range batch.Messages() {
+		msgs = append(msgs, msg)
+	}
+	if err := batch.Error(); err != nil {
+		t.Fatal(err)
+	}
+	return msgs
+}
+
+func TestNATSSinkSendWaitsForTheStream(t *testing.T) {
+	srv := runNATSServer(t)
+	s := newTestNATSSink(t, srv, "TRUSTME")
+
+	messages := outboxMessages()
+	if err := s.Send(context.Background(), messages); err != nil {
+		t.Fatal(err)
+	}
+
+	msgs := fetchStream(t, s, "TRUSTME", len(messages))
+	if len(msgs) != len(messages) {
+		t.Fatalf("got %d messages, want %d", len(msgs), len(messages))
+	}
+	for i, msg := range msgs {
+		want := messages[i]
+		if subject := "trustme." + string(want.Event); msg.Subject() != subject {
+			t.Errorf("message %d: subject %s, want %s", i, msg.Subject(), subject)
+		}
+		if id := msg.Headers().Get(nats.MsgIdHdr); id != want.ID {
+			t.Errorf("message %d: id %s, want %s", i, id, want.ID)
+		}
+		if key := msg.Headers().Get(HeaderKey); key != want.Key {
+			t.Errorf("message %d: key %s, want %s", i, key, want.Key)
+		}
+		if string(msg.Data()) != string(want.Payload) {
+			t.Errorf("message %d: data %s, want %s", i, msg.Data(), want.Payload)
+		}
+	}
+}
+
+func TestNATSSinkDeduplicatesRedeliveries(t *testing.T) {
+	srv := runNATSServer(t)
+	s := newTestNATSSink(t, srv, "TRUSTME")
+
+	messages := outboxMessages()
+	for i := 0; i < 2; i++ {
+		if err := s.Send(context.Background(), messages); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	stream, err := s.js.Stream(context.Background(), "TRUSTME")
+	if err != nil {
+		t.Fatal(err)
+	}
+	info, err := stream.Info(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.State.Msgs != uint64(len(messages)) {
+		t.Fatalf("stream holds %d messages, want %d", info.State.Msgs, len(messages))
+	}
+}
+
+func TestNATSSinkFailsWithoutStream(t *testing.T) {
+	srv := runNATSServer(t)
+	s := newTestNATSSink(t, srv, "")
+
+	if err := s.Send(context.Background(), outboxMessages()); err == nil {
+		t.Fatal("send succeeded without a stream to acknowledge it")
+	}
+}
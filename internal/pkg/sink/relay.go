@@ -0,0 +1,144 @@
+package sink
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/vuquang23/trustme/internal/pkg/entity"
+	"github.com/vuquang23/trustme/internal/pkg/util/id"
+	"github.com/vuquang23/trustme/pkg/logger"
+)
+
+// Relay writes every published event to the outbox and publishes the outbox to the broker in order.
+//
+// Events are written synchronously from Publish, before the parser saves its checkpoint, and removed
+// from the outbox only once the broker accepted them: they are delivered at least once, consumers
+// deduplicate on the message ID.
+type Relay struct {
+	cfg  Config
+	repo IOutboxRepository
+	sink ISink
+	// eventData encodes the data of the messages.
+	eventData entity.EventDataFunc
+
+	wake chan struct{}
+}
+
+// New connects to the configured broker.
+func New(cfg Config, repo IOutboxRepository, eventData entity.EventDataFunc) (*Relay, error) {
+	var (
+		sink ISink
+		err  error
+	)
+	switch cfg.Driver {
+	case DriverNATS:
+		sink, err = NewNATSSink(cfg.NATS)
+	case DriverKafka:
+		sink = NewKafkaSink(cfg.Kafka)
+	default:
+		err = fmt.Errorf("unsupported sink driver: %s", cfg.Driver)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &Relay{
+		cfg:       cfg,
+		repo:      repo,
+		sink:      sink,
+		eventData: eventData,
+		wake:      make(chan struct{}, 1),
+	}, nil
+}
+
+// Publish appends the event to the outbox. It fails when the event could not be appended, so the block is
+// processed again instead of losing it.
+func (r *Relay) Publish(event entity.Event) error {
+	now := time.Now().UTC()
+	messageID := id.New()
+	payload, err := json.Marshal(entity.NewEventPayload(messageID, event, now, r.eventData(event)))
+	if err != nil {
+		return fmt.Errorf("encode sink message: %w", err)
+	}
+
+	key := event.Address
+	if event.Type == entity.EventTypeBlock {
+		key = strconv.FormatUint(event.Block.Number, 10)
+	}
+
+	err = r.repo.Append(&entity.OutboxMessage{
+		ID:        messageID,
+		Event:     event.Type,
+		Key:       key,
+		Payload:   payload,
+		CreatedAt: now,
+	})
+	if err != nil {
+		return fmt.Errorf("append %s event to outbox: %w", event.Type, err)
+	}
+
+	select {
+	case r.wake <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+// Run publishes the outbox until ctx is done.
+func (r *Relay) Run(ctx context.Context) error {
+	ticker := time.NewTicker(r.cfg.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		for r.flush(ctx) {
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		case <-r.wake:
+		}
+	}
+}
+
+// flush publishes one batch of the outbox and reports whether the batch was full.
+// A batch failing is published again as a whole after RetryBackoff, keeping the order.
+func (r *Relay) flush(ctx context.Context) bool {
+	messages, err := r.repo.Pending(r.cfg.BatchSize)
+	if err != nil {
+		logger.Errorf(ctx, "load outbox failed: %s", err)
+		return false
+	}
+	if len(messages) == 0 {
+		return false
+	}
+
+	if err := r.sink.Send(ctx, messages); err != nil {
+		logger.WithFields(ctx, logger.Fields{
+			"driver":   r.cfg.Driver,
+			"messages": len(messages),
+			"errorMsg": err.Error(),
+		}).Warn("failed to publish outbox")
+
+		select {
+		case <-ctx.Done():
+		case <-time.After(r.cfg.RetryBackoff):
+		}
+		return false
+	}
+
+	if err := r.repo.Delete(messages); err != nil {
+		logger.Errorf(ctx, "delete published outbox messages failed: %s", err)
+		return false
+	}
+
+	return ctx.Err() == nil && len(messages) == r.cfg.BatchSize
+}
+
+func (r *Relay) Close() error {
+	return r.sink.Close()
+}
@@ -0,0 +1,106 @@
+package sink
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/vuquang23/trustme/internal/pkg/entity"
+	"github.com/vuquang23/trustme/internal/pkg/repository/outbox"
+)
+
+type failingOutbox struct {
+	outbox.MemRepository
+}
+
+var errOutboxDown = errors.New("outbox down")
+
+func (o *failingOutbox) Append(*entity.OutboxMessage) error {
+	return errOutboxDown
+}
+
+func newTestRelay(repo IOutboxRepository, sink ISink) *Relay {
+	return &Relay{
+		cfg:       Config{BatchSize: 10},
+		repo:      repo,
+		sink:      sink,
+		eventData: func(event entity.Event) interface{} { return event.Record.TxIndex },
+		wake:      make(chan struct{}, 1),
+	}
+}
+
+func txEvent(txIndex uint) entity.Event {
+	return entity.Event{
+		Type:    entity.EventTypeTx,
+		Address: "0x1f9090aae28b8a3dceadf281b0f12828e676c326",
+		Record:  &entity.TxRecord{TxIndex: txIndex},
+	}
+}
+
+func TestRelayPublishFailsWhenOutboxFails(t *testing.T) {
+	relay := newTestRelay(&failingOutbox{}, nil)
+
+	if err := relay.Publish(txEvent(1)); !errors.Is(err, errOutboxDown) {
+		t.Fatalf("got %v, want %v", err, errOutboxDown)
+	}
+}
+
+func TestRelayFlushesOutboxToNATS(t *testing.T) {
+	srv := runNATSServer(t)
+	repo := outbox.NewMemRepository()
+	relay := newTestRelay(repo, newTestNATSSink(t, srv, "TRUSTME"))
+
+	for i := uint(0); i < 3; i++ {
+		if err := relay.Publish(txEvent(i)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	relay.flush(context.Background())
+
+	pending, err := repo.Pending(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pending) != 0 {
+		t.Fatalf("%d messages left in the outbox", len(pending))
+	}
+
+	msgs := fetchStream(t, relay.sink.(*NATSSink), "TRUSTME", 3)
+	if len(msgs) != 3 {
+		t.Fatalf("got %d messages, want 3", len(msgs))
+	}
+	for i, msg := range msgs {
+		var payload entity.EventPayload
+		if err := json.Unmarshal(msg.Data(), &payload); err != nil {
+			t.Fatal(err)
+		}
+		if payload.Event != string(entity.EventTypeTx) || payload.Address != "0x1f9090aaE28b8a3dCeaDf281B0F12828e676c326" {
+			t.Errorf("message %d: unexpected payload %+v", i, payload)
+		}
+		if payload.Data != float64(i) {
+			t.Errorf("message %d: data %v, want %d", i, payload.Data, i)
+		}
+	}
+}
+
+func TestRelayKeepsOutboxWhenSendFails(t *testing.T) {
+	srv := runNATSServer(t)
+	repo := outbox.NewMemRepository()
+	relay := newTestRelay(repo, newTestNATSSink(t, srv, ""))
+
+	if err := relay.Publish(txEvent(1)); err != nil {
+		t.Fatal(err)
+	}
+
+	// no stream acknowledges the message
+	relay.flush(context.Background())
+
+	pending, err := repo.Pending(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pending) != 1 {
+		t.Fatalf("%d messages left in the outbox, want 1", len(pending))
+	}
+}
@@ -0,0 +1,57 @@
+package tenant
+
+import (
+	"context"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+
+	"github.com/vuquang23/trustme/internal/pkg/entity"
+)
+
+type IParser interface {
+	// last parsed block
+	GetCurrentBlock() int
+
+	// add address to observer with its metadata
+	AddSubscription(subscription *entity.Subscription) error
+
+	// remove address from observer
+	Unsubscribe(address string) error
+
+	// subscription of an observed address
+	GetSubscription(address string) (*entity.Subscription, error)
+
+	// page of observed addresses
+	GetSubscriptions(cursor string, limit int) (*entity.SubscriptionPage, error)
+
+	// list of inbound or outbound transactions for an address
+	GetTransactions(address string) []*types.Transaction
+
+	// page of transactions for an address with their block context
+	QueryTxRecords(address string, query entity.TxQuery) (*entity.TxPage, error)
+
+	// a transaction with its receipt and decoded logs, looked up on the RPC when not indexed
+	GetTxDetail(ctx context.Context, hash common.Hash) (*entity.TxDetail, error)
+
+	// token transfers of a mined transaction
+	GetTokenTransfers(ctx context.Context, hash common.Hash) ([]*entity.TokenTransfer, error)
+}
+
+type IRepository interface {
+	Create(tenant string, subscription *entity.Subscription) error
+	Delete(tenant, address string) error
+	// Get returns nil when the tenant does not subscribe to the address.
+	Get(tenant, address string) (*entity.Subscription, error)
+	// List returns the subscriptions of a tenant ordered by address, starting after cursor.
+	List(tenant, cursor string, limit int) (*entity.SubscriptionPage, error)
+	// Tenants returns the tenants subscribing to an address.
+	Tenants(address string) ([]string, error)
+}
+
+type IQuota interface {
+	// count a new subscription of a tenant, ErrQuotaExceeded when over its quota
+	ConsumeSubscription(tenant string) error
+	// give back the subscription of a failed subscribe
+	RefundSubscription(tenant string)
+}
@@ -0,0 +1,134 @@
+package tenant
+
+import (
+	"context"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+
+	"github.com/vuquang23/trustme/internal/pkg/entity"
+)
+
+// Parser is the parser restricted to the subscriptions of a tenant. Without a tenant it is the parser itself.
+// Every method of the parser is wrapped, so none of them reaches the parser unscoped by mistake.
+type Parser struct {
+	parser  IParser
+	tenants *Tenants
+	tenant  string
+}
+
+// Tenant is the tenant the parser is restricted to, empty when it is not.
+func (p *Parser) Tenant() string {
+	return p.tenant
+}
+
+// Allows tells whether the tenant subscribes to an address, always true without a tenant.
+func (p *Parser) Allows(address string) bool {
+	if p.tenant == "" {
+		return true
+	}
+	subscription, err := p.tenants.repo.Get(p.tenant, address)
+	return err == nil && subscription != nil
+}
+
+// GetCurrentBlock is the same for every tenant.
+func (p *Parser) GetCurrentBlock() int {
+	return p.parser.GetCurrentBlock()
+}
+
+func (p *Parser) Subscribe(address string) bool {
+	return p.AddSubscription(&entity.Subscription{Address: address}) == nil
+}
+
+func (p *Parser) AddSubscription(subscription *entity.Subscription) error {
+	if p.tenant == "" {
+		return p.tenants.subscribeUnscoped(subscription)
+	}
+	return p.tenants.subscribe(p.tenant, subscription)
+}
+
+func (p *Parser) Unsubscribe(address string) error {
+	if p.tenant == "" {
+		return p.tenants.unsubscribeUnscoped(address)
+	}
+	return p.tenants.unsubscribe(p.tenant, address)
+}
+
+func (p *Parser) GetSubscription(address string) (*entity.Subscription, error) {
+	if p.tenant == "" {
+		return p.parser.GetSubscription(address)
+	}
+
+	subscription, err := p.tenants.repo.Get(p.tenant, address)
+	if err != nil {
+		return nil, entity.BackendError(err)
+	}
+	if subscription == nil {
+		return nil, entity.ErrNotSubscribed
+	}
+	return subscription, nil
+}
+
+func (p *Parser) GetSubscriptions(cursor string, limit int) (*entity.SubscriptionPage, error) {
+	if p.tenant == "" {
+		return p.parser.GetSubscriptions(cursor, limit)
+	}
+
+	page, err := p.tenants.repo.List(p.tenant, cursor, limit)
+	if err != nil {
+		return nil, entity.BackendError(err)
+	}
+	return page, nil
+}
+
+func (p *Parser) GetTransactions(address string) []*types.Transaction {
+	if !p.Allows(address) {
+		return []*types.Transaction{}
+	}
+	return p.parser.GetTransactions(address)
+}
+
+func (p *Parser) QueryTxRecords(address string, query entity.TxQuery) (*entity.TxPage, error) {
+	if !p.Allows(address) {
+		return nil, entity.ErrNotSubscribed
+	}
+	return p.parser.QueryTxRecords(address, query)
+}
+
+// GetTxDetail only returns the transactions indexed for one of the tenant subscriptions, with their records.
+func (p *Parser) GetTxDetail(ctx context.Context, hash common.Hash) (*entity.TxDetail, error) {
+	detail, err := p.parser.GetTxDetail(ctx, hash)
+	if err != nil || p.tenant == "" {
+		return detail, err
+	}
+
+	records := make([]*entity.TxRecord, 0, len(detail.Records))
+	for _, record := range detail.Records {
+		if p.Allows(record.Address) {
+			records = append(records, record)
+		}
+	}
+	if len(records) == 0 {
+		return nil, entity.ErrTxNotFound
+	}
+
+	detail.Records = records
+	return detail, nil
+}
+
+// GetTokenTransfers only returns the transfers of the transactions indexed for one of the tenant subscriptions.
+func (p *Parser) GetTokenTransfers(ctx context.Context, hash common.Hash) ([]*entity.TokenTransfer, error) {
+	if p.tenant == "" {
+		return p.parser.GetTokenTransfers(ctx, hash)
+	}
+
+	detail, err := p.GetTxDetail(ctx, hash)
+	if err != nil {
+		return nil, err
+	}
+	// the detail has no transfers when its receipt could not be read
+	if detail.Receipt == nil {
+		return p.parser.GetTokenTransfers(ctx, hash)
+	}
+	return detail.Transfers, nil
+}
@@ -0,0 +1,227 @@
+package tenant
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/vuquang23/trustme/internal/pkg/auth"
+	"github.com/vuquang23/trustme/internal/pkg/entity"
+)
+
+// Tenants scopes the parser to the tenant of a request.
+//
+// The parser watches an address as long as one tenant, or an unscoped request, subscribes to it. Each tenant
+// keeps its own subscription metadata and only sees the transactions of its own subscriptions.
+type Tenants struct {
+	parser IParser
+	repo   IRepository
+	quota  IQuota
+
+	// mu serializes subscription changes, so the parser subscription follows the tenant ones.
+	mu sync.Mutex
+}
+
+// unscopedOwner owns the subscriptions made without a tenant, so they are counted along the tenant ones.
+// Subscriptions made before it existed have no owner at all and are unscoped too.
+const unscopedOwner = ""
+
+func New(parser IParser, repo IRepository, quota IQuota) *Tenants {
+	return &Tenants{
+		parser: parser,
+		repo:   repo,
+		quota:  quota,
+	}
+}
+
+// Parser returns the parser as seen by the tenant of the request. Requests without a tenant,
+// authentication disabled or admin ones, see the parser as is.
+func (t *Tenants) Parser(ctx context.Context) *Parser {
+	var tenant string
+	if principal := auth.PrincipalFromContext(ctx); principal != nil && !principal.Admin {
+		tenant = principal.Tenant
+	}
+	return &Parser{parser: t.parser, tenants: t, tenant: tenant}
+}
+
+func (t *Tenants) subscribe(tenant string, subscription *entity.Subscription) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	existing, err := t.repo.Get(tenant, subscription.Address)
+	if err != nil {
+		return entity.BackendError(err)
+	}
+	if existing != nil {
+		return entity.ErrAlreadySubscribed
+	}
+
+	if err := t.quota.ConsumeSubscription(tenant); err != nil {
+		return err
+	}
+
+	if subscription.CreatedAt.IsZero() {
+		subscription.CreatedAt = time.Now().UTC()
+	}
+	added, err := t.addToParser(subscription)
+	if err != nil {
+		t.quota.RefundSubscription(tenant)
+		return err
+	}
+
+	if err := t.repo.Create(tenant, subscription); err != nil {
+		t.quota.RefundSubscription(tenant)
+		return entity.BackendError(t.rollBack(added, subscription.Address, err))
+	}
+	return nil
+}
+
+// rollBack unsubscribes the parser from an address it was subscribed to for a subscription that could not be
+// recorded, it would be watched without any owner otherwise.
+func (t *Tenants) rollBack(added bool, address string, err error) error {
+	if !added {
+		return err
+	}
+	if unsubscribeErr := t.parser.Unsubscribe(address); unsubscribeErr != nil {
+		return errors.Join(err, fmt.Errorf("roll back the subscription of %s: %w", address, unsubscribeErr))
+	}
+	return err
+}
+
+func (t *Tenants) unsubscribe(tenant, address string) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	existing, err := t.repo.Get(tenant, address)
+	if err != nil {
+		return entity.BackendError(err)
+	}
+	if existing == nil {
+		return entity.ErrNotSubscribed
+	}
+
+	if err := t.repo.Delete(tenant, address); err != nil {
+		return entity.BackendError(err)
+	}
+	return t.releaseFromParser(address)
+}
+
+// addToParser subscribes the parser to the address of a tenant subscription, it tells whether the parser was not
+// watching it yet. An address the parser already watches without any owner was subscribed unscoped, it is
+// recorded as such so it outlives the tenant one.
+func (t *Tenants) addToParser(subscription *entity.Subscription) (bool, error) {
+	err := t.parser.AddSubscription(subscription)
+	if err == nil {
+		return true, nil
+	}
+	if !errors.Is(err, entity.ErrAlreadySubscribed) {
+		return false, err
+	}
+
+	owners, err := t.repo.Tenants(subscription.Address)
+	if err != nil {
+		return false, entity.BackendError(err)
+	}
+	if len(owners) > 0 {
+		return false, nil
+	}
+	if err := t.repo.Create(unscopedOwner, &entity.Subscription{Address: subscription.Address, CreatedAt: subscription.CreatedAt}); err != nil {
+		return false, entity.BackendError(err)
+	}
+	return false, nil
+}
+
+// releaseFromParser unsubscribes the parser from an address once nobody owns it anymore.
+func (t *Tenants) releaseFromParser(address string) error {
+	owners, err := t.repo.Tenants(address)
+	if err != nil {
+		return entity.BackendError(err)
+	}
+	if len(owners) > 0 {
+		return nil
+	}
+
+	if err := t.parser.Unsubscribe(address); err != nil && !errors.Is(err, entity.ErrNotSubscribed) {
+		return err
+	}
+	return nil
+}
+
+// subscribeUnscoped subscribes an address without a tenant. It fails with ErrAlreadySubscribed only when the
+// address is already subscribed unscoped, a subscription of tenants gets an unscoped owner along.
+func (t *Tenants) subscribeUnscoped(subscription *entity.Subscription) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	err := t.parser.AddSubscription(subscription)
+	if err != nil && !errors.Is(err, entity.ErrAlreadySubscribed) {
+		return err
+	}
+	added := err == nil
+
+	if err != nil {
+		owned, err := t.ownedUnscoped(subscription.Address)
+		if err != nil {
+			return err
+		}
+		if owned {
+			return entity.ErrAlreadySubscribed
+		}
+	}
+
+	createdAt := subscription.CreatedAt
+	if createdAt.IsZero() {
+		createdAt = time.Now().UTC()
+	}
+	if err := t.repo.Create(unscopedOwner, &entity.Subscription{Address: subscription.Address, CreatedAt: createdAt}); err != nil {
+		return entity.BackendError(t.rollBack(added, subscription.Address, err))
+	}
+	return nil
+}
+
+// unsubscribeUnscoped drops the unscoped subscription of an address, the parser keeps watching it as long as
+// tenants subscribe to it.
+func (t *Tenants) unsubscribeUnscoped(address string) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	owned, err := t.ownedUnscoped(address)
+	if err != nil {
+		return err
+	}
+	if !owned {
+		return entity.ErrNotSubscribed
+	}
+
+	if err := t.repo.Delete(unscopedOwner, address); err != nil {
+		return entity.BackendError(err)
+	}
+	return t.releaseFromParser(address)
+}
+
+// ownedUnscoped tells whether an address is subscribed unscoped: recorded as such, or watched by the parser
+// without any owner.
+func (t *Tenants) ownedUnscoped(address string) (bool, error) {
+	owners, err := t.repo.Tenants(address)
+	if err != nil {
+		return false, entity.BackendError(err)
+	}
+	for _, owner := range owners {
+		if owner == unscopedOwner {
+			return true, nil
+		}
+	}
+	if len(owners) > 0 {
+		return false, nil
+	}
+
+	if _, err := t.parser.GetSubscription(address); err != nil {
+		if errors.Is(err, entity.ErrNotSubscribed) {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
@@ -0,0 +1,273 @@
+package tenant
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+
+	"github.com/vuquang23/trustme/internal/pkg/auth"
+	"github.com/vuquang23/trustme/internal/pkg/entity"
+	tenantrepo "github.com/vuquang23/trustme/internal/pkg/repository/tenant"
+)
+
+// fakeParser watches addresses and indexes the records it is given.
+type fakeParser struct {
+	subscriptions map[string]*entity.Subscription
+	records       []*entity.TxRecord
+}
+
+func newFakeParser() *fakeParser {
+	return &fakeParser{subscriptions: make(map[string]*entity.Subscription)}
+}
+
+func (f *fakeParser) GetCurrentBlock() int {
+	return 42
+}
+
+func (f *fakeParser) AddSubscription(subscription *entity.Subscription) error {
+	if _, ok := f.subscriptions[subscription.Address]; ok {
+		return entity.ErrAlreadySubscribed
+	}
+	f.subscriptions[subscription.Address] = subscription
+	return nil
+}
+
+func (f *fakeParser) Unsubscribe(address string) error {
+	if _, ok := f.subscriptions[address]; !ok {
+		return entity.ErrNotSubscribed
+	}
+	delete(f.subscriptions, address)
+	return nil
+}
+
+func (f *fakeParser) GetSubscription(address string) (*entity.Subscription, error) {
+	subscription, ok := f.subscriptions[address]
+	if !ok {
+		return nil, entity.ErrNotSubscribed
+	}
+	return subscription, nil
+}
+
+func (f *fakeParser) GetSubscriptions(string, int) (*entity.SubscriptionPage, error) {
+	page := &entity.SubscriptionPage{}
+	for _, subscription := range f.subscriptions {
+		page.Subscriptions = append(page.Subscriptions, subscription)
+	}
+	return page, nil
+}
+
+func (f *fakeParser) GetTransactions(address string) []*types.Transaction {
+	txs := []*types.Transaction{}
+	for _, record := range f.records {
+		if record.Address == address {
+			txs = append(txs, record.Tx)
+		}
+	}
+	return txs
+}
+
+func (f *fakeParser) QueryTxRecords(address string, _ entity.TxQuery) (*entity.TxPage, error) {
+	page := &entity.TxPage{Records: []*entity.TxRecord{}}
+	for _, record := range f.records {
+		if record.Address == address {
+			page.Records = append(page.Records, record)
+		}
+	}
+	return page, nil
+}
+
+func (f *fakeParser) GetTxDetail(_ context.Context, hash common.Hash) (*entity.TxDetail, error) {
+	detail := &entity.TxDetail{Indexed: true, Receipt: &types.Receipt{TxHash: hash}}
+	for _, record := range f.records {
+		if record.Tx.Hash() == hash {
+			detail.Tx = record.Tx
+			detail.Records = append(detail.Records, record)
+			detail.Transfers = append(detail.Transfers, &entity.TokenTransfer{To: common.HexToAddress(record.Address)})
+		}
+	}
+	if detail.Tx == nil {
+		return nil, entity.ErrTxNotFound
+	}
+	return detail, nil
+}
+
+func (f *fakeParser) GetTokenTransfers(ctx context.Context, hash common.Hash) ([]*entity.TokenTransfer, error) {
+	detail, err := f.GetTxDetail(ctx, hash)
+	if err != nil {
+		return nil, err
+	}
+	return detail.Transfers, nil
+}
+
+// fakeQuota counts the subscriptions of each tenant, without any limit.
+type fakeQuota map[string]int
+
+func (q fakeQuota) ConsumeSubscription(tenant string) error {
+	q[tenant]++
+	return nil
+}
+
+func (q fakeQuota) RefundSubscription(tenant string) {
+	q[tenant]--
+}
+
+// failingRepository fails to create any subscription.
+type failingRepository struct {
+	*tenantrepo.MemRepository
+}
+
+func (failingRepository) Create(string, *entity.Subscription) error {
+	return errors.New("disk full")
+}
+
+const (
+	alice = "0x00000000000000000000000000000000000000a1"
+	bob   = "0x00000000000000000000000000000000000000b0"
+)
+
+func scoped(tenants *Tenants, tenant string) *Parser {
+	return tenants.Parser(auth.WithPrincipal(context.Background(), &entity.Principal{KeyID: tenant, Tenant: tenant}))
+}
+
+func addresses(page *entity.SubscriptionPage) []string {
+	var addresses []string
+	for _, subscription := range page.Subscriptions {
+		addresses = append(addresses, subscription.Address)
+	}
+	sort.Strings(addresses)
+	return addresses
+}
+
+func TestTenantIsolation(t *testing.T) {
+	parser := newFakeParser()
+	tx := types.NewTx(&types.LegacyTx{Nonce: 1})
+	parser.records = []*entity.TxRecord{{Address: alice, Tx: tx}, {Address: bob, Tx: tx}}
+	tenants := New(parser, tenantrepo.NewMemRepository(), fakeQuota{})
+
+	acme, globex := scoped(tenants, "acme"), scoped(tenants, "globex")
+	if err := acme.AddSubscription(&entity.Subscription{Address: alice}); err != nil {
+		t.Fatal(err)
+	}
+	if err := globex.AddSubscription(&entity.Subscription{Address: bob}); err != nil {
+		t.Fatal(err)
+	}
+
+	page, err := acme.GetSubscriptions("", 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := addresses(page); len(got) != 1 || got[0] != alice {
+		t.Fatalf("got subscriptions %v, want only %s", got, alice)
+	}
+	if _, err := acme.GetSubscription(bob); !errors.Is(err, entity.ErrNotSubscribed) {
+		t.Fatalf("got %v for the subscription of another tenant, want %v", err, entity.ErrNotSubscribed)
+	}
+
+	if _, err := acme.QueryTxRecords(bob, entity.TxQuery{}); !errors.Is(err, entity.ErrNotSubscribed) {
+		t.Fatalf("got %v querying another tenant address, want %v", err, entity.ErrNotSubscribed)
+	}
+	if txs := acme.GetTransactions(bob); len(txs) != 0 {
+		t.Fatalf("got %d transactions of another tenant address", len(txs))
+	}
+	txPage, err := acme.QueryTxRecords(alice, entity.TxQuery{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(txPage.Records) != 1 {
+		t.Fatalf("got %d records, want 1", len(txPage.Records))
+	}
+
+	detail, err := acme.GetTxDetail(context.Background(), tx.Hash())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(detail.Records) != 1 || detail.Records[0].Address != alice {
+		t.Fatalf("got records %+v, want only the one of %s", detail.Records, alice)
+	}
+
+	transfers, err := acme.GetTokenTransfers(context.Background(), tx.Hash())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(transfers) != 2 {
+		t.Fatalf("got %d transfers, want the 2 of the transaction", len(transfers))
+	}
+	if _, err := scoped(tenants, "initech").GetTokenTransfers(context.Background(), tx.Hash()); !errors.Is(err, entity.ErrTxNotFound) {
+		t.Fatalf("got %v for a transaction of no subscription of the tenant, want %v", err, entity.ErrTxNotFound)
+	}
+
+	if err := acme.Unsubscribe(bob); !errors.Is(err, entity.ErrNotSubscribed) {
+		t.Fatalf("got %v unsubscribing another tenant address, want %v", err, entity.ErrNotSubscribed)
+	}
+	if _, ok := parser.subscriptions[bob]; !ok {
+		t.Fatalf("%s is no longer watched after another tenant unsubscribed from it", bob)
+	}
+
+	unscoped := tenants.Parser(context.Background())
+	if got, err := unscoped.GetSubscriptions("", 10); err != nil || len(got.Subscriptions) != 2 {
+		t.Fatalf("got %v, %v, want both subscriptions unscoped", got, err)
+	}
+}
+
+func TestSharedSubscriptions(t *testing.T) {
+	parser := newFakeParser()
+	tenants := New(parser, tenantrepo.NewMemRepository(), fakeQuota{})
+	acme, globex, unscoped := scoped(tenants, "acme"), scoped(tenants, "globex"), tenants.Parser(context.Background())
+
+	for _, p := range []*Parser{acme, globex, unscoped} {
+		if err := p.AddSubscription(&entity.Subscription{Address: alice}); err != nil {
+			t.Fatal(err)
+		}
+		if err := p.AddSubscription(&entity.Subscription{Address: alice}); !errors.Is(err, entity.ErrAlreadySubscribed) {
+			t.Fatalf("got %v subscribing twice, want %v", err, entity.ErrAlreadySubscribed)
+		}
+	}
+
+	for i, p := range []*Parser{acme, unscoped, globex} {
+		if _, ok := parser.subscriptions[alice]; !ok {
+			t.Fatalf("%s is no longer watched after %d unsubscriptions", alice, i)
+		}
+		if err := p.Unsubscribe(alice); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, ok := parser.subscriptions[alice]; ok {
+		t.Fatalf("%s is still watched once nobody subscribes to it", alice)
+	}
+}
+
+func TestSubscribeRollsBack(t *testing.T) {
+	parser := newFakeParser()
+	quota := fakeQuota{}
+	tenants := New(parser, failingRepository{tenantrepo.NewMemRepository()}, quota)
+
+	if err := scoped(tenants, "acme").AddSubscription(&entity.Subscription{Address: alice}); !errors.Is(err, entity.ErrBackendUnavailable) {
+		t.Fatalf("got %v, want %v", err, entity.ErrBackendUnavailable)
+	}
+	if _, ok := parser.subscriptions[alice]; ok {
+		t.Fatalf("%s is watched without any owner", alice)
+	}
+	if quota["acme"] != 0 {
+		t.Fatalf("got %d subscriptions counted, want the failed one refunded", quota["acme"])
+	}
+
+	if err := tenants.Parser(context.Background()).AddSubscription(&entity.Subscription{Address: alice}); !errors.Is(err, entity.ErrBackendUnavailable) {
+		t.Fatalf("got %v unscoped, want %v", err, entity.ErrBackendUnavailable)
+	}
+	if _, ok := parser.subscriptions[alice]; ok {
+		t.Fatalf("%s is watched without any owner after an unscoped subscription", alice)
+	}
+
+	// an address watched before is kept
+	parser.subscriptions[bob] = &entity.Subscription{Address: bob}
+	if err := scoped(tenants, "acme").AddSubscription(&entity.Subscription{Address: bob}); !errors.Is(err, entity.ErrBackendUnavailable) {
+		t.Fatalf("got %v, want %v", err, entity.ErrBackendUnavailable)
+	}
+	if _, ok := parser.subscriptions[bob]; !ok {
+		t.Fatalf("%s watched before is no longer watched", bob)
+	}
+}
@@ -0,0 +1,24 @@
+package tracing
+
+import "time"
+
+const (
+	ProtocolGRPC = "grpc"
+	ProtocolHTTP = "http"
+)
+
+type Config struct {
+	// Enabled exports the spans with OTLP. Incoming traceparent headers are propagated either way.
+	Enabled bool
+	// Protocol of the OTLP exporter, grpc or http.
+	Protocol string `default:"grpc"`
+	// Endpoint is the host:port of the collector, 4317 for grpc and 4318 for http by convention.
+	Endpoint string `default:"localhost:4317"`
+	// Insecure disables TLS to the collector.
+	Insecure bool `default:"true"`
+	// SampleRatio is the ratio of the traces started here that are sampled, sampled parents are always followed.
+	SampleRatio float64 `default:"1"`
+	ServiceName string  `default:"trustme"`
+	// Timeout bounds an export.
+	Timeout time.Duration `default:"10s"`
+}
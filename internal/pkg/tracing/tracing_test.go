@@ -0,0 +1,95 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// recordSpans installs a tracer provider keeping the ended spans in memory, and the propagator of Init.
+func recordSpans(t *testing.T) *tracetest.SpanRecorder {
+	t.Helper()
+
+	if _, err := Init(context.Background(), Config{}); err != nil {
+		t.Fatal(err)
+	}
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	otel.SetTracerProvider(provider)
+	t.Cleanup(func() { _ = provider.Shutdown(context.Background()) })
+	return recorder
+}
+
+func TestRPCTransportTracesCalls(t *testing.T) {
+	recorder := recordSpans(t)
+
+	var traceparent string
+	node := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		traceparent = r.Header.Get("traceparent")
+		_, _ = w.Write([]byte(`{"jsonrpc":"2.0","id":1,"result":"0x1"}`))
+	}))
+	defer node.Close()
+
+	ctx, parent := Tracer().Start(context.Background(), "parser.fetchBlock")
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, node.URL,
+		strings.NewReader(`{"jsonrpc":"2.0","id":1,"method":"eth_blockNumber","params":[]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := (&http.Client{Transport: RPCTransport(nil)}).Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = resp.Body.Close()
+	parent.End()
+
+	spans := recorder.Ended()
+	if len(spans) != 2 {
+		t.Fatalf("got %d spans, want 2", len(spans))
+	}
+	call := spans[0]
+	if call.Name() != "rpc eth_blockNumber" || call.SpanKind() != trace.SpanKindClient {
+		t.Fatalf("got span %s of kind %s", call.Name(), call.SpanKind())
+	}
+	if call.Parent().SpanID() != parent.SpanContext().SpanID() {
+		t.Fatalf("got parent %s, want %s", call.Parent().SpanID(), parent.SpanContext().SpanID())
+	}
+
+	// the node continues the trace from the call span
+	want := "00-" + call.SpanContext().TraceID().String() + "-" + call.SpanContext().SpanID().String() + "-01"
+	if traceparent != want {
+		t.Fatalf("got traceparent %q, want %q", traceparent, want)
+	}
+}
+
+func TestEndRecordsError(t *testing.T) {
+	recorder := recordSpans(t)
+
+	_, failed := Tracer().Start(context.Background(), "parser.saveBlock")
+	End(failed, errors.New("disk full"))
+	_, succeeded := Tracer().Start(context.Background(), "parser.saveBlock")
+	End(succeeded, nil)
+
+	spans := recorder.Ended()
+	if len(spans) != 2 {
+		t.Fatalf("got %d spans, want 2", len(spans))
+	}
+	if status := spans[0].Status(); status.Code != codes.Error || status.Description != "disk full" {
+		t.Fatalf("got status %+v", status)
+	}
+	if events := spans[0].Events(); len(events) != 1 || events[0].Name != "exception" {
+		t.Fatalf("got events %+v, want the error", events)
+	}
+	if status := spans[1].Status(); status.Code != codes.Unset {
+		t.Fatalf("got status %+v of a successful span", status)
+	}
+}
@@ -0,0 +1,37 @@
+package ether
+
+import (
+	"math/big"
+	"strings"
+)
+
+const decimals = 18
+
+var weiPerEther = new(big.Int).Exp(big.NewInt(10), big.NewInt(decimals), nil)
+
+// FormatWei formats an amount of wei as an exact decimal string of ether, e.g. 1500000000000000000 -> "1.5".
+func FormatWei(wei *big.Int) string {
+	if wei == nil {
+		return "0"
+	}
+
+	sign := ""
+	if wei.Sign() < 0 {
+		sign = "-"
+	}
+
+	quo, rem := new(big.Int).QuoRem(new(big.Int).Abs(wei), weiPerEther, new(big.Int))
+	if rem.Sign() == 0 {
+		return sign + quo.String()
+	}
+
+	frac := strings.TrimRight(leftPad(rem.String(), decimals), "0")
+	return sign + quo.String() + "." + frac
+}
+
+func leftPad(s string, n int) string {
+	if len(s) >= n {
+		return s
+	}
+	return strings.Repeat("0", n-len(s)) + s
+}
@@ -0,0 +1,37 @@
+package ethrpc
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+)
+
+// Method returns the method of the JSON-RPC request sent by req, batch for a batch of calls and unknown when
+// the body can't be read again.
+func Method(req *http.Request) string {
+	if req.Body == nil || req.GetBody == nil {
+		return "unknown"
+	}
+	body, err := req.GetBody()
+	if err != nil {
+		return "unknown"
+	}
+	defer body.Close()
+
+	b, err := io.ReadAll(body)
+	if err != nil {
+		return "unknown"
+	}
+	if b = bytes.TrimSpace(b); len(b) > 0 && b[0] == '[' {
+		return "batch"
+	}
+
+	var msg struct {
+		Method string `json:"method"`
+	}
+	if err := json.Unmarshal(b, &msg); err != nil || msg.Method == "" {
+		return "unknown"
+	}
+	return msg.Method
+}
@@ -0,0 +1,16 @@
+package id
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/hex"
+	"time"
+)
+
+// New returns a random hex ID whose lexical order is its creation order.
+func New() string {
+	id := make([]byte, 16)
+	binary.BigEndian.PutUint64(id, uint64(time.Now().UnixNano()))
+	_, _ = rand.Read(id[8:])
+	return hex.EncodeToString(id)
+}
@@ -0,0 +1,21 @@
+package webhook
+
+import "time"
+
+type Config struct {
+	// Workers is the number of deliveries sent concurrently.
+	Workers int `default:"4"`
+	// Timeout bounds one delivery attempt.
+	Timeout time.Duration `default:"10s"`
+	// MaxAttempts is the number of failed attempts after which a delivery is dead-lettered.
+	MaxAttempts int `default:"10"`
+	// InitialBackoff is the delay before the first retry, doubled after every failed attempt up to MaxBackoff.
+	InitialBackoff time.Duration `default:"10s"`
+	MaxBackoff     time.Duration `default:"1h"`
+	// PollInterval is how often the outbox is checked for due deliveries.
+	PollInterval time.Duration `default:"1s"`
+	// BatchSize is the number of due deliveries loaded from the outbox at once.
+	BatchSize int `default:"100"`
+	// AllowPrivateTargets lets webhooks target loopback, link-local and private addresses.
+	AllowPrivateTargets bool
+}
@@ -0,0 +1,363 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/vuquang23/trustme/internal/pkg/entity"
+	"github.com/vuquang23/trustme/internal/pkg/util/id"
+	"github.com/vuquang23/trustme/pkg/logger"
+)
+
+const (
+	HeaderEvent     = "X-Trustme-Event"
+	HeaderDelivery  = "X-Trustme-Delivery"
+	HeaderTimestamp = "X-Trustme-Timestamp"
+	HeaderSignature = "X-Trustme-Signature"
+)
+
+// Dispatcher writes a delivery to the outbox for every published event matching a webhook,
+// and sends the due deliveries until they succeed or run out of attempts.
+//
+// Deliveries are written synchronously from Publish, before the parser saves its checkpoint,
+// so an event is delivered at least once even if the process stops right after.
+type Dispatcher struct {
+	cfg            Config
+	repo           IRepository
+	subscriberRepo ISubscriberRepository
+	client         *http.Client
+	// eventData encodes the data of the deliveries.
+	eventData entity.EventDataFunc
+
+	wake chan struct{}
+	// loadFailures counts the failed loads of the outbox in a row.
+	loadFailures int
+}
+
+func New(cfg Config, repo IRepository, subscriberRepo ISubscriberRepository, eventData entity.EventDataFunc) *Dispatcher {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if !cfg.AllowPrivateTargets {
+		dialer := &net.Dialer{Timeout: 30 * time.Second, KeepAlive: 30 * time.Second, Control: dialControl}
+		transport.DialContext = dialer.DialContext
+		transport.Proxy = nil
+	}
+
+	return &Dispatcher{
+		cfg:            cfg,
+		repo:           repo,
+		subscriberRepo: subscriberRepo,
+		client:         &http.Client{Timeout: cfg.Timeout, Transport: transport},
+		eventData:      eventData,
+		wake:           make(chan struct{}, 1),
+	}
+}
+
+func (d *Dispatcher) CreateWebhook(webhook *entity.Webhook) error {
+	if err := d.checkURL(context.Background(), webhook.URL); err != nil {
+		return err
+	}
+
+	if !d.subscriberRepo.IsSubscriber(webhook.Address) {
+		return entity.ErrNotSubscribed
+	}
+
+	if webhook.Secret == "" {
+		secret := make([]byte, 32)
+		if _, err := rand.Read(secret); err != nil {
+			return err
+		}
+		webhook.Secret = hex.EncodeToString(secret)
+	}
+
+	webhook.ID = id.New()
+	webhook.CreatedAt = time.Now().UTC()
+
+	if err := d.repo.CreateWebhook(webhook); err != nil {
+		return entity.BackendError(err)
+	}
+	return nil
+}
+
+func (d *Dispatcher) GetWebhook(id string) (*entity.Webhook, error) {
+	webhook, err := d.repo.GetWebhook(id)
+	if err != nil {
+		return nil, entity.BackendError(err)
+	}
+	if webhook == nil {
+		return nil, entity.ErrWebhookNotFound
+	}
+	return webhook, nil
+}
+
+func (d *Dispatcher) DeleteWebhook(id string) error {
+	if _, err := d.GetWebhook(id); err != nil {
+		return err
+	}
+
+	if err := d.repo.DeleteWebhook(id); err != nil {
+		return entity.BackendError(err)
+	}
+	return nil
+}
+
+func (d *Dispatcher) ListWebhooks(address string) ([]*entity.Webhook, error) {
+	webhooks, err := d.repo.ListWebhooks(address)
+	if err != nil {
+		return nil, entity.BackendError(err)
+	}
+	return webhooks, nil
+}
+
+func (d *Dispatcher) GetDelivery(id string) (*entity.WebhookDelivery, error) {
+	delivery, err := d.repo.GetDelivery(id)
+	if err != nil {
+		return nil, entity.BackendError(err)
+	}
+	if delivery == nil {
+		return nil, entity.ErrDeliveryNotFound
+	}
+	return delivery, nil
+}
+
+func (d *Dispatcher) ListDeliveries(query entity.DeliveryQuery) (*entity.DeliveryPage, error) {
+	page, err := d.repo.ListDeliveries(query)
+	if err != nil {
+		return nil, entity.BackendError(err)
+	}
+	return page, nil
+}
+
+// Replay puts a delivery back in the outbox to be sent right away, whatever its status.
+// Its past attempts are kept and it gets MaxAttempts new ones.
+func (d *Dispatcher) Replay(id string) (*entity.WebhookDelivery, error) {
+	delivery, err := d.GetDelivery(id)
+	if err != nil {
+		return nil, err
+	}
+
+	delivery.Status = entity.DeliveryStatusPending
+	delivery.Failures = 0
+	delivery.NextAttemptAt = time.Now().UTC()
+	if err := d.repo.SaveDelivery(delivery); err != nil {
+		return nil, entity.BackendError(err)
+	}
+
+	d.notify()
+	return delivery, nil
+}
+
+// Publish enqueues a delivery of the event for each webhook of its address accepting it. It fails when a
+// delivery could not be written to the outbox, so the block is processed again instead of losing it.
+func (d *Dispatcher) Publish(event entity.Event) error {
+	if event.Address == "" {
+		return nil
+	}
+
+	webhooks, err := d.repo.ListWebhooks(event.Address)
+	if err != nil {
+		return fmt.Errorf("list webhooks of %s: %w", event.Address, err)
+	}
+
+	enqueued := false
+	defer func() {
+		if enqueued {
+			d.notify()
+		}
+	}()
+
+	for _, webhook := range webhooks {
+		if !webhook.Accepts(event.Type) {
+			continue
+		}
+
+		now := time.Now().UTC()
+		deliveryID := id.New()
+		payload, err := json.Marshal(entity.NewEventPayload(deliveryID, event, now, d.eventData(event)))
+		if err != nil {
+			return fmt.Errorf("encode webhook payload: %w", err)
+		}
+
+		err = d.repo.SaveDelivery(&entity.WebhookDelivery{
+			ID:            deliveryID,
+			WebhookID:     webhook.ID,
+			Event:         event.Type,
+			Address:       event.Address,
+			Payload:       payload,
+			Status:        entity.DeliveryStatusPending,
+			NextAttemptAt: now,
+			Tenant:        webhook.Tenant,
+			CreatedAt:     now,
+		})
+		if err != nil {
+			return fmt.Errorf("enqueue webhook delivery for %s: %w", webhook.ID, err)
+		}
+		enqueued = true
+	}
+
+	return nil
+}
+
+func (d *Dispatcher) notify() {
+	select {
+	case d.wake <- struct{}{}:
+	default:
+	}
+}
+
+// Run sends the due deliveries of the outbox until ctx is done.
+func (d *Dispatcher) Run(ctx context.Context) error {
+	ticker := time.NewTicker(d.cfg.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		for d.dispatchDue(ctx) {
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		case <-d.wake:
+		}
+	}
+}
+
+// dispatchDue sends one batch of due deliveries and reports whether the batch was full.
+// After a failed load of the outbox, it waits as long as before retrying a failed delivery.
+func (d *Dispatcher) dispatchDue(ctx context.Context) bool {
+	deliveries, err := d.repo.DueDeliveries(time.Now().UTC(), d.cfg.BatchSize)
+	if err != nil {
+		d.loadFailures++
+		logger.Errorf(ctx, "load due webhook deliveries failed: %s", err)
+
+		select {
+		case <-ctx.Done():
+		case <-time.After(d.backoff(d.loadFailures)):
+		}
+		return false
+	}
+	d.loadFailures = 0
+
+	workers := make(chan struct{}, d.cfg.Workers)
+	var wg sync.WaitGroup
+	for _, delivery := range deliveries {
+		if ctx.Err() != nil {
+			break
+		}
+
+		wg.Add(1)
+		workers <- struct{}{}
+		go func(delivery *entity.WebhookDelivery) {
+			defer func() {
+				<-workers
+				wg.Done()
+			}()
+			d.dispatch(ctx, delivery)
+		}(delivery)
+	}
+	wg.Wait()
+
+	return ctx.Err() == nil && len(deliveries) == d.cfg.BatchSize
+}
+
+func (d *Dispatcher) dispatch(ctx context.Context, delivery *entity.WebhookDelivery) {
+	webhook, err := d.repo.GetWebhook(delivery.WebhookID)
+	if err != nil {
+		logger.Errorf(ctx, "get webhook %s failed: %s", delivery.WebhookID, err)
+		return
+	}
+
+	var attempt entity.DeliveryAttempt
+	if webhook == nil {
+		attempt = entity.DeliveryAttempt{At: time.Now().UTC(), Error: entity.ErrWebhookNotFound.Error()}
+		delivery.Status = entity.DeliveryStatusDead
+	} else {
+		attempt = d.send(ctx, webhook, delivery)
+		d.schedule(delivery, attempt)
+	}
+	delivery.Attempts = append(delivery.Attempts, attempt)
+
+	if err := d.repo.SaveDelivery(delivery); err != nil {
+		logger.Errorf(ctx, "save webhook delivery %s failed: %s", delivery.ID, err)
+	}
+}
+
+// schedule sets the status and next attempt of a delivery after an attempt.
+func (d *Dispatcher) schedule(delivery *entity.WebhookDelivery, attempt entity.DeliveryAttempt) {
+	if attempt.Error == "" {
+		delivery.Status = entity.DeliveryStatusDelivered
+		return
+	}
+
+	delivery.Failures++
+	if delivery.Failures >= d.cfg.MaxAttempts {
+		delivery.Status = entity.DeliveryStatusDead
+		return
+	}
+
+	delivery.NextAttemptAt = attempt.At.Add(d.backoff(delivery.Failures))
+}
+
+func (d *Dispatcher) backoff(failures int) time.Duration {
+	backoff := d.cfg.InitialBackoff
+	for i := 1; i < failures && backoff < d.cfg.MaxBackoff; i++ {
+		backoff *= 2
+	}
+	if backoff > d.cfg.MaxBackoff {
+		backoff = d.cfg.MaxBackoff
+	}
+	return backoff
+}
+
+func (d *Dispatcher) send(ctx context.Context, webhook *entity.Webhook, delivery *entity.WebhookDelivery) entity.DeliveryAttempt {
+	start := time.Now().UTC()
+	attempt := entity.DeliveryAttempt{At: start}
+
+	timestamp := strconv.FormatInt(start.Unix(), 10)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhook.URL, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		attempt.Error = err.Error()
+		return attempt
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(HeaderEvent, string(delivery.Event))
+	req.Header.Set(HeaderDelivery, delivery.ID)
+	req.Header.Set(HeaderTimestamp, timestamp)
+	req.Header.Set(HeaderSignature, "sha256="+Sign(webhook.Secret, timestamp, delivery.Payload))
+
+	resp, err := d.client.Do(req)
+	attempt.Duration = time.Since(start)
+	if err != nil {
+		attempt.Error = err.Error()
+		return attempt
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64*1024))
+
+	attempt.StatusCode = resp.StatusCode
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		attempt.Error = fmt.Sprintf("unexpected status %d", resp.StatusCode)
+	}
+	return attempt
+}
+
+// Sign is the hex HMAC-SHA256, keyed by the webhook secret, of the timestamp header, a dot and the body.
+func Sign(secret, timestamp string, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(payload)
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,253 @@
+package webhook
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/vuquang23/trustme/internal/pkg/entity"
+	webhookrepo "github.com/vuquang23/trustme/internal/pkg/repository/webhook"
+)
+
+func newTestDispatcher(cfg Config, repo IRepository) *Dispatcher {
+	if cfg.Workers == 0 {
+		cfg.Workers = 1
+	}
+	if cfg.BatchSize == 0 {
+		cfg.BatchSize = 10
+	}
+	if cfg.Timeout == 0 {
+		cfg.Timeout = 5 * time.Second
+	}
+	return New(cfg, repo, nil, func(event entity.Event) interface{} { return nil })
+}
+
+// enqueue saves a webhook to url with a delivery due now.
+func enqueue(t *testing.T, repo IRepository, url string) *entity.WebhookDelivery {
+	t.Helper()
+
+	if err := repo.CreateWebhook(&entity.Webhook{ID: "w1", Address: "0xabc", URL: url, Secret: "secret"}); err != nil {
+		t.Fatal(err)
+	}
+	delivery := &entity.WebhookDelivery{
+		ID:            "d1",
+		WebhookID:     "w1",
+		Event:         entity.EventTypeTx,
+		Address:       "0xabc",
+		Payload:       []byte(`{"id":"d1"}`),
+		Status:        entity.DeliveryStatusPending,
+		NextAttemptAt: time.Now().UTC(),
+	}
+	if err := repo.SaveDelivery(delivery); err != nil {
+		t.Fatal(err)
+	}
+	return delivery
+}
+
+func TestSign(t *testing.T) {
+	// printf '1700000000.{"id":"d1"}' | openssl dgst -sha256 -hmac secret
+	const want = "1ba9109190da01700025361ed13fd2054498487d336683cd58ecc23e88460eef"
+
+	if got := Sign("secret", "1700000000", []byte(`{"id":"d1"}`)); got != want {
+		t.Fatalf("got %s, want %s", got, want)
+	}
+	if got := Sign("other", "1700000000", []byte(`{"id":"d1"}`)); got == want {
+		t.Fatal("got the same signature with another secret")
+	}
+	if got := Sign("secret", "1700000001", []byte(`{"id":"d1"}`)); got == want {
+		t.Fatal("got the same signature with another timestamp")
+	}
+}
+
+func TestSendSignsDeliveries(t *testing.T) {
+	var header http.Header
+	var body []byte
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		header = r.Header.Clone()
+		body, _ = io.ReadAll(r.Body)
+	}))
+	defer srv.Close()
+
+	repo := webhookrepo.NewMemRepository()
+	delivery := enqueue(t, repo, srv.URL)
+	d := newTestDispatcher(Config{AllowPrivateTargets: true, MaxAttempts: 3}, repo)
+	d.dispatchDue(context.Background())
+
+	want := "sha256=" + Sign("secret", header.Get(HeaderTimestamp), delivery.Payload)
+	if got := header.Get(HeaderSignature); got != want || string(body) != string(delivery.Payload) {
+		t.Fatalf("got signature %s of %s, want %s of %s", got, body, want, delivery.Payload)
+	}
+	if header.Get(HeaderDelivery) != delivery.ID || header.Get(HeaderEvent) != string(entity.EventTypeTx) {
+		t.Fatalf("got headers %v", header)
+	}
+
+	saved, err := repo.GetDelivery(delivery.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if saved.Status != entity.DeliveryStatusDelivered || len(saved.Attempts) != 1 || saved.Attempts[0].StatusCode != http.StatusOK {
+		t.Fatalf("got delivery %+v, want it delivered", saved)
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	d := newTestDispatcher(Config{InitialBackoff: 10 * time.Second, MaxBackoff: time.Minute}, nil)
+
+	for failures, want := range map[int]time.Duration{
+		1:  10 * time.Second,
+		2:  20 * time.Second,
+		3:  40 * time.Second,
+		4:  time.Minute,
+		20: time.Minute,
+	} {
+		if got := d.backoff(failures); got != want {
+			t.Errorf("%d failures: got %s, want %s", failures, got, want)
+		}
+	}
+}
+
+func TestDeadLettersAfterMaxAttempts(t *testing.T) {
+	var requests atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer srv.Close()
+
+	repo := webhookrepo.NewMemRepository()
+	delivery := enqueue(t, repo, srv.URL)
+	d := newTestDispatcher(Config{AllowPrivateTargets: true, MaxAttempts: 3, InitialBackoff: time.Second, MaxBackoff: time.Hour}, repo)
+
+	for attempt := 1; attempt <= 3; attempt++ {
+		d.dispatchDue(context.Background())
+
+		saved, err := repo.GetDelivery(delivery.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(saved.Attempts) != attempt || saved.Failures != attempt || saved.Attempts[attempt-1].StatusCode != http.StatusInternalServerError {
+			t.Fatalf("attempt %d: got delivery %+v", attempt, saved)
+		}
+		if attempt == 3 {
+			if saved.Status != entity.DeliveryStatusDead {
+				t.Fatalf("got status %s after %d attempts, want %s", saved.Status, attempt, entity.DeliveryStatusDead)
+			}
+			break
+		}
+
+		last := saved.Attempts[attempt-1].At
+		if want := last.Add(d.backoff(attempt)); saved.Status != entity.DeliveryStatusPending || !saved.NextAttemptAt.Equal(want) {
+			t.Fatalf("attempt %d: got %s retried at %s, want pending at %s", attempt, saved.Status, saved.NextAttemptAt, want)
+		}
+		// not due before its backoff
+		if due, err := repo.DueDeliveries(time.Now().UTC(), 10); err != nil || len(due) != 0 {
+			t.Fatalf("attempt %d: got %d due deliveries, %v", attempt, len(due), err)
+		}
+
+		saved.NextAttemptAt = time.Now().UTC()
+		if err := repo.SaveDelivery(saved); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// dead deliveries are not retried
+	d.dispatchDue(context.Background())
+	if got := requests.Load(); got != 3 {
+		t.Fatalf("got %d requests, want 3", got)
+	}
+}
+
+func TestDialControlBlocksPrivateAddresses(t *testing.T) {
+	for _, address := range []string{
+		"127.0.0.1:80",
+		"10.1.2.3:443",
+		"172.16.0.1:443",
+		"192.168.1.1:80",
+		"169.254.169.254:80",
+		"0.0.0.0:80",
+		"[::1]:80",
+		"[fe80::1]:80",
+		"[fd00::1]:80",
+	} {
+		if err := dialControl("tcp", address, nil); !errors.Is(err, errPrivateTarget) {
+			t.Errorf("%s: got %v, want %v", address, err, errPrivateTarget)
+		}
+	}
+
+	for _, address := range []string{"93.184.216.34:443", "[2606:4700::1111]:443"} {
+		if err := dialControl("tcp", address, nil); err != nil {
+			t.Errorf("%s: got %v", address, err)
+		}
+	}
+}
+
+// A webhook whose host resolves to a private address once registered is refused when it is dialed.
+func TestSendRefusesPrivateTargets(t *testing.T) {
+	var requests atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+	}))
+	defer srv.Close()
+
+	repo := webhookrepo.NewMemRepository()
+	delivery := enqueue(t, repo, strings.Replace(srv.URL, "127.0.0.1", "localhost", 1))
+	d := newTestDispatcher(Config{MaxAttempts: 3, InitialBackoff: time.Second, MaxBackoff: time.Hour}, repo)
+	d.dispatchDue(context.Background())
+
+	saved, err := repo.GetDelivery(delivery.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(saved.Attempts) != 1 || !strings.Contains(saved.Attempts[0].Error, errPrivateTarget.Error()) {
+		t.Fatalf("got attempts %+v, want the dial refused", saved.Attempts)
+	}
+	if requests.Load() != 0 {
+		t.Fatal("the private target got the delivery")
+	}
+}
+
+// failingRepository fails to load the outbox.
+type failingRepository struct {
+	*webhookrepo.MemRepository
+	loads atomic.Int32
+}
+
+func (r *failingRepository) DueDeliveries(time.Time, int) ([]*entity.WebhookDelivery, error) {
+	r.loads.Add(1)
+	return nil, errors.New("outbox down")
+}
+
+func TestRunBacksOffWhenOutboxFails(t *testing.T) {
+	repo := &failingRepository{MemRepository: webhookrepo.NewMemRepository()}
+	d := newTestDispatcher(Config{PollInterval: time.Millisecond, InitialBackoff: time.Hour, MaxBackoff: time.Hour}, repo)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- d.Run(ctx) }()
+
+	// neither the ticker nor new deliveries wake the dispatcher up during its backoff
+	for i := 0; i < 50; i++ {
+		d.notify()
+		time.Sleep(time.Millisecond)
+	}
+	loads := repo.loads.Load()
+	cancel()
+
+	select {
+	case err := <-done:
+		if !errors.Is(err, context.Canceled) {
+			t.Fatalf("got %v, want %v", err, context.Canceled)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Run did not stop during its backoff")
+	}
+	if loads != 1 {
+		t.Fatalf("got %d loads of the outbox, want 1", loads)
+	}
+}
@@ -0,0 +1,28 @@
+package webhook
+
+import (
+	"time"
+
+	"github.com/vuquang23/trustme/internal/pkg/entity"
+)
+
+type IRepository interface {
+	CreateWebhook(webhook *entity.Webhook) error
+	// nil when the webhook does not exist
+	GetWebhook(id string) (*entity.Webhook, error)
+	DeleteWebhook(id string) error
+	// webhooks of an address, of every address when empty
+	ListWebhooks(address string) ([]*entity.Webhook, error)
+
+	// creates or updates a delivery and its outbox entry
+	SaveDelivery(delivery *entity.WebhookDelivery) error
+	// nil when the delivery does not exist
+	GetDelivery(id string) (*entity.WebhookDelivery, error)
+	ListDeliveries(query entity.DeliveryQuery) (*entity.DeliveryPage, error)
+	// pending deliveries whose next attempt is not after now, earliest first
+	DueDeliveries(now time.Time, limit int) ([]*entity.WebhookDelivery, error)
+}
+
+type ISubscriberRepository interface {
+	IsSubscriber(address string) bool
+}
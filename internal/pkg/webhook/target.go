@@ -0,0 +1,55 @@
+package webhook
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"syscall"
+
+	"github.com/vuquang23/trustme/internal/pkg/entity"
+)
+
+var errPrivateTarget = errors.New("webhook target is a loopback, link-local or private address")
+
+// checkURL validates the url of a new webhook. Unless private targets are allowed, its host must only
+// resolve to public addresses.
+func (d *Dispatcher) checkURL(ctx context.Context, rawURL string) error {
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("%w: url must be an absolute http(s) url", entity.ErrInvalidParams)
+	}
+	if d.cfg.AllowPrivateTargets {
+		return nil
+	}
+
+	ips, err := net.DefaultResolver.LookupIPAddr(ctx, u.Hostname())
+	if err != nil {
+		return fmt.Errorf("%w: url host can't be resolved", entity.ErrInvalidParams)
+	}
+	for _, ip := range ips {
+		if isPrivate(ip.IP) {
+			return fmt.Errorf("%w: %v", entity.ErrInvalidParams, errPrivateTarget)
+		}
+	}
+	return nil
+}
+
+// dialControl refuses connections to private addresses. Checked on the resolved address of every connection,
+// it also covers redirects and hosts resolving differently once the webhook is registered.
+func dialControl(_, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	if ip := net.ParseIP(host); ip == nil || isPrivate(ip) {
+		return errPrivateTarget
+	}
+	return nil
+}
+
+func isPrivate(ip net.IP) bool {
+	return ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() ||
+		ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() || ip.IsInterfaceLocalMulticast()
+}
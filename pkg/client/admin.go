@@ -0,0 +1,99 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+)
+
+// The admin routes are only served to admin keys, other keys fail with ErrForbidden.
+
+// CreateKey generates an API key, the returned one is the only one carrying the key.
+func (c *Client) CreateKey(ctx context.Context, params CreateKeyParams) (*APIKey, error) {
+	var key APIKey
+	if err := c.call(ctx, request{method: http.MethodPost, path: "/admin/keys", body: params}, &key); err != nil {
+		return nil, err
+	}
+	return &key, nil
+}
+
+func (c *Client) Keys(ctx context.Context) ([]APIKey, error) {
+	var keys []APIKey
+	if err := c.call(ctx, request{method: http.MethodGet, path: "/admin/keys"}, &keys); err != nil {
+		return nil, err
+	}
+	return keys, nil
+}
+
+func (c *Client) RevokeKey(ctx context.Context, id string) error {
+	return c.call(ctx, request{method: http.MethodDelete, path: "/admin/keys/" + url.PathEscape(id)}, nil)
+}
+
+func (c *Client) RateLimits(ctx context.Context) (*RateLimits, error) {
+	var limits RateLimits
+	if err := c.call(ctx, request{method: http.MethodGet, path: "/admin/rate-limits"}, &limits); err != nil {
+		return nil, err
+	}
+	return &limits, nil
+}
+
+// SetRateLimits replaces the rate limits and the subscription quota until the next restart of the server.
+func (c *Client) SetRateLimits(ctx context.Context, limits RateLimits) (*RateLimits, error) {
+	var updated RateLimits
+	if err := c.call(ctx, request{method: http.MethodPut, path: "/admin/rate-limits", body: limits}, &updated); err != nil {
+		return nil, err
+	}
+	return &updated, nil
+}
+
+// SetLogLevel changes the log level of the server until its next restart: debug, info, warn, error or fatal.
+func (c *Client) SetLogLevel(ctx context.Context, level string) error {
+	body := map[string]string{"level": level}
+	return c.call(ctx, request{method: http.MethodPut, path: "/admin/log-level", body: body}, nil)
+}
+
+func (c *Client) Parser(ctx context.Context) (*ParserInternals, error) {
+	var internals ParserInternals
+	if err := c.call(ctx, request{method: http.MethodGet, path: "/admin/parser"}, &internals); err != nil {
+		return nil, err
+	}
+	return &internals, nil
+}
+
+func (c *Client) PauseParser(ctx context.Context) error {
+	return c.call(ctx, request{method: http.MethodPost, path: "/admin/parser/pause"}, nil)
+}
+
+// ResumeParser processes new blocks again, the blocks skipped while paused are reprocessed in the background.
+func (c *Client) ResumeParser(ctx context.Context) error {
+	return c.call(ctx, request{method: http.MethodPost, path: "/admin/parser/resume"}, nil)
+}
+
+func (c *Client) ReconnectParser(ctx context.Context) error {
+	return c.call(ctx, request{method: http.MethodPost, path: "/admin/parser/reconnect"}, nil)
+}
+
+// Reprocess starts processing blocks again, it fails with ErrReprocessRunning while another one runs.
+// Its progress is reported by the backfill of the status.
+func (c *Client) Reprocess(ctx context.Context, params ReprocessParams) error {
+	return c.call(ctx, request{method: http.MethodPost, path: "/admin/parser/reprocess", body: params}, nil)
+}
+
+// CancelReprocess stops the running reprocess, it tells whether there was one.
+func (c *Client) CancelReprocess(ctx context.Context) (bool, error) {
+	var canceled bool
+	err := c.call(ctx, request{method: http.MethodDelete, path: "/admin/parser/reprocess"}, &canceled)
+	return canceled, err
+}
+
+func (c *Client) FailedBlocks(ctx context.Context) ([]FailedBlock, error) {
+	var blocks []FailedBlock
+	if err := c.call(ctx, request{method: http.MethodGet, path: "/admin/parser/failed-blocks"}, &blocks); err != nil {
+		return nil, err
+	}
+	return blocks, nil
+}
+
+func (c *Client) ClearFailedBlocks(ctx context.Context) error {
+	return c.call(ctx, request{method: http.MethodDelete, path: "/admin/parser/failed-blocks"}, nil)
+}
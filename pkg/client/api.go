@@ -0,0 +1,275 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+// Import formats of SubscribeBatchFile.
+const (
+	FormatJSON   = "json"
+	FormatCSV    = "csv"
+	FormatNDJSON = "ndjson"
+)
+
+var contentTypeByFormat = map[string]string{
+	FormatJSON:   "application/json",
+	FormatCSV:    "text/csv",
+	FormatNDJSON: "application/x-ndjson",
+}
+
+// Healthz checks that the server is alive.
+func (c *Client) Healthz(ctx context.Context) error {
+	return c.call(ctx, request{method: http.MethodGet, path: "/healthz"}, nil)
+}
+
+// Readyz returns the readiness checks, a not ready server fails with ErrNotReady listing the failed ones.
+// It is not retried.
+func (c *Client) Readyz(ctx context.Context) (map[string]string, error) {
+	var checks map[string]string
+	resp, err := c.do(ctx, http.MethodGet, "/readyz", nil, nil, "")
+	if err != nil {
+		return nil, err
+	}
+	if err := decodeResponse(resp, &checks); err != nil {
+		return nil, err
+	}
+	return checks, nil
+}
+
+func (c *Client) CurrentBlock(ctx context.Context) (uint64, error) {
+	var block uint64
+	err := c.call(ctx, request{method: http.MethodGet, path: "/api/current-block"}, &block)
+	return block, err
+}
+
+func (c *Client) Status(ctx context.Context) (*Status, error) {
+	var status Status
+	if err := c.call(ctx, request{method: http.MethodGet, path: "/api/status"}, &status); err != nil {
+		return nil, err
+	}
+	return &status, nil
+}
+
+// Subscribe subscribes an address and returns its subscription, the address checksummed.
+func (c *Client) Subscribe(ctx context.Context, params SubscribeParams) (*Subscription, error) {
+	var subscription Subscription
+	err := c.call(ctx, request{method: http.MethodPost, path: "/api/subscribe", body: params}, &subscription)
+	if err != nil {
+		return nil, err
+	}
+	return &subscription, nil
+}
+
+// SubscribeBatch subscribes a batch of addresses, each one gets its own result.
+func (c *Client) SubscribeBatch(ctx context.Context, items []SubscribeParams) (*ImportResult, error) {
+	var result ImportResult
+	err := c.call(ctx, request{method: http.MethodPost, path: "/api/subscribe/batch", body: items}, &result)
+	if err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// SubscribeBatchFile subscribes the addresses of a JSON, CSV or NDJSON file, each one gets its own result.
+func (c *Client) SubscribeBatchFile(ctx context.Context, data []byte, format string) (*ImportResult, error) {
+	contentType, ok := contentTypeByFormat[format]
+	if !ok {
+		contentType = contentTypeByFormat[FormatJSON]
+	}
+
+	var result ImportResult
+	err := c.call(ctx, request{
+		method:      http.MethodPost,
+		path:        "/api/subscribe/batch",
+		rawBody:     data,
+		contentType: contentType,
+	}, &result)
+	if err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+func (c *Client) Unsubscribe(ctx context.Context, address string) error {
+	return c.call(ctx, request{method: http.MethodDelete, path: "/api/subscribe/" + url.PathEscape(address)}, nil)
+}
+
+// Subscriptions returns a page of subscriptions ordered by address, limit 0 being the server default.
+func (c *Client) Subscriptions(ctx context.Context, cursor string, limit int) (*SubscriptionPage, error) {
+	query := url.Values{}
+	setString(query, "cursor", cursor)
+	setInt(query, "limit", limit)
+
+	var page SubscriptionPage
+	if err := c.call(ctx, request{method: http.MethodGet, path: "/api/subscriptions", query: query}, &page); err != nil {
+		return nil, err
+	}
+	return &page, nil
+}
+
+// IterSubscriptions iterates over every subscription, fetching pages of limit subscriptions.
+func (c *Client) IterSubscriptions(limit int) *Iterator[Subscription] {
+	return newIterator("", func(ctx context.Context, cursor string) ([]Subscription, string, error) {
+		page, err := c.Subscriptions(ctx, cursor, limit)
+		if err != nil {
+			return nil, "", err
+		}
+		return page.Items, page.NextCursor, nil
+	})
+}
+
+// Transactions returns the transactions of an address in the JSON encoding of go-ethereum.
+//
+// Deprecated: the whole history comes in one response, page through it with TxRecords or IterTxRecords.
+func (c *Client) Transactions(ctx context.Context, address string) ([]json.RawMessage, error) {
+	query := url.Values{"address": {address}}
+
+	var txs []json.RawMessage
+	if err := c.call(ctx, request{method: http.MethodGet, path: "/api/txs", query: query}, &txs); err != nil {
+		return nil, err
+	}
+	return txs, nil
+}
+
+// Tx returns a transaction with its receipt and decoded logs, indexed or not.
+func (c *Client) Tx(ctx context.Context, hash string) (*TxDetail, error) {
+	var detail TxDetail
+	if err := c.call(ctx, request{method: http.MethodGet, path: "/api/txs/" + url.PathEscape(hash)}, &detail); err != nil {
+		return nil, err
+	}
+	return &detail, nil
+}
+
+// TxRecords returns a page of the transactions of an address matching the query.
+func (c *Client) TxRecords(ctx context.Context, address string, q TxQuery) (*TxPage, error) {
+	query := url.Values{"address": {address}}
+	setUint(query, "fromBlock", q.FromBlock)
+	setUint(query, "toBlock", q.ToBlock)
+	setUint(query, "fromTime", q.FromTime)
+	setUint(query, "toTime", q.ToTime)
+	setString(query, "direction", q.Direction)
+	setString(query, "minValue", q.MinValue)
+	setString(query, "maxValue", q.MaxValue)
+	setString(query, "status", q.Status)
+	setString(query, "counterparty", q.Counterparty)
+	if q.Type != nil {
+		query.Set("type", strconv.FormatUint(uint64(*q.Type), 10))
+	}
+	setString(query, "order", q.Order)
+	setString(query, "cursor", q.Cursor)
+	setInt(query, "limit", q.Limit)
+
+	var page TxPage
+	if err := c.call(ctx, request{method: http.MethodGet, path: "/api/v2/txs", query: query}, &page); err != nil {
+		return nil, err
+	}
+	return &page, nil
+}
+
+// IterTxRecords iterates over the transactions of an address matching the query, starting at its cursor.
+func (c *Client) IterTxRecords(address string, q TxQuery) *Iterator[TxRecord] {
+	return newIterator(q.Cursor, func(ctx context.Context, cursor string) ([]TxRecord, string, error) {
+		q.Cursor = cursor
+		page, err := c.TxRecords(ctx, address, q)
+		if err != nil {
+			return nil, "", err
+		}
+		return page.Items, page.NextCursor, nil
+	})
+}
+
+// CreateWebhook registers a webhook, the returned one is the only one carrying the secret.
+func (c *Client) CreateWebhook(ctx context.Context, params CreateWebhookParams) (*Webhook, error) {
+	var webhook Webhook
+	if err := c.call(ctx, request{method: http.MethodPost, path: "/api/webhooks", body: params}, &webhook); err != nil {
+		return nil, err
+	}
+	return &webhook, nil
+}
+
+// Webhooks lists the webhooks, of an address when it is not empty.
+func (c *Client) Webhooks(ctx context.Context, address string) ([]Webhook, error) {
+	query := url.Values{}
+	setString(query, "address", address)
+
+	var webhooks []Webhook
+	if err := c.call(ctx, request{method: http.MethodGet, path: "/api/webhooks", query: query}, &webhooks); err != nil {
+		return nil, err
+	}
+	return webhooks, nil
+}
+
+func (c *Client) DeleteWebhook(ctx context.Context, id string) error {
+	return c.call(ctx, request{method: http.MethodDelete, path: "/api/webhooks/" + url.PathEscape(id)}, nil)
+}
+
+// Deliveries returns a page of webhook deliveries, newest first.
+func (c *Client) Deliveries(ctx context.Context, q DeliveryQuery) (*DeliveryPage, error) {
+	query := url.Values{}
+	setString(query, "webhookId", q.WebhookID)
+	setString(query, "status", q.Status)
+	setString(query, "cursor", q.Cursor)
+	setInt(query, "limit", q.Limit)
+
+	var page DeliveryPage
+	if err := c.call(ctx, request{method: http.MethodGet, path: "/api/webhook-deliveries", query: query}, &page); err != nil {
+		return nil, err
+	}
+	return &page, nil
+}
+
+// IterDeliveries iterates over the webhook deliveries matching the query, starting at its cursor.
+func (c *Client) IterDeliveries(q DeliveryQuery) *Iterator[Delivery] {
+	return newIterator(q.Cursor, func(ctx context.Context, cursor string) ([]Delivery, string, error) {
+		q.Cursor = cursor
+		page, err := c.Deliveries(ctx, q)
+		if err != nil {
+			return nil, "", err
+		}
+		return page.Items, page.NextCursor, nil
+	})
+}
+
+func (c *Client) Delivery(ctx context.Context, id string) (*Delivery, error) {
+	var delivery Delivery
+	err := c.call(ctx, request{method: http.MethodGet, path: "/api/webhook-deliveries/" + url.PathEscape(id)}, &delivery)
+	if err != nil {
+		return nil, err
+	}
+	return &delivery, nil
+}
+
+// ReplayDelivery queues a delivery to be sent again right away, whatever its status.
+func (c *Client) ReplayDelivery(ctx context.Context, id string) (*Delivery, error) {
+	var delivery Delivery
+	err := c.call(ctx, request{
+		method: http.MethodPost,
+		path:   "/api/webhook-deliveries/" + url.PathEscape(id) + "/replay",
+	}, &delivery)
+	if err != nil {
+		return nil, err
+	}
+	return &delivery, nil
+}
+
+func setString(query url.Values, key, value string) {
+	if value != "" {
+		query.Set(key, value)
+	}
+}
+
+func setInt(query url.Values, key string, value int) {
+	if value != 0 {
+		query.Set(key, strconv.Itoa(value))
+	}
+}
+
+func setUint(query url.Values, key string, value *uint64) {
+	if value != nil {
+		query.Set(key, strconv.FormatUint(*value, 10))
+	}
+}
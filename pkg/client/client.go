@@ -0,0 +1,272 @@
+// Package client is a Go client of the trustme API. It unwraps the response envelopes, retries the requests
+// refused with a 429 or failed with a 5xx, and turns the error responses into *Error.
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	headerAPIKey     = "X-API-Key"
+	headerRequestID  = "X-Request-Id"
+	defaultRetries   = 3
+	defaultMinWait   = 200 * time.Millisecond
+	defaultMaxWait   = 5 * time.Second
+	defaultUserAgent = "trustme-go-client"
+)
+
+// Client calls the trustme API, it is safe for concurrent use.
+type Client struct {
+	baseURL    string
+	httpClient *http.Client
+	apiKey     string
+	token      string
+	userAgent  string
+	retries    int
+	minWait    time.Duration
+	maxWait    time.Duration
+}
+
+type Option func(*Client)
+
+// WithAPIKey authenticates the requests with an API key, sent in the X-API-Key header.
+func WithAPIKey(key string) Option {
+	return func(c *Client) { c.apiKey = key }
+}
+
+// WithBearerToken authenticates the requests with a JWT, or an API key, sent as a bearer token.
+func WithBearerToken(token string) Option {
+	return func(c *Client) { c.token = token }
+}
+
+// WithHTTPClient replaces http.DefaultClient. Its timeout bounds each attempt, not the whole call.
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(c *Client) { c.httpClient = httpClient }
+}
+
+func WithUserAgent(userAgent string) Option {
+	return func(c *Client) { c.userAgent = userAgent }
+}
+
+// WithRetries sets how many times a request is retried after a 429, a 5xx or a transport error, 0 disables retries.
+func WithRetries(retries int) Option {
+	return func(c *Client) { c.retries = retries }
+}
+
+// WithBackoff bounds the exponential wait between two attempts. A Retry-After header takes precedence,
+// up to maxWait.
+func WithBackoff(minWait, maxWait time.Duration) Option {
+	return func(c *Client) {
+		c.minWait = minWait
+		c.maxWait = maxWait
+	}
+}
+
+// New returns a client of the API served at baseURL, such as http://localhost:8080.
+func New(baseURL string, opts ...Option) (*Client, error) {
+	baseURL = strings.TrimSuffix(baseURL, "/")
+	u, err := url.Parse(baseURL)
+	if err != nil {
+		return nil, err
+	}
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, fmt.Errorf("trustme: base url must be an absolute http(s) url: %s", baseURL)
+	}
+
+	c := &Client{
+		baseURL:    baseURL,
+		httpClient: http.DefaultClient,
+		userAgent:  defaultUserAgent,
+		retries:    defaultRetries,
+		minWait:    defaultMinWait,
+		maxWait:    defaultMaxWait,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c, nil
+}
+
+// request describes a call: the body is either marshaled to JSON, or sent as is with its content type.
+type request struct {
+	method      string
+	path        string
+	query       url.Values
+	body        interface{}
+	rawBody     []byte
+	contentType string
+}
+
+// call sends the request, retrying it when it is worth it, and decodes the data of the success response into out.
+func (c *Client) call(ctx context.Context, req request, out interface{}) error {
+	body := req.rawBody
+	contentType := req.contentType
+	if req.body != nil {
+		var err error
+		if body, err = json.Marshal(req.body); err != nil {
+			return err
+		}
+		contentType = "application/json"
+	}
+
+	for attempt := 0; ; attempt++ {
+		resp, err := c.do(ctx, req.method, req.path, req.query, body, contentType)
+		if err == nil {
+			err = decodeResponse(resp, out)
+		}
+		if err == nil {
+			return nil
+		}
+
+		if attempt >= c.retries || !retryable(ctx, err) {
+			return err
+		}
+
+		var retryAfter string
+		if resp != nil {
+			retryAfter = resp.Header.Get("Retry-After")
+		}
+		if err := sleep(ctx, c.backoff(attempt, retryAfter)); err != nil {
+			return err
+		}
+	}
+}
+
+func (c *Client) do(
+	ctx context.Context,
+	method, path string,
+	query url.Values,
+	body []byte,
+	contentType string,
+) (*http.Response, error) {
+	var reader io.Reader
+	if body != nil {
+		reader = bytes.NewReader(body)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, method, c.url(path, query), reader)
+	if err != nil {
+		return nil, err
+	}
+	if contentType != "" {
+		httpReq.Header.Set("Content-Type", contentType)
+	}
+	httpReq.Header.Set("Accept", "application/json")
+	c.authorize(httpReq.Header)
+
+	return c.httpClient.Do(httpReq)
+}
+
+// url is the URL of an API path, whose segments are already escaped.
+func (c *Client) url(path string, query url.Values) string {
+	if len(query) == 0 {
+		return c.baseURL + path
+	}
+	return c.baseURL + path + "?" + query.Encode()
+}
+
+func (c *Client) authorize(header http.Header) {
+	header.Set("User-Agent", c.userAgent)
+	if c.apiKey != "" {
+		header.Set(headerAPIKey, c.apiKey)
+	}
+	if c.token != "" {
+		header.Set("Authorization", "Bearer "+c.token)
+	}
+}
+
+// decodeResponse unwraps the envelope of a response into out, or returns its *Error.
+func decodeResponse(resp *http.Response, out interface{}) error {
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+
+	if resp.StatusCode >= http.StatusBadRequest {
+		return newError(resp, data)
+	}
+
+	var envelope Response
+	if err := json.Unmarshal(data, &envelope); err != nil {
+		return fmt.Errorf("trustme: decode response: %w", err)
+	}
+	if out == nil {
+		return nil
+	}
+	if err := json.Unmarshal(envelope.Data, out); err != nil {
+		return fmt.Errorf("trustme: decode response data: %w", err)
+	}
+	return nil
+}
+
+// newError decodes an error response. Responses not made by the API, from a proxy say, keep their status.
+func newError(resp *http.Response, data []byte) *Error {
+	apiErr := &Error{HTTPStatus: resp.StatusCode}
+	if err := json.Unmarshal(data, apiErr); err != nil || apiErr.Code == 0 {
+		apiErr.Code = resp.StatusCode
+		apiErr.Message = strings.TrimSpace(string(data))
+		if apiErr.Message == "" {
+			apiErr.Message = http.StatusText(resp.StatusCode)
+		}
+	}
+	if apiErr.RequestID == "" {
+		apiErr.RequestID = resp.Header.Get(headerRequestID)
+	}
+	return apiErr
+}
+
+// retryable tells whether a failed attempt is worth retrying: a temporary API error or a transport error,
+// unless the caller gave up.
+func retryable(ctx context.Context, err error) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+
+	var apiErr *Error
+	if errors.As(err, &apiErr) {
+		return apiErr.Temporary()
+	}
+
+	var urlErr *url.Error
+	return errors.As(err, &urlErr)
+}
+
+// backoff is the wait before the next attempt: the Retry-After of the response when there is one,
+// otherwise an exponential wait with jitter.
+func (c *Client) backoff(attempt int, retryAfter string) time.Duration {
+	if seconds, err := strconv.Atoi(retryAfter); err == nil && seconds >= 0 {
+		return min(time.Duration(seconds)*time.Second, c.maxWait)
+	}
+
+	wait := c.minWait << attempt
+	if wait <= 0 || wait > c.maxWait {
+		wait = c.maxWait
+	}
+	// Jitter over the upper half, so that concurrent clients don't retry in lockstep.
+	return wait/2 + time.Duration(rand.Int63n(int64(wait/2)+1))
+}
+
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,140 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+const testAddress = "0x1f9090aaE28b8a3dCeaDf281B0F12828e676c326"
+
+func respondSuccess(w http.ResponseWriter, data interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{"code": 0, "message": "successfully", "data": data})
+}
+
+func respondError(w http.ResponseWriter, status, code int, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{"code": code, "message": message, "requestId": "req-1"})
+}
+
+// newTestClient returns a client of a stub server, forcing the 429 and 5xx answers the API routes don't give on
+// demand. The other behaviours are tested against the routes in server_test.go.
+func newTestClient(t *testing.T, handler http.HandlerFunc, opts ...Option) *Client {
+	t.Helper()
+
+	srv := httptest.NewServer(handler)
+	t.Cleanup(srv.Close)
+
+	opts = append([]Option{WithBackoff(time.Millisecond, 5*time.Millisecond)}, opts...)
+	c, err := New(srv.URL, opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c
+}
+
+func TestRetriesTemporaryErrors(t *testing.T) {
+	var attempts atomic.Int32
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		var params SubscribeParams
+		if err := json.Unmarshal(body, &params); err != nil || params.Address != testAddress {
+			t.Errorf("attempt %d: got body %q", attempts.Load(), body)
+		}
+		if r.Header.Get(headerAPIKey) != "tm_key" {
+			t.Errorf("got api key %q", r.Header.Get(headerAPIKey))
+		}
+
+		if attempts.Add(1) < 3 {
+			respondError(w, http.StatusServiceUnavailable, CodeBackendUnavailable, "backend unavailable")
+			return
+		}
+		respondSuccess(w, Subscription{Address: testAddress})
+	}, WithAPIKey("tm_key"))
+
+	subscription, err := c.Subscribe(context.Background(), SubscribeParams{Address: testAddress})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if subscription.Address != testAddress {
+		t.Fatalf("got address %s", subscription.Address)
+	}
+	if n := attempts.Load(); n != 3 {
+		t.Fatalf("got %d attempts, want 3", n)
+	}
+}
+
+func TestGivesUpAfterRetries(t *testing.T) {
+	var attempts atomic.Int32
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		attempts.Add(1)
+		// not an API response, from a proxy
+		http.Error(w, "bad gateway", http.StatusBadGateway)
+	}, WithRetries(2))
+
+	_, err := c.Status(context.Background())
+
+	var apiErr *Error
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("got %v, want an *Error", err)
+	}
+	if apiErr.HTTPStatus != http.StatusBadGateway || apiErr.Code != http.StatusBadGateway || apiErr.Message != "bad gateway" {
+		t.Fatalf("got %+v", apiErr)
+	}
+	if n := attempts.Load(); n != 3 {
+		t.Fatalf("got %d attempts, want 3", n)
+	}
+}
+
+func TestHonoursRetryAfter(t *testing.T) {
+	var attempts atomic.Int32
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if attempts.Add(1) == 1 {
+			w.Header().Set("Retry-After", "0")
+			respondError(w, http.StatusTooManyRequests, CodeRateLimited, "too many requests")
+			return
+		}
+		respondSuccess(w, 42)
+	}, WithBackoff(time.Hour, time.Hour))
+
+	// without the Retry-After, the backoff would wait for half an hour at least
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	block, err := c.CurrentBlock(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if block != 42 {
+		t.Fatalf("got block %d, want 42", block)
+	}
+}
+
+func TestDoesNotRetryQuotaExceeded(t *testing.T) {
+	var attempts atomic.Int32
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		attempts.Add(1)
+		respondError(w, http.StatusTooManyRequests, CodeQuotaExceeded, "daily subscription quota exceeded")
+	})
+
+	_, err := c.Subscribe(context.Background(), SubscribeParams{Address: testAddress})
+	if !errors.Is(err, ErrQuotaExceeded) || errors.Is(err, ErrRateLimited) {
+		t.Fatalf("got %v, want %v", err, ErrQuotaExceeded)
+	}
+
+	var apiErr *Error
+	if !errors.As(err, &apiErr) || apiErr.HTTPStatus != http.StatusTooManyRequests || apiErr.RequestID != "req-1" {
+		t.Fatalf("got %+v", apiErr)
+	}
+	if n := attempts.Load(); n != 1 {
+		t.Fatalf("got %d attempts, want 1", n)
+	}
+}
@@ -0,0 +1,89 @@
+package client
+
+import (
+	"fmt"
+	"net/http"
+)
+
+// The error codes of the API, the code of an Error tells its cause apart.
+const (
+	CodeInternal           = 500
+	CodeInvalidParams      = 4000
+	CodeInvalidAddress     = 4001
+	CodeInvalidChecksum    = 4002
+	CodeInvalidCursor      = 4003
+	CodeInvalidTxHash      = 4004
+	CodeUnauthorized       = 4010
+	CodeForbidden          = 4030
+	CodeNotSubscribed      = 4040
+	CodeTxNotFound         = 4041
+	CodeWebhookNotFound    = 4042
+	CodeDeliveryNotFound   = 4043
+	CodeAPIKeyNotFound     = 4044
+	CodeAlreadySubscribed  = 4090
+	CodeReprocessRunning   = 4091
+	CodeRateLimited        = 4290
+	CodeQuotaExceeded      = 4291
+	CodeCanceled           = 4990
+	CodeBackendUnavailable = 5030
+	CodeNotReady           = 5031
+)
+
+// The errors of the API, to be matched with errors.Is.
+var (
+	ErrInternal           = &Error{Code: CodeInternal}
+	ErrInvalidParams      = &Error{Code: CodeInvalidParams}
+	ErrInvalidAddress     = &Error{Code: CodeInvalidAddress}
+	ErrInvalidChecksum    = &Error{Code: CodeInvalidChecksum}
+	ErrInvalidCursor      = &Error{Code: CodeInvalidCursor}
+	ErrInvalidTxHash      = &Error{Code: CodeInvalidTxHash}
+	ErrUnauthorized       = &Error{Code: CodeUnauthorized}
+	ErrForbidden          = &Error{Code: CodeForbidden}
+	ErrNotSubscribed      = &Error{Code: CodeNotSubscribed}
+	ErrTxNotFound         = &Error{Code: CodeTxNotFound}
+	ErrWebhookNotFound    = &Error{Code: CodeWebhookNotFound}
+	ErrDeliveryNotFound   = &Error{Code: CodeDeliveryNotFound}
+	ErrAPIKeyNotFound     = &Error{Code: CodeAPIKeyNotFound}
+	ErrAlreadySubscribed  = &Error{Code: CodeAlreadySubscribed}
+	ErrReprocessRunning   = &Error{Code: CodeReprocessRunning}
+	ErrRateLimited        = &Error{Code: CodeRateLimited}
+	ErrQuotaExceeded      = &Error{Code: CodeQuotaExceeded}
+	ErrCanceled           = &Error{Code: CodeCanceled}
+	ErrBackendUnavailable = &Error{Code: CodeBackendUnavailable}
+	ErrNotReady           = &Error{Code: CodeNotReady}
+)
+
+// Error is an error response of the API.
+type Error struct {
+	HTTPStatus int           `json:"-"`
+	Code       int           `json:"code"`
+	Message    string        `json:"message"`
+	Details    []interface{} `json:"details"`
+	RequestID  string        `json:"requestId"`
+}
+
+func (e *Error) Error() string {
+	msg := fmt.Sprintf("trustme: %d %s", e.Code, e.Message)
+	if len(e.Details) > 0 {
+		msg += fmt.Sprintf(": %v", e.Details)
+	}
+	if e.RequestID != "" {
+		msg += " (request " + e.RequestID + ")"
+	}
+	return msg
+}
+
+// Is matches the errors of the same code, so that errors.Is(err, client.ErrNotSubscribed) holds for any
+// "address is not subscribed" response.
+func (e *Error) Is(target error) bool {
+	t, ok := target.(*Error)
+	return ok && t.Code == e.Code
+}
+
+// Temporary tells whether the request may succeed when retried. An exceeded quota is not, it lasts the day.
+func (e *Error) Temporary() bool {
+	if e.Code == CodeQuotaExceeded {
+		return false
+	}
+	return e.HTTPStatus == http.StatusTooManyRequests || e.HTTPStatus >= http.StatusInternalServerError
+}
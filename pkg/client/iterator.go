@@ -0,0 +1,63 @@
+package client
+
+import "context"
+
+// Iterator walks a paginated list, fetching the next page when the current one is consumed:
+//
+//	it := c.IterTxRecords("0x...", client.TxQuery{})
+//	for it.Next(ctx) {
+//		record := it.Item()
+//		...
+//	}
+//	err := it.Err()
+type Iterator[T any] struct {
+	fetch  func(ctx context.Context, cursor string) ([]T, string, error)
+	items  []T
+	item   T
+	cursor string
+	done   bool
+	err    error
+}
+
+func newIterator[T any](cursor string, fetch func(ctx context.Context, cursor string) ([]T, string, error)) *Iterator[T] {
+	return &Iterator[T]{fetch: fetch, cursor: cursor}
+}
+
+// Next moves to the next item, it returns false once the list is exhausted or a page failed to be fetched.
+func (it *Iterator[T]) Next(ctx context.Context) bool {
+	for len(it.items) == 0 {
+		if it.done || it.err != nil {
+			return false
+		}
+
+		items, next, err := it.fetch(ctx, it.cursor)
+		if err != nil {
+			it.err = err
+			return false
+		}
+		it.items = items
+		it.cursor = next
+		it.done = next == ""
+	}
+
+	it.item = it.items[0]
+	it.items = it.items[1:]
+	return true
+}
+
+func (it *Iterator[T]) Item() T {
+	return it.item
+}
+
+func (it *Iterator[T]) Err() error {
+	return it.err
+}
+
+// All consumes the iterator and returns the items left.
+func (it *Iterator[T]) All(ctx context.Context) ([]T, error) {
+	var items []T
+	for it.Next(ctx) {
+		items = append(items, it.Item())
+	}
+	return items, it.Err()
+}
@@ -0,0 +1,405 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/gin-gonic/gin"
+	"github.com/mcuadros/go-defaults"
+
+	"github.com/vuquang23/trustme/internal/pkg/api"
+	"github.com/vuquang23/trustme/internal/pkg/entity"
+	"github.com/vuquang23/trustme/internal/pkg/eventbus"
+	"github.com/vuquang23/trustme/internal/pkg/ratelimit"
+	"github.com/vuquang23/trustme/internal/pkg/repository/subscriber"
+	tenantrepo "github.com/vuquang23/trustme/internal/pkg/repository/tenant"
+	"github.com/vuquang23/trustme/internal/pkg/repository/tx"
+	"github.com/vuquang23/trustme/internal/pkg/server"
+	"github.com/vuquang23/trustme/internal/pkg/tenant"
+	"github.com/vuquang23/trustme/pkg/logger"
+)
+
+// fakeParser serves the subscriptions and transactions of in-memory repositories, without any node.
+type fakeParser struct {
+	currentBlock int
+	subscribers  *subscriber.MemRepository
+	txs          *tx.MemRepository
+}
+
+func (p *fakeParser) GetCurrentBlock() int {
+	return p.currentBlock
+}
+
+func (p *fakeParser) AddSubscription(subscription *entity.Subscription) error {
+	if p.subscribers.IsSubscriber(subscription.Address) {
+		return entity.ErrAlreadySubscribed
+	}
+	return p.subscribers.Create(subscription)
+}
+
+func (p *fakeParser) Unsubscribe(address string) error {
+	if !p.subscribers.IsSubscriber(address) {
+		return entity.ErrNotSubscribed
+	}
+	return p.subscribers.Delete(address)
+}
+
+func (p *fakeParser) GetSubscription(address string) (*entity.Subscription, error) {
+	subscription, err := p.subscribers.Get(address)
+	if err != nil {
+		return nil, err
+	}
+	if subscription == nil {
+		return nil, entity.ErrNotSubscribed
+	}
+	return subscription, nil
+}
+
+func (p *fakeParser) GetSubscriptions(cursor string, limit int) (*entity.SubscriptionPage, error) {
+	return p.subscribers.List(cursor, limit)
+}
+
+func (p *fakeParser) GetTransactions(address string) []*types.Transaction {
+	records, _ := p.txs.GetTxs(address)
+	txs := make([]*types.Transaction, 0, len(records))
+	for _, record := range records {
+		txs = append(txs, record.Tx)
+	}
+	return txs
+}
+
+func (p *fakeParser) QueryTxRecords(address string, query entity.TxQuery) (*entity.TxPage, error) {
+	return p.txs.QueryTxs(address, query)
+}
+
+func (p *fakeParser) GetTxDetail(_ context.Context, hash common.Hash) (*entity.TxDetail, error) {
+	records, err := p.txs.GetTxsByHash(hash)
+	if err != nil {
+		return nil, err
+	}
+	if len(records) == 0 {
+		return nil, entity.ErrTxNotFound
+	}
+	record := records[0]
+	return &entity.TxDetail{
+		Indexed:     true,
+		Tx:          record.Tx,
+		From:        record.From,
+		BlockNumber: record.BlockNumber,
+		BlockHash:   record.BlockHash,
+		Timestamp:   record.Timestamp,
+		TxIndex:     record.TxIndex,
+		Records:     records,
+	}, nil
+}
+
+func (p *fakeParser) GetTokenTransfers(context.Context, common.Hash) ([]*entity.TokenTransfer, error) {
+	return nil, nil
+}
+
+type testServer struct {
+	url    string
+	parser *fakeParser
+	bus    *eventbus.Bus
+	client *Client
+}
+
+// newTestServer serves the API routes over a fake parser, rate limited by rateLimit when it is enabled.
+func newTestServer(t *testing.T, rateLimit ratelimit.Config, opts ...Option) *testServer {
+	t.Helper()
+
+	engine, err := server.GinEngine(server.Config{Mode: gin.TestMode}, logger.Config{}, logger.LoggerBackendZap)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	limiter, err := ratelimit.New(rateLimit)
+	if err != nil {
+		t.Fatal(err)
+	}
+	engine.Use(api.RateLimit(limiter))
+
+	var cfg api.Config
+	defaults.SetDefaults(&cfg)
+
+	parser := &fakeParser{
+		currentBlock: 42,
+		subscribers:  subscriber.NewMemRepository(),
+		txs:          tx.NewMemRepository(0),
+	}
+	bus := eventbus.New()
+	tenants := tenant.New(parser, tenantrepo.NewMemRepository(), limiter)
+	if err := api.SetupRoute(engine, cfg, tenants, bus, nil, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	srv := httptest.NewServer(engine)
+	t.Cleanup(srv.Close)
+
+	opts = append([]Option{WithBackoff(time.Millisecond, 5*time.Millisecond)}, opts...)
+	c, err := New(srv.URL, opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &testServer{url: srv.URL, parser: parser, bus: bus, client: c}
+}
+
+// saveRecord stores a transaction of testAddress at blockNumber.
+func (s *testServer) saveRecord(t *testing.T, blockNumber uint64) *entity.TxRecord {
+	t.Helper()
+
+	to := common.HexToAddress(testAddress)
+	record := &entity.TxRecord{
+		Address:     strings.ToLower(testAddress),
+		Direction:   entity.TxDirectionIn,
+		Tx:          types.NewTx(&types.LegacyTx{Nonce: blockNumber, To: &to, Value: big.NewInt(1), GasPrice: big.NewInt(1)}),
+		BlockNumber: blockNumber,
+		Status:      entity.TxStatusSuccess,
+	}
+	if err := s.parser.txs.SaveTx(record); err != nil {
+		t.Fatal(err)
+	}
+	return record
+}
+
+// countingTransport counts the requests sent through it.
+type countingTransport struct {
+	requests atomic.Int32
+}
+
+func (t *countingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	t.requests.Add(1)
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+func TestServerTypedErrors(t *testing.T) {
+	transport := &countingTransport{}
+	s := newTestServer(t, ratelimit.Config{}, WithHTTPClient(&http.Client{Transport: transport}))
+	ctx := context.Background()
+
+	if _, err := s.client.Subscribe(ctx, SubscribeParams{Address: testAddress}); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tc := range []struct {
+		name   string
+		call   func() error
+		status int
+		target error
+	}{
+		{
+			name: "already subscribed",
+			call: func() error {
+				_, err := s.client.Subscribe(ctx, SubscribeParams{Address: testAddress})
+				return err
+			},
+			status: 409,
+			target: ErrAlreadySubscribed,
+		},
+		{
+			name:   "not subscribed",
+			call:   func() error { return s.client.Unsubscribe(ctx, "0x0000000000000000000000000000000000000001") },
+			status: 404,
+			target: ErrNotSubscribed,
+		},
+		{
+			name:   "invalid address",
+			call:   func() error { return s.client.Unsubscribe(ctx, "0x1234") },
+			status: 400,
+			target: ErrInvalidAddress,
+		},
+		{
+			name: "invalid cursor",
+			call: func() error {
+				_, err := s.client.TxRecords(ctx, testAddress, TxQuery{Cursor: "not a cursor"})
+				return err
+			},
+			status: 400,
+			target: ErrInvalidCursor,
+		},
+		{
+			name: "tx not found",
+			call: func() error {
+				_, err := s.client.Tx(ctx, common.Hash{1}.Hex())
+				return err
+			},
+			status: 404,
+			target: ErrTxNotFound,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			transport.requests.Store(0)
+			err := tc.call()
+			if !errors.Is(err, tc.target) {
+				t.Fatalf("got %v, want %v", err, tc.target)
+			}
+
+			var apiErr *Error
+			if !errors.As(err, &apiErr) || apiErr.HTTPStatus != tc.status || apiErr.RequestID == "" {
+				t.Fatalf("got %+v, want status %d with a request id", apiErr, tc.status)
+			}
+			if n := transport.requests.Load(); n != 1 {
+				t.Fatalf("got %d requests, want 1", n)
+			}
+		})
+	}
+}
+
+func TestServerIterTxRecordsFollowsCursors(t *testing.T) {
+	s := newTestServer(t, ratelimit.Config{})
+	ctx := context.Background()
+
+	if _, err := s.client.Subscribe(ctx, SubscribeParams{Address: testAddress}); err != nil {
+		t.Fatal(err)
+	}
+	var want []string
+	for n := uint64(1); n <= 5; n++ {
+		want = append([]string{s.saveRecord(t, n).Hash().Hex()}, want...)
+	}
+
+	var (
+		got   []string
+		pages int
+	)
+	it := s.client.IterTxRecords(testAddress, TxQuery{Order: "desc", Limit: 2})
+	for it.Next(ctx) {
+		record := it.Item()
+		if record.Address != testAddress || record.Status != "success" {
+			t.Fatalf("got %+v", record)
+		}
+		got = append(got, record.Hash)
+		if len(got)%2 == 1 {
+			pages++
+		}
+	}
+	if err := it.Err(); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+	if pages != 3 {
+		t.Fatalf("got %d pages, want 3", pages)
+	}
+
+	subscriptions, err := s.client.IterSubscriptions(1).All(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(subscriptions) != 1 || subscriptions[0].Address != testAddress {
+		t.Fatalf("got %+v", subscriptions)
+	}
+}
+
+func TestServerIteratorStopsOnError(t *testing.T) {
+	s := newTestServer(t, ratelimit.Config{})
+
+	ctx := context.Background()
+
+	if _, err := s.client.Subscribe(ctx, SubscribeParams{Address: testAddress}); err != nil {
+		t.Fatal(err)
+	}
+	s.saveRecord(t, 1)
+
+	it := s.client.IterTxRecords(testAddress, TxQuery{Cursor: "not a cursor"})
+	if it.Next(ctx) {
+		t.Fatalf("got %+v from an invalid cursor", it.Item())
+	}
+	if !errors.Is(it.Err(), ErrInvalidCursor) {
+		t.Fatalf("got %v, want %v", it.Err(), ErrInvalidCursor)
+	}
+	if it.Next(ctx) {
+		t.Fatal("got an item after the error")
+	}
+}
+
+func TestServerRetryAfter(t *testing.T) {
+	s := newTestServer(t, ratelimit.Config{Enabled: true, Default: ratelimit.Limit{Rate: 10, Burst: 1}},
+		WithBackoff(time.Millisecond, 5*time.Second))
+	ctx := context.Background()
+
+	if _, err := s.client.CurrentBlock(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	// the server asks to wait a second, the backoff alone would retry after a millisecond
+	start := time.Now()
+	block, err := s.client.CurrentBlock(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if block != 42 {
+		t.Fatalf("got block %d, want 42", block)
+	}
+	if elapsed := time.Since(start); elapsed < 500*time.Millisecond {
+		t.Fatalf("retried after %s, before the Retry-After", elapsed)
+	}
+
+	noRetry, err := New(s.url, WithRetries(0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = noRetry.CurrentBlock(ctx)
+	var apiErr *Error
+	if !errors.Is(err, ErrRateLimited) || !errors.As(err, &apiErr) || apiErr.HTTPStatus != 429 || !apiErr.Temporary() {
+		t.Fatalf("got %v, want a temporary rate limited error", err)
+	}
+}
+
+func TestServerStream(t *testing.T) {
+	s := newTestServer(t, ratelimit.Config{})
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if _, err := s.client.OpenStream(ctx, []string{"0x1234"}, ""); !errors.Is(err, ErrInvalidAddress) {
+		t.Fatalf("got %v, want %v", err, ErrInvalidAddress)
+	}
+	if _, err := s.client.Subscribe(ctx, SubscribeParams{Address: testAddress}); err != nil {
+		t.Fatal(err)
+	}
+
+	stream, err := s.client.OpenStream(ctx, []string{testAddress}, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the stream is subscribed to the bus once its response started
+	live := s.saveRecord(t, 1)
+	if err := s.bus.Publish(entity.Event{Type: entity.EventTypeTx, Address: live.Address, Record: live}); err != nil {
+		t.Fatal(err)
+	}
+	event, err := stream.Recv()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if event.Event != "tx" || event.ID == "" || event.Record.Hash != live.Hash().Hex() || event.Record.Address != testAddress {
+		t.Fatalf("got %+v", event)
+	}
+	if stream.LastEventID() != event.ID {
+		t.Fatalf("got last event id %q, want %q", stream.LastEventID(), event.ID)
+	}
+	_ = stream.Close()
+
+	// saved while disconnected, replayed when listening from the last event
+	missed := s.saveRecord(t, 2)
+	errStop := errors.New("stop")
+	var received []string
+	err = s.client.Listen(ctx, []string{testAddress}, event.ID, func(event *StreamEvent) error {
+		received = append(received, event.Record.Hash)
+		return errStop
+	})
+	if !errors.Is(err, errStop) {
+		t.Fatalf("got %v, want the error of the callback", err)
+	}
+	if len(received) != 1 || received[0] != missed.Hash().Hex() {
+		t.Fatalf("got %v, want %s", received, missed.Hash().Hex())
+	}
+}
@@ -0,0 +1,169 @@
+package client
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// StreamEvent is a transaction pushed by the stream endpoint.
+type StreamEvent struct {
+	// ID is the position of the event in the stream, to resume it with Last-Event-ID.
+	ID     string
+	Event  string
+	Record TxRecord
+}
+
+// Stream reads the server-sent events of the transactions of addresses.
+type Stream struct {
+	body        io.ReadCloser
+	reader      *bufio.Reader
+	lastEventID string
+}
+
+// OpenStream opens a stream of the transactions of the addresses, which must be subscribed. With lastEventID,
+// the transactions saved after that event are replayed first.
+func (c *Client) OpenStream(ctx context.Context, addresses []string, lastEventID string) (*Stream, error) {
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodGet, c.url("/api/stream", url.Values{"address": addresses}), nil)
+	if err != nil {
+		return nil, err
+	}
+	httpReq.Header.Set("Accept", "text/event-stream")
+	if lastEventID != "" {
+		httpReq.Header.Set("Last-Event-ID", lastEventID)
+	}
+	c.authorize(httpReq.Header)
+
+	// The response lasts as long as the stream, the timeout of the http client would cut it.
+	httpClient := *c.httpClient
+	httpClient.Timeout = 0
+
+	resp, err := httpClient.Do(httpReq)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, decodeResponse(resp, nil)
+	}
+
+	return &Stream{
+		body:        resp.Body,
+		reader:      bufio.NewReader(resp.Body),
+		lastEventID: lastEventID,
+	}, nil
+}
+
+// Recv blocks until the next event, it returns io.EOF once the server ends the stream.
+func (s *Stream) Recv() (*StreamEvent, error) {
+	var (
+		event = &StreamEvent{}
+		data  strings.Builder
+	)
+	for {
+		line, err := s.reader.ReadString('\n')
+		if err != nil {
+			return nil, err
+		}
+		line = strings.TrimRight(line, "\r\n")
+
+		if line == "" {
+			// A blank line ends an event, heartbeats have no data.
+			if data.Len() == 0 {
+				continue
+			}
+			if err := json.Unmarshal([]byte(data.String()), &event.Record); err != nil {
+				return nil, fmt.Errorf("trustme: decode stream event: %w", err)
+			}
+			if event.ID != "" {
+				s.lastEventID = event.ID
+			}
+			return event, nil
+		}
+
+		field, value, _ := strings.Cut(line, ":")
+		value = strings.TrimPrefix(value, " ")
+		switch field {
+		case "":
+			// A comment, the keepalives.
+		case "event":
+			event.Event = value
+		case "id":
+			event.ID = value
+		case "data":
+			if data.Len() > 0 {
+				data.WriteByte('\n')
+			}
+			data.WriteString(value)
+		}
+	}
+}
+
+// LastEventID is the id of the last event received, to open the stream again where it stopped.
+func (s *Stream) LastEventID() string {
+	return s.lastEventID
+}
+
+func (s *Stream) Close() error {
+	return s.body.Close()
+}
+
+// Listen calls fn with every transaction of the addresses until ctx is done or fn fails. The stream is opened
+// again with the last event id when it drops, so no transaction is missed. Errors other than temporary ones
+// are returned, such as ErrNotSubscribed.
+func (c *Client) Listen(ctx context.Context, addresses []string, lastEventID string, fn func(*StreamEvent) error) error {
+	// failures counts the attempts in a row that failed to open the stream.
+	failures := 0
+	for {
+		stream, err := c.OpenStream(ctx, addresses, lastEventID)
+		if err != nil {
+			if !retryable(ctx, err) {
+				return err
+			}
+			failures++
+		} else {
+			failures = 0
+			err = consume(stream, fn)
+			lastEventID = stream.LastEventID()
+			_ = stream.Close()
+
+			var fnErr *listenerError
+			if errors.As(err, &fnErr) {
+				return fnErr.err
+			}
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+		}
+
+		if err := sleep(ctx, c.backoff(failures, "")); err != nil {
+			return err
+		}
+	}
+}
+
+// listenerError tells the errors of the callback of Listen apart from the ones of the stream.
+type listenerError struct {
+	err error
+}
+
+func (e *listenerError) Error() string {
+	return e.err.Error()
+}
+
+func consume(stream *Stream, fn func(*StreamEvent) error) error {
+	for {
+		event, err := stream.Recv()
+		if err != nil {
+			return err
+		}
+		if err := fn(event); err != nil {
+			return &listenerError{err: err}
+		}
+	}
+}
@@ -0,0 +1,352 @@
+package client
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// The event types of a subscribed address, EventBlock is not about an address.
+const (
+	EventTx            = "tx"
+	EventTokenTransfer = "token_transfer"
+	EventConfirmation  = "confirmation"
+	EventReorg         = "reorg"
+	EventBlock         = "block"
+)
+
+// Response is the envelope of every successful response.
+type Response struct {
+	Code      int             `json:"code"`
+	Message   string          `json:"message"`
+	Data      json.RawMessage `json:"data"`
+	RequestID string          `json:"requestId"`
+}
+
+type WSStatus struct {
+	Connected   bool       `json:"connected"`
+	ConnectedAt *time.Time `json:"connectedAt,omitempty"`
+	LastHeadAt  *time.Time `json:"lastHeadAt,omitempty"`
+	Reconnects  int        `json:"reconnects"`
+	LastError   string     `json:"lastError,omitempty"`
+}
+
+type Backfill struct {
+	From    uint64 `json:"from"`
+	To      uint64 `json:"to"`
+	Current uint64 `json:"current"`
+}
+
+type Status struct {
+	CurrentBlock    uint64     `json:"currentBlock"`
+	ChainHead       uint64     `json:"chainHead"`
+	Lag             uint64     `json:"lag"`
+	LastBlockTime   *time.Time `json:"lastBlockTime,omitempty"`
+	LastProcessedAt *time.Time `json:"lastProcessedAt,omitempty"`
+	WS              WSStatus   `json:"ws"`
+	Backfill        *Backfill  `json:"backfill,omitempty"`
+}
+
+type SubscribeParams struct {
+	Address string   `json:"address"`
+	Label   string   `json:"label,omitempty"`
+	Tags    []string `json:"tags,omitempty"`
+	Owner   string   `json:"owner,omitempty"`
+	Notes   string   `json:"notes,omitempty"`
+}
+
+type Subscription struct {
+	Address   string    `json:"address"`
+	Label     string    `json:"label"`
+	Tags      []string  `json:"tags"`
+	Owner     string    `json:"owner"`
+	Notes     string    `json:"notes"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+type SubscriptionPage struct {
+	Items      []Subscription `json:"items"`
+	NextCursor string         `json:"nextCursor,omitempty"`
+	Total      int            `json:"total"`
+}
+
+type ImportItemResult struct {
+	Line    int    `json:"line"`
+	Address string `json:"address"`
+	Status  string `json:"status"`
+	Error   string `json:"error,omitempty"`
+}
+
+type ImportResult struct {
+	Created    int                `json:"created"`
+	Duplicates int                `json:"duplicates"`
+	Invalid    int                `json:"invalid"`
+	Failed     int                `json:"failed"`
+	Items      []ImportItemResult `json:"items"`
+}
+
+// TxRecord is a transaction as seen by one of its subscribed parties.
+type TxRecord struct {
+	Hash      string `json:"hash"`
+	Address   string `json:"address"`
+	Direction string `json:"direction"`
+	From      string `json:"from"`
+	To        string `json:"to,omitempty"`
+	Type      uint8  `json:"type"`
+	Nonce     uint64 `json:"nonce"`
+	Input     string `json:"input"`
+
+	Value      string `json:"value"`
+	ValueEther string `json:"valueEther"`
+
+	Gas               uint64 `json:"gas"`
+	GasUsed           uint64 `json:"gasUsed"`
+	EffectiveGasPrice string `json:"effectiveGasPrice"`
+	Fee               string `json:"fee"`
+	FeeEther          string `json:"feeEther"`
+
+	BlockNumber uint64 `json:"blockNumber"`
+	BlockHash   string `json:"blockHash"`
+	Timestamp   uint64 `json:"timestamp"`
+	TxIndex     uint   `json:"txIndex"`
+	Status      string `json:"status"`
+}
+
+type TxPage struct {
+	Items      []TxRecord `json:"items"`
+	NextCursor string     `json:"nextCursor,omitempty"`
+	Total      *int       `json:"total,omitempty"`
+}
+
+// TxQuery filters the transactions of an address, the zero value of a field doesn't filter.
+type TxQuery struct {
+	FromBlock    *uint64
+	ToBlock      *uint64
+	FromTime     *uint64
+	ToTime       *uint64
+	Direction    string
+	MinValue     string
+	MaxValue     string
+	Status       string
+	Counterparty string
+	Type         *uint8
+	Order        string
+	Cursor       string
+	Limit        int
+}
+
+type TxParty struct {
+	Address   string `json:"address"`
+	Direction string `json:"direction"`
+}
+
+type Log struct {
+	LogIndex uint              `json:"logIndex"`
+	Address  string            `json:"address"`
+	Topics   []string          `json:"topics"`
+	Data     string            `json:"data"`
+	Event    string            `json:"event,omitempty"`
+	Args     map[string]string `json:"args,omitempty"`
+}
+
+type Receipt struct {
+	Status            string `json:"status"`
+	GasUsed           uint64 `json:"gasUsed"`
+	CumulativeGasUsed uint64 `json:"cumulativeGasUsed"`
+	EffectiveGasPrice string `json:"effectiveGasPrice"`
+	Fee               string `json:"fee"`
+	FeeEther          string `json:"feeEther"`
+	ContractAddress   string `json:"contractAddress,omitempty"`
+	Logs              []Log  `json:"logs"`
+}
+
+type TxDetail struct {
+	Hash    string `json:"hash"`
+	Indexed bool   `json:"indexed"`
+	Pending bool   `json:"pending"`
+
+	From       string `json:"from"`
+	To         string `json:"to,omitempty"`
+	Type       uint8  `json:"type"`
+	Nonce      uint64 `json:"nonce"`
+	Input      string `json:"input"`
+	Gas        uint64 `json:"gas"`
+	Value      string `json:"value"`
+	ValueEther string `json:"valueEther"`
+
+	BlockNumber uint64 `json:"blockNumber,omitempty"`
+	BlockHash   string `json:"blockHash,omitempty"`
+	Timestamp   uint64 `json:"timestamp,omitempty"`
+	TxIndex     uint   `json:"txIndex"`
+
+	Receipt *Receipt  `json:"receipt"`
+	Parties []TxParty `json:"parties"`
+}
+
+type Confirmation struct {
+	TxRecord
+	Confirmations uint64 `json:"confirmations"`
+}
+
+type TokenTransfer struct {
+	Token       string `json:"token"`
+	From        string `json:"from"`
+	To          string `json:"to"`
+	Value       string `json:"value,omitempty"`
+	TokenID     string `json:"tokenId,omitempty"`
+	TxHash      string `json:"txHash"`
+	LogIndex    uint   `json:"logIndex"`
+	BlockNumber uint64 `json:"blockNumber"`
+	BlockHash   string `json:"blockHash"`
+	TxIndex     uint   `json:"txIndex"`
+}
+
+type Block struct {
+	Number     uint64 `json:"number"`
+	Hash       string `json:"hash"`
+	ParentHash string `json:"parentHash"`
+	Timestamp  uint64 `json:"timestamp"`
+	TxCount    int    `json:"txCount"`
+	MatchedTxs int    `json:"matchedTxs"`
+}
+
+// EventPayload is the body of a webhook delivery or a message published to a broker.
+type EventPayload struct {
+	ID        string          `json:"id"`
+	Event     string          `json:"event"`
+	Address   string          `json:"address,omitempty"`
+	CreatedAt time.Time       `json:"createdAt"`
+	Data      json.RawMessage `json:"data"`
+}
+
+// DecodeEventData decodes the data of an event by its type: a *TxRecord for tx and reorg events,
+// a *TokenTransfer, a *Confirmation or a *Block.
+func DecodeEventData(event string, data json.RawMessage) (interface{}, error) {
+	var v interface{}
+	switch event {
+	case EventTokenTransfer:
+		v = &TokenTransfer{}
+	case EventConfirmation:
+		v = &Confirmation{}
+	case EventBlock:
+		v = &Block{}
+	default:
+		v = &TxRecord{}
+	}
+
+	if err := json.Unmarshal(data, v); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+type CreateWebhookParams struct {
+	Address string `json:"address"`
+	URL     string `json:"url"`
+	// Secret is generated when empty.
+	Secret string `json:"secret,omitempty"`
+	// Events defaults to every event type of the address.
+	Events []string `json:"events,omitempty"`
+}
+
+type Webhook struct {
+	ID      string `json:"id"`
+	Address string `json:"address"`
+	URL     string `json:"url"`
+	// Secret is only returned when the webhook is created.
+	Secret    string    `json:"secret,omitempty"`
+	Events    []string  `json:"events"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+type DeliveryAttempt struct {
+	At         time.Time `json:"at"`
+	StatusCode int       `json:"statusCode,omitempty"`
+	Error      string    `json:"error,omitempty"`
+	DurationMs int64     `json:"durationMs"`
+}
+
+type Delivery struct {
+	ID            string            `json:"id"`
+	WebhookID     string            `json:"webhookId"`
+	Event         string            `json:"event"`
+	Address       string            `json:"address"`
+	Status        string            `json:"status"`
+	Attempts      []DeliveryAttempt `json:"attempts"`
+	NextAttemptAt *time.Time        `json:"nextAttemptAt,omitempty"`
+	CreatedAt     time.Time         `json:"createdAt"`
+	Payload       json.RawMessage   `json:"payload"`
+}
+
+type DeliveryPage struct {
+	Items      []Delivery `json:"items"`
+	NextCursor string     `json:"nextCursor,omitempty"`
+}
+
+// DeliveryQuery filters the webhook deliveries, Status "dead" being the dead-letter list.
+type DeliveryQuery struct {
+	WebhookID string
+	Status    string
+	Cursor    string
+	Limit     int
+}
+
+type CreateKeyParams struct {
+	Name   string `json:"name"`
+	Tenant string `json:"tenant,omitempty"`
+	Admin  bool   `json:"admin"`
+}
+
+type APIKey struct {
+	ID     string `json:"id"`
+	Name   string `json:"name"`
+	Tenant string `json:"tenant,omitempty"`
+	Admin  bool   `json:"admin"`
+	// Key is only returned when the key is created.
+	Key       string    `json:"key,omitempty"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+type Limit struct {
+	// Rate is the number of requests per second, 0 for no limit.
+	Rate  float64 `json:"rate"`
+	Burst int     `json:"burst"`
+}
+
+type GroupLimit struct {
+	Prefix string `json:"prefix"`
+	Limit
+}
+
+type RateLimits struct {
+	// Enabled switches the rate limiting on or off, SetRateLimits leaves it as is when nil.
+	Enabled             *bool        `json:"enabled,omitempty"`
+	Default             Limit        `json:"default"`
+	Groups              []GroupLimit `json:"groups"`
+	SubscriptionsPerDay int          `json:"subscriptionsPerDay"`
+}
+
+type ParserInternals struct {
+	Paused               bool   `json:"paused"`
+	Checkpoint           uint64 `json:"checkpoint"`
+	QueueDepth           int    `json:"queueDepth"`
+	QueueCapacity        int    `json:"queueCapacity"`
+	PendingConfirmations int    `json:"pendingConfirmations"`
+	FailedBlocks         int    `json:"failedBlocks"`
+	Status               Status `json:"status"`
+}
+
+type ReprocessParams struct {
+	From uint64 `json:"from"`
+	// To defaults to From, to reprocess a single block.
+	To      uint64 `json:"to,omitempty"`
+	Publish bool   `json:"publish"`
+}
+
+type FailedBlock struct {
+	Hash          string    `json:"hash,omitempty"`
+	Number        uint64    `json:"number"`
+	Error         string    `json:"error"`
+	Attempts      int       `json:"attempts"`
+	FirstFailedAt time.Time `json:"firstFailedAt"`
+	LastFailedAt  time.Time `json:"lastFailedAt"`
+}
@@ -0,0 +1,106 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/gorilla/websocket"
+)
+
+// The types of the WebSocket messages sent by the server.
+const (
+	WSTypeAck   = "ack"
+	WSTypeError = "error"
+	WSTypeEvent = "event"
+	WSTypePong  = "pong"
+)
+
+// WSMessage is a message of the server: an ack or error answering a request, a pong, or an event whose data
+// is decoded with DecodeEventData.
+type WSMessage struct {
+	ID      string          `json:"id,omitempty"`
+	Type    string          `json:"type"`
+	Event   string          `json:"event,omitempty"`
+	Address string          `json:"address,omitempty"`
+	Data    json.RawMessage `json:"data,omitempty"`
+	Error   *Error          `json:"error,omitempty"`
+}
+
+type wsRequest struct {
+	ID        string   `json:"id,omitempty"`
+	Op        string   `json:"op"`
+	Addresses []string `json:"addresses,omitempty"`
+	Events    []string `json:"events,omitempty"`
+}
+
+// WSConn is a WebSocket connection to the server. Requests and Recv may be called concurrently, but Recv from
+// a single goroutine: it is the one receiving the answers of the requests too.
+type WSConn struct {
+	conn *websocket.Conn
+
+	writeMu sync.Mutex
+	nextID  int
+}
+
+// DialWebSocket opens a WebSocket connection, events are received once addresses are subscribed to.
+func (c *Client) DialWebSocket(ctx context.Context) (*WSConn, error) {
+	header := http.Header{}
+	c.authorize(header)
+
+	wsURL := "ws" + strings.TrimPrefix(c.url("/api/ws", nil), "http")
+	conn, resp, err := websocket.DefaultDialer.DialContext(ctx, wsURL, header)
+	if err != nil {
+		if resp != nil && resp.StatusCode >= http.StatusBadRequest {
+			return nil, decodeResponse(resp, nil)
+		}
+		return nil, err
+	}
+
+	return &WSConn{conn: conn}, nil
+}
+
+// Subscribe subscribes to the events of the addresses, every event type of an address when events is empty.
+// The answer is received by Recv with the returned request id.
+func (w *WSConn) Subscribe(addresses, events []string) (string, error) {
+	return w.send(wsRequest{Op: "subscribe", Addresses: addresses, Events: events})
+}
+
+// Unsubscribe stops the events of the addresses, every event type of an address when events is empty.
+func (w *WSConn) Unsubscribe(addresses, events []string) (string, error) {
+	return w.send(wsRequest{Op: "unsubscribe", Addresses: addresses, Events: events})
+}
+
+func (w *WSConn) Ping() (string, error) {
+	return w.send(wsRequest{Op: "ping"})
+}
+
+func (w *WSConn) send(req wsRequest) (string, error) {
+	w.writeMu.Lock()
+	defer w.writeMu.Unlock()
+
+	w.nextID++
+	req.ID = strconv.Itoa(w.nextID)
+	return req.ID, w.conn.WriteJSON(req)
+}
+
+// Recv blocks until the next message. The connection is closed by the server when the client is too slow
+// to receive its events.
+func (w *WSConn) Recv() (*WSMessage, error) {
+	var message WSMessage
+	if err := w.conn.ReadJSON(&message); err != nil {
+		return nil, err
+	}
+	return &message, nil
+}
+
+func (w *WSConn) Close() error {
+	w.writeMu.Lock()
+	defer w.writeMu.Unlock()
+
+	_ = w.conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""))
+	return w.conn.Close()
+}
@@ -4,6 +4,8 @@ import (
 	"context"
 	"errors"
 	"sync"
+
+	"go.opentelemetry.io/otel/trace"
 )
 
 const CtxLoggerKey = "logger"
@@ -179,7 +181,19 @@ func SetLogLevel(level string) error {
 func fromCtx(ctx context.Context) Logger {
 	l := ctx.Value(CtxLoggerKey)
 	if ctxLogger, ok := l.(Logger); ok && ctxLogger != nil {
-		return ctxLogger
+		return WithSpan(ctx, ctxLogger)
 	}
-	return log
+	return WithSpan(ctx, log)
+}
+
+// WithSpan adds the trace and span IDs of the span in ctx, if any, to the entries of l.
+func WithSpan(ctx context.Context, l Logger) Logger {
+	spanCtx := trace.SpanContextFromContext(ctx)
+	if !spanCtx.IsValid() {
+		return l
+	}
+	return l.WithFields(Fields{
+		"trace.id": spanCtx.TraceID().String(),
+		"span.id":  spanCtx.SpanID().String(),
+	})
 }